@@ -0,0 +1,738 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	goerrors "github.com/goliatone/go-errors"
+)
+
+// Request struct tags read by the Context methods BindQuery, BindParams,
+// BindHeaders and BindForm, and by Typed handlers.
+const (
+	TagPath   = "path"
+	TagQuery  = "query"
+	TagHeader = "header"
+	TagCookie = "cookie"
+	TagForm   = "form"
+)
+
+// DefaultBindMaxBodySize is the request body limit applied by Bind and
+// BindForm when no BindConfig says otherwise. It matches Fiber's default
+// BodyLimit.
+const DefaultBindMaxBodySize int64 = 4 << 20
+
+// bindMultipartMemory is handed to multipart.Reader.ReadForm. The body is
+// already bounded by MaxBodySize, so this only decides when parts spill to
+// temporary files.
+const bindMultipartMemory int64 = 32 << 20
+
+// bindSourceBody is the source reported by errors about the request body.
+const bindSourceBody = "body"
+
+// maxBindKeyDepth bounds nested keys like a[b][c]; deeper keys are treated
+// as literal names.
+const maxBindKeyDepth = 16
+
+// BindConfig controls how Bind and the source specific Context methods read
+// the request.
+type BindConfig struct {
+	// MaxBodySize caps the bytes read by Bind and BindForm. Zero uses
+	// DefaultBindMaxBodySize and a negative value disables the limit.
+	MaxBodySize int64
+	// DisallowUnknownFields rejects JSON properties, form fields and query
+	// parameters that do not map to a field of the destination.
+	DisallowUnknownFields bool
+	// DisableValidation skips the `validate` tag checks that run after each
+	// binder. See Validate.
+	DisableValidation bool
+	// ValidationStatus is the HTTP status of validation failures. Zero keeps
+	// 400; set http.StatusUnprocessableEntity to tell them apart from
+	// malformed requests.
+	ValidationStatus int
+}
+
+// WithBindConfig returns a copy of ctx carrying cfg for the binders.
+func WithBindConfig(ctx context.Context, cfg BindConfig) context.Context {
+	return context.WithValue(ctx, contextKeyBindConfig, cfg)
+}
+
+// BindConfigFromContext returns the BindConfig stored in ctx, with defaults
+// applied.
+func BindConfigFromContext(ctx context.Context) BindConfig {
+	var cfg BindConfig
+	if ctx != nil {
+		cfg, _ = ctx.Value(contextKeyBindConfig).(BindConfig)
+	}
+	if cfg.MaxBodySize == 0 {
+		cfg.MaxBodySize = DefaultBindMaxBodySize
+	}
+	return cfg
+}
+
+// BindConfigMiddleware applies cfg to every binder called by the handlers
+// that follow it.
+func BindConfigMiddleware(cfg BindConfig) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.SetContext(WithBindConfig(c.Context(), cfg))
+			return next(c)
+		}
+	}
+}
+
+// bindBody decodes the request body into v, choosing the decoder from the
+// Content-Type header. JSON is assumed when the header is missing.
+func bindBody(c Context, v any) error {
+	return bindBodyAs(c, v, false)
+}
+
+func bindBodyAs(c Context, v any, allowEmpty bool) error {
+	if v == nil {
+		return fmt.Errorf("bind: nil interface provided")
+	}
+
+	cfg := BindConfigFromContext(c.Context())
+	mediaType, params := bindMediaType(c)
+	body, err := readBindBody(c, cfg.MaxBodySize)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		if allowEmpty || isFormMediaType(mediaType) {
+			return validateBound(v, "", cfg)
+		}
+		return newBindError(bindSourceBody, "request body is empty")
+	}
+
+	switch {
+	case mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		err = decodeJSONBody(body, v, cfg)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		if xmlErr := xml.Unmarshal(body, v); xmlErr != nil {
+			err = newBindError(bindSourceBody, "malformed XML: "+xmlErr.Error())
+		}
+	case isFormMediaType(mediaType):
+		err = decodeFormBody(body, mediaType, params, v, cfg)
+	default:
+		err = newUnsupportedMediaTypeError(mediaType)
+	}
+	if err != nil {
+		return err
+	}
+	return validateBound(v, "", cfg)
+}
+
+// bindForm decodes a urlencoded or multipart body into the `form` tagged
+// fields of v. Uploaded files are left to FormFile.
+func bindForm(c Context, v any) error {
+	if err := checkBindStruct(v); err != nil {
+		return err
+	}
+
+	cfg := BindConfigFromContext(c.Context())
+	mediaType, params := bindMediaType(c)
+	body, err := readBindBody(c, cfg.MaxBodySize)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if !isFormMediaType(mediaType) {
+			return newUnsupportedMediaTypeError(mediaType)
+		}
+		if err := decodeFormBody(body, mediaType, params, v, cfg); err != nil {
+			return err
+		}
+	}
+	return validateBound(v, "", cfg)
+}
+
+// bindQuery decodes the query string into the `query` tagged fields of v.
+func bindQuery(c Context, v any) error {
+	if err := checkBindStruct(v); err != nil {
+		return err
+	}
+	cfg := BindConfigFromContext(c.Context())
+	if err := bindValues(v, requestQuery(c), TagQuery, cfg.DisallowUnknownFields); err != nil {
+		return err
+	}
+	return validateBound(v, TagQuery, cfg)
+}
+
+// bindParams decodes route parameters into the `path` tagged fields of v.
+func bindParams(c Context, v any) error {
+	return bindLookup(c, v, TagPath, func(name string) string { return c.Param(name) })
+}
+
+// bindHeaders decodes request headers into the `header` tagged fields of v.
+// Slice fields read the header as a comma separated list.
+func bindHeaders(c Context, v any) error {
+	return bindLookup(c, v, TagHeader, c.Header)
+}
+
+// bindCookies decodes request cookies into the `cookie` tagged fields of v.
+func bindCookies(c Context, v any) error {
+	return bindLookup(c, v, TagCookie, func(name string) string { return c.Cookies(name) })
+}
+
+// validateBound runs the `validate` rules of the fields bound from source,
+// where "" stands for the body.
+func validateBound(v any, source string, cfg BindConfig) error {
+	if cfg.DisableValidation {
+		return nil
+	}
+	err := validateSource(v, source, false)
+	var validationErr *goerrors.Error
+	if cfg.ValidationStatus != 0 && errors.As(err, &validationErr) && validationErr.TextCode == "VALIDATION_ERROR" {
+		validationErr.Code = cfg.ValidationStatus
+	}
+	return err
+}
+
+func bindMediaType(c Context) (string, map[string]string) {
+	contentType := c.Header("Content-Type")
+	if contentType == "" {
+		return "", nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0])), nil
+	}
+	return mediaType, params
+}
+
+func isFormMediaType(mediaType string) bool {
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+// readBindBody reads at most limit bytes of the request body. net/http
+// requests are read incrementally and their body is restored for later
+// readers; other adapters already hold the body in memory.
+func readBindBody(c Context, limit int64) ([]byte, error) {
+	if hc, ok := AsHTTPContext(c); ok && hc.Request() != nil {
+		req := hc.Request()
+		if req.Body == nil || req.Body == http.NoBody {
+			return nil, nil
+		}
+		if limit > 0 && req.ContentLength > limit {
+			return nil, newBodyTooLargeError(limit)
+		}
+
+		reader := io.Reader(req.Body)
+		if limit > 0 {
+			reader = io.LimitReader(req.Body, limit+1)
+		}
+		body, err := io.ReadAll(reader)
+		req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+		if err != nil {
+			return nil, newBindError(bindSourceBody, "failed to read request body: "+err.Error())
+		}
+		if limit > 0 && int64(len(body)) > limit {
+			return nil, newBodyTooLargeError(limit)
+		}
+		return body, nil
+	}
+
+	body := c.Body()
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, newBodyTooLargeError(limit)
+	}
+	return body, nil
+}
+
+func decodeJSONBody(body []byte, v any, cfg BindConfig) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if cfg.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return jsonBindError(err)
+	}
+	if dec.More() {
+		return newBindError(bindSourceBody, "request body must contain a single JSON value")
+	}
+	return nil
+}
+
+func jsonBindError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return newBindError(bindSourceBody, "invalid request body", goerrors.FieldError{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+		})
+	}
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return newBindError(bindSourceBody, "invalid request body", goerrors.FieldError{
+			Field:   strings.Trim(name, `"`),
+			Message: "unknown field",
+		})
+	}
+	var invalid *json.InvalidUnmarshalError
+	if errors.As(err, &invalid) {
+		return fmt.Errorf("bind: %w", err)
+	}
+	return newBindError(bindSourceBody, "malformed JSON: "+err.Error())
+}
+
+func decodeFormBody(body []byte, mediaType string, params map[string]string, v any, cfg BindConfig) error {
+	var values url.Values
+	if mediaType == "multipart/form-data" {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return newBindError(TagForm, "multipart body has no boundary")
+		}
+		form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(bindMultipartMemory)
+		if err != nil {
+			return newBindError(TagForm, "malformed multipart body: "+err.Error())
+		}
+		defer form.RemoveAll() //nolint:errcheck
+		values = url.Values(form.Value)
+	} else {
+		parsed, err := url.ParseQuery(string(body))
+		if err != nil {
+			return newBindError(TagForm, "malformed form body: "+err.Error())
+		}
+		values = parsed
+	}
+
+	if err := checkBindStruct(v); err != nil {
+		return err
+	}
+	return bindValues(v, values, TagForm, cfg.DisallowUnknownFields)
+}
+
+func requestQuery(c Context) url.Values {
+	if hc, ok := AsHTTPContext(c); ok && hc.Request() != nil && hc.Request().URL != nil {
+		return hc.Request().URL.Query()
+	}
+	u, err := url.Parse(c.OriginalURL())
+	if err != nil {
+		return url.Values{}
+	}
+	return u.Query()
+}
+
+func checkBindStruct(v any) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: target must be a non-nil pointer to a struct, got %T", v)
+	}
+	return nil
+}
+
+// bindNode is a tree of values built from keys such as filter[name] or
+// ids[]. Plain keys are leaves of the root.
+type bindNode struct {
+	values   []string
+	children map[string]*bindNode
+}
+
+func newBindTree(values url.Values) *bindNode {
+	root := &bindNode{}
+	for key, vals := range values {
+		node := root
+		for _, part := range splitBindKey(key) {
+			node = node.child(part)
+		}
+		node.values = append(node.values, vals...)
+	}
+	return root
+}
+
+func (n *bindNode) child(name string) *bindNode {
+	if n.children == nil {
+		n.children = map[string]*bindNode{}
+	}
+	child, ok := n.children[name]
+	if !ok {
+		child = &bindNode{}
+		n.children[name] = child
+	}
+	return child
+}
+
+// splitBindKey splits "filter[name][]" into ["filter", "name", ""]. Keys that
+// are not well formed are returned whole.
+func splitBindKey(key string) []string {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+	parts := []string{key[:open]}
+	for rest := key[open:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 || len(parts) == maxBindKeyDepth {
+			return []string{key}
+		}
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return parts
+}
+
+func bindFieldPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "[" + name + "]"
+}
+
+// valueBinder decodes a bindNode tree into a struct, collecting one field
+// error per bad value.
+type valueBinder struct {
+	tag             string
+	disallowUnknown bool
+	errs            []goerrors.FieldError
+}
+
+func bindValues(v any, values url.Values, tag string, disallowUnknown bool) error {
+	b := &valueBinder{tag: tag, disallowUnknown: disallowUnknown}
+	b.bindStruct(reflect.ValueOf(v).Elem(), newBindTree(values), "")
+	return b.err()
+}
+
+func (b *valueBinder) err() error {
+	if len(b.errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(b.errs, func(i, j int) bool { return b.errs[i].Field < b.errs[j].Field })
+	return newBindError(b.tag, bindSourceMessage(b.tag), b.errs...)
+}
+
+func (b *valueBinder) fail(path, message string, value any) {
+	b.errs = append(b.errs, goerrors.FieldError{Field: path, Message: message, Value: value})
+}
+
+// bindStruct fills the fields of rv from node. Top level fields bind only
+// when tagged; fields of nested structs fall back to their JSON name and
+// then their Go name.
+func (b *valueBinder) bindStruct(rv reflect.Value, node *bindNode, path string) {
+	used := map[string]bool{}
+	for _, field := range bindableFields(rv.Type(), b.tag, path == "") {
+		child, ok := node.children[field.name]
+		if !ok {
+			continue
+		}
+		used[field.name] = true
+		b.bind(rv.FieldByIndex(field.index), child, bindFieldPath(path, field.name))
+	}
+
+	if b.disallowUnknown {
+		for name := range node.children {
+			if !used[name] {
+				b.fail(bindFieldPath(path, name), "unknown field", nil)
+			}
+		}
+	}
+}
+
+func (b *valueBinder) bind(rv reflect.Value, node *bindNode, path string) {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		b.bind(rv.Elem(), node, path)
+		return
+	}
+
+	switch {
+	case reflect.PointerTo(rv.Type()).Implements(textUnmarshalerType), isScalarKind(rv.Kind()), isBytes(rv.Type()):
+		if len(node.values) == 0 {
+			b.fail(path, "expected a value, got nested keys", nil)
+			return
+		}
+		if err := setFieldFromStrings(rv, node.values[:1]); err != nil {
+			b.fail(path, err.Error(), node.values[0])
+		}
+	case rv.Kind() == reflect.Struct:
+		if len(node.values) > 0 {
+			b.fail(path, "expected nested keys, got a value", node.values[0])
+			return
+		}
+		b.bindStruct(rv, node, path)
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if len(node.values) > 0 {
+			b.fail(path, "expected nested keys, got a value", node.values[0])
+			return
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		for key, child := range node.children {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			b.bind(elem, child, bindFieldPath(path, key))
+			rv.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), elem)
+		}
+	case rv.Kind() == reflect.Slice:
+		b.bindSlice(rv, node, path)
+	default:
+		b.fail(path, fmt.Sprintf("unsupported field type %s", rv.Type()), nil)
+	}
+}
+
+// bindSlice accepts repeated keys (ids=1&ids=2), empty brackets (ids[]=1)
+// and indexes (items[0][name]=x). Indexes order the elements but are not
+// positions, so sparse indexes do not allocate.
+func (b *valueBinder) bindSlice(rv reflect.Value, node *bindNode, path string) {
+	var elems []reflect.Value
+	appendValues := func(values []string) {
+		for _, value := range values {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			b.bind(elem, &bindNode{values: []string{value}}, bindFieldPath(path, strconv.Itoa(len(elems))))
+			elems = append(elems, elem)
+		}
+	}
+
+	appendValues(node.values)
+	indexes := make([]int, 0, len(node.children))
+	for key, child := range node.children {
+		if key == "" {
+			if len(child.children) > 0 {
+				b.fail(bindFieldPath(path, ""), "nested keys need an index", nil)
+				continue
+			}
+			appendValues(child.values)
+			continue
+		}
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 {
+			b.fail(bindFieldPath(path, key), "expected an index", nil)
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		b.bind(elem, node.children[strconv.Itoa(index)], bindFieldPath(path, strconv.Itoa(index)))
+		elems = append(elems, elem)
+	}
+
+	slice := reflect.MakeSlice(rv.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		slice.Index(i).Set(elem)
+	}
+	rv.Set(slice)
+}
+
+// bindLookup fills the fields of v tagged with tag using lookup. Empty values
+// leave the field untouched.
+func bindLookup(c Context, v any, tag string, lookup func(name string) string) error {
+	if err := checkBindStruct(v); err != nil {
+		return err
+	}
+
+	var errs []goerrors.FieldError
+	rv := reflect.ValueOf(v).Elem()
+	for _, field := range bindableFields(rv.Type(), tag, true) {
+		raw := lookup(field.name)
+		if raw == "" {
+			continue
+		}
+		target := rv.FieldByIndex(field.index)
+		values := []string{raw}
+		if tag == TagHeader && isListField(target.Type()) {
+			values = strings.Split(raw, ",")
+			for i := range values {
+				values[i] = strings.TrimSpace(values[i])
+			}
+		}
+		if err := setFieldFromStrings(target, values); err != nil {
+			errs = append(errs, goerrors.FieldError{Field: field.name, Message: err.Error(), Value: raw})
+		}
+	}
+	if len(errs) > 0 {
+		return newBindError(tag, bindSourceMessage(tag), errs...)
+	}
+	return validateBound(v, tag, BindConfigFromContext(c.Context()))
+}
+
+type bindableField struct {
+	index []int
+	name  string
+}
+
+// bindableFields lists the fields of t that bind under tag. Untagged embedded
+// structs are flattened.
+func bindableFields(t reflect.Type, tag string, requireTag bool) []bindableField {
+	var fields []bindableField
+	for field := range t.Fields() {
+		value, tagged := field.Tag.Lookup(tag)
+		name := strings.Split(value, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && !tagged && field.Type.Kind() == reflect.Struct {
+			for _, inner := range bindableFields(field.Type, tag, requireTag) {
+				inner.index = append([]int{field.Index[0]}, inner.index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if !field.IsExported() || (requireTag && !tagged) {
+			continue
+		}
+		if name == "" && !requireTag {
+			name = strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, bindableField{index: field.Index, name: name})
+	}
+	return fields
+}
+
+func bindSourceMessage(source string) string {
+	switch source {
+	case TagQuery:
+		return "invalid query parameters"
+	case TagPath:
+		return "invalid path parameters"
+	case TagHeader:
+		return "invalid headers"
+	case TagCookie:
+		return "invalid cookies"
+	case TagForm:
+		return "invalid form data"
+	default:
+		return "invalid request body"
+	}
+}
+
+// newBindError reports input that could not be bound. source is one of the
+// request tags or "body", and fields lists the offending values.
+func newBindError(source, message string, fields ...goerrors.FieldError) *goerrors.Error {
+	err := goerrors.New(message, goerrors.CategoryBadInput).
+		WithCode(http.StatusBadRequest).
+		WithTextCode("BIND_ERROR").
+		WithMetadata(map[string]any{"source": source})
+	if len(fields) > 0 {
+		err.ValidationErrors = fields
+	}
+	return err
+}
+
+func newBodyTooLargeError(limit int64) *goerrors.Error {
+	return goerrors.New(fmt.Sprintf("request body exceeds %d bytes", limit), goerrors.CategoryBadInput).
+		WithCode(http.StatusRequestEntityTooLarge).
+		WithTextCode("REQUEST_TOO_LARGE").
+		WithMetadata(map[string]any{"source": bindSourceBody, "limit": limit})
+}
+
+func newUnsupportedMediaTypeError(mediaType string) *goerrors.Error {
+	return goerrors.New(fmt.Sprintf("unsupported content type %q", mediaType), goerrors.CategoryBadInput).
+		WithCode(http.StatusUnsupportedMediaType).
+		WithTextCode("UNSUPPORTED_MEDIA_TYPE").
+		WithMetadata(map[string]any{"source": bindSourceBody, "content_type": mediaType})
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func isListField(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice && !isBytes(t) && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setFieldFromStrings converts raw values into field. Slices take every value;
+// other kinds use the first.
+func setFieldFromStrings(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFieldFromStrings(elem.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+
+	if field.Kind() == reflect.Slice && !isBytes(field.Type()) {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFieldFromStrings(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setScalarFromString(field, values[0])
+}
+
+func setScalarFromString(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected a boolean")
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an unsigned integer")
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		field.SetFloat(v)
+	case reflect.Slice:
+		// []byte
+		field.SetBytes([]byte(raw))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package router_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+type bindingProfile struct {
+	Name string   `json:"name" xml:"name" form:"name"`
+	Age  int      `json:"age" xml:"age" form:"age"`
+	Tags []string `json:"tags" xml:"tag" form:"tags"`
+}
+
+type bindingSearch struct {
+	Org    string `path:"org"`
+	Filter struct {
+		Name string   `query:"name"`
+		Tags []string `query:"tags"`
+	} `query:"filter"`
+	IDs  []int `query:"ids"`
+	Sort []struct {
+		Field string `query:"field"`
+	} `query:"sort"`
+	Meta     map[string]string `query:"meta"`
+	Page     *int              `query:"page"`
+	Trace    string            `header:"X-Trace-ID"`
+	Language []string          `header:"Accept-Language"`
+}
+
+func registerBindingRoutes[T any](r router.Router[T]) {
+	strict := router.BindConfigMiddleware(router.BindConfig{MaxBodySize: 64, DisallowUnknownFields: true})
+
+	bindProfile := func(c router.Context) error {
+		var profile bindingProfile
+		if err := c.Bind(&profile); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, profile)
+	}
+	r.Post("/api/profile", bindProfile)
+	r.Post("/api/strict/profile", bindProfile, strict)
+	r.Post("/api/profile/form", func(c router.Context) error {
+		var profile bindingProfile
+		if err := c.BindForm(&profile); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, profile)
+	})
+
+	search := func(c router.Context) error {
+		var req bindingSearch
+		for _, bind := range []func(any) error{c.BindParams, c.BindQuery, c.BindHeaders} {
+			if err := bind(&req); err != nil {
+				return err
+			}
+		}
+		return c.JSON(http.StatusOK, req)
+	}
+	r.Get("/api/orgs/:org/search", search)
+	r.Get("/api/strict/orgs/:org/search", search, strict)
+}
+
+var bindingSetup = routertest.Setup{
+	Fiber:      registerBindingRoutes,
+	HTTPRouter: registerBindingRoutes,
+	ServeMux:   registerBindingRoutes,
+}
+
+func TestBind_ChoosesDecoderFromContentType(t *testing.T) {
+	var multipartBody bytes.Buffer
+	writer := multipart.NewWriter(&multipartBody)
+	require.NoError(t, writer.WriteField("name", "Ada"))
+	require.NoError(t, writer.WriteField("age", "36"))
+	require.NoError(t, writer.WriteField("tags", "a"))
+	require.NoError(t, writer.WriteField("tags", "b"))
+	require.NoError(t, writer.Close())
+
+	bodies := map[string]func(*routertest.RequestBuilder) *routertest.RequestBuilder{
+		"json": func(r *routertest.RequestBuilder) *routertest.RequestBuilder {
+			return r.JSON(map[string]any{"name": "Ada", "age": 36, "tags": []string{"a", "b"}})
+		},
+		"no content type": func(r *routertest.RequestBuilder) *routertest.RequestBuilder {
+			return r.Body(strings.NewReader(`{"name":"Ada","age":36,"tags":["a","b"]}`), "")
+		},
+		"xml": func(r *routertest.RequestBuilder) *routertest.RequestBuilder {
+			return r.Body(strings.NewReader(`<profile><name>Ada</name><age>36</age><tag>a</tag><tag>b</tag></profile>`), "application/xml")
+		},
+		"form": func(r *routertest.RequestBuilder) *routertest.RequestBuilder {
+			return r.Form(url.Values{"name": {"Ada"}, "age": {"36"}, "tags[]": {"a", "b"}})
+		},
+		"multipart": func(r *routertest.RequestBuilder) *routertest.RequestBuilder {
+			return r.Body(bytes.NewReader(multipartBody.Bytes()), writer.FormDataContentType())
+		},
+	}
+
+	routertest.EachAdapter(t, bindingSetup, func(t *testing.T, client *routertest.Client) {
+		for format, body := range bodies {
+			body(client.Post("/api/profile")).Do(t).
+				AssertStatus(http.StatusOK).
+				AssertJSONPath("name", "Ada").
+				AssertJSONPath("age", 36).
+				AssertJSONPath("tags", []string{"a", "b"})
+			if format == "form" || format == "multipart" {
+				body(client.Post("/api/profile/form")).Do(t).
+					AssertStatus(http.StatusOK).
+					AssertJSONPath("tags", []string{"a", "b"})
+			}
+		}
+
+		client.Post("/api/profile").Body(strings.NewReader("name: Ada"), "text/yaml").Do(t).
+			AssertStatus(http.StatusUnsupportedMediaType)
+		client.Post("/api/profile/form").JSON(map[string]string{"name": "Ada"}).Do(t).
+			AssertStatus(http.StatusUnsupportedMediaType)
+		client.Post("/api/profile").Body(strings.NewReader(`{"name":`), "application/json").Do(t).
+			AssertStatus(http.StatusBadRequest)
+	})
+}
+
+func TestBind_QueryParamsAndHeaders(t *testing.T) {
+	query := "filter[name]=ada&filter[tags][]=a&filter[tags][]=b&ids=1&ids=2" +
+		"&sort[1][field]=age&sort[0][field]=name&meta[team]=core&page=3&other=ignored"
+
+	routertest.EachAdapter(t, bindingSetup, func(t *testing.T, client *routertest.Client) {
+		client.Get("/api/orgs/acme/search?"+query).
+			Header("X-Trace-ID", "trace-1").
+			Header("Accept-Language", "en, fr").
+			Do(t).
+			AssertStatus(http.StatusOK).
+			AssertJSONPath("Org", "acme").
+			AssertJSONPath("Filter.Name", "ada").
+			AssertJSONPath("Filter.Tags", []string{"a", "b"}).
+			AssertJSONPath("IDs", []int{1, 2}).
+			AssertJSONPath("Sort.0.Field", "name").
+			AssertJSONPath("Sort.1.Field", "age").
+			AssertJSONPath("Meta.team", "core").
+			AssertJSONPath("Page", 3).
+			AssertJSONPath("Trace", "trace-1").
+			AssertJSONPath("Language", []string{"en", "fr"})
+
+		res := client.Get("/api/orgs/acme/search?ids=1&ids=x&page=y").Do(t).
+			AssertStatus(http.StatusBadRequest)
+		assert.Contains(t, res.String(), "ids[1]")
+		assert.Contains(t, res.String(), "BIND_ERROR")
+	})
+}
+
+func TestBind_ConfigLimitsAndUnknownFields(t *testing.T) {
+	routertest.EachAdapter(t, bindingSetup, func(t *testing.T, client *routertest.Client) {
+		client.Post("/api/strict/profile").JSON(map[string]string{"name": "Ada"}).Do(t).
+			AssertStatus(http.StatusOK)
+		client.Post("/api/strict/profile").JSON(map[string]string{"name": "Ada", "role": "admin"}).Do(t).
+			AssertStatus(http.StatusBadRequest)
+		client.Post("/api/strict/profile").JSON(map[string]string{"name": strings.Repeat("a", 100)}).Do(t).
+			AssertStatus(http.StatusRequestEntityTooLarge)
+		client.Post("/api/profile").JSON(map[string]string{"name": "Ada", "role": "admin"}).Do(t).
+			AssertStatus(http.StatusOK)
+
+		client.Get("/api/strict/orgs/acme/search?ids=1").Do(t).AssertStatus(http.StatusOK)
+		res := client.Get("/api/strict/orgs/acme/search?ids=1&other=x&filter[nope]=y").Do(t).
+			AssertStatus(http.StatusBadRequest)
+		assert.Contains(t, res.String(), "filter[nope]")
+		assert.Contains(t, res.String(), `"other"`)
+	})
+}
@@ -0,0 +1,604 @@
+package router
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// CachePolicy sets which responses the Cache middleware stores, how long for
+// and under which tags.
+type CachePolicy struct {
+	// TTL is how long a stored response is fresh. Zero leaves it to the
+	// s-maxage or max-age directives of the response Cache-Control.
+	TTL time.Duration `json:"ttl,omitempty"`
+	// StaleWhileRevalidate is how long after TTL a response is still served
+	// while one request refreshes it. Zero leaves it to the
+	// stale-while-revalidate directive of the response Cache-Control.
+	StaleWhileRevalidate time.Duration `json:"stale_while_revalidate,omitempty"`
+	// Query lists the query parameters that select a response; requests only
+	// differing in others, such as tracking parameters, share an entry.
+	// Defaults to all parameters.
+	Query []string `json:"query,omitempty"`
+	// Vary lists the request headers that select a response, such as
+	// Accept-Language. Responses varying on other headers are not stored.
+	Vary []string `json:"vary,omitempty"`
+	// Tags label the stored responses for invalidation, in addition to the
+	// route name. "{name}" is replaced by the route param name, so
+	// "user:{id}" tags the responses of each user apart.
+	Tags []string `json:"tags,omitempty"`
+	// Invalidates lists the tags purged when a request to an unsafe route,
+	// such as PUT or DELETE, succeeds. It takes "{name}" params like Tags.
+	Invalidates []string `json:"invalidates,omitempty"`
+}
+
+// CacheEntry is a stored response.
+type CacheEntry struct {
+	Response *CapturedResponse
+	Tags     []string
+	StoredAt time.Time
+	// Expires is when the response stops being fresh.
+	Expires time.Time
+	// StaleUntil is when the response stops being served while it is
+	// revalidated. Stores may drop the entry from then on.
+	StaleUntil time.Time
+}
+
+// CacheStore keeps the responses of the Cache middleware. Implement it over a
+// shared backend, such as Redis, to share responses across instances. Get
+// returns nil without an error for missing keys.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	Set(ctx context.Context, key string, entry *CacheEntry) error
+	// Invalidate drops the entries labelled with any of tags.
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// CacheConfig configures the Cache middleware.
+type CacheConfig struct {
+	// CachePolicy applies to routes without a policy of their own, see
+	// SetCachePolicy.
+	CachePolicy
+	Skip func(Context) bool
+	// Store defaults to a NewInMemoryCacheStore of 1000 entries.
+	Store CacheStore
+	// MaxBodySize is the largest response stored, in bytes. Defaults to
+	// DefaultMaxCapturedBodySize. Larger and streamed responses fail with
+	// ErrResponseCaptureTooLarge and ErrResponseCaptureStream, so keep
+	// them off cached routes.
+	MaxBodySize int64
+	// Logger logs store failures, which let the request through uncached.
+	Logger Logger
+}
+
+// SetCachePolicy sets the cache policy of a single route, in place of the
+// policy of the Cache middleware:
+//
+//	router.SetCachePolicy(r.Get("/users/:id", show).SetName("user:read"), router.CachePolicy{
+//		TTL:  time.Minute,
+//		Tags: []string{"user:{id}"},
+//	})
+//	router.SetCachePolicy(r.Put("/users/:id", update).SetName("user:update"), router.CachePolicy{
+//		Invalidates: []string{"user:{id}"},
+//	})
+func SetCachePolicy(ri RouteInfo, policy CachePolicy) RouteInfo {
+	if route, ok := ri.(*RouteDefinition); ok {
+		route.Cache = &policy
+	}
+	return ri
+}
+
+// Cache returns a middleware that stores GET and HEAD responses and replays
+// them until they expire. Entries are keyed by method, route, params, the
+// policy query parameters and Vary headers. Concurrent requests for a missing
+// entry wait for a single run of the handler. Requests for an entry in its
+// stale-while-revalidate window get it at once, while the first of them
+// refreshes it in the background on a copy of the request, without the Locals
+// set by earlier middleware. Replayed responses carry Age and X-Cache headers.
+//
+// Requests with a Cookie or Authorization header bypass the cache, unless the
+// policy varies on that header, so one user is never served another's
+// response. Responses that set cookies, vary on headers outside the policy, or
+// are marked no-store, no-cache or private are not stored.
+func Cache(config ...CacheConfig) MiddlewareFunc {
+	var cfg CacheConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewInMemoryCacheStore(1000)
+	}
+	rc := &responseCache{config: &cfg, flights: make(map[string]*cacheFlight)}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			policy := &cfg.CachePolicy
+			route, _ := routeFromContext(c.Context())
+			if route != nil && route.Cache != nil {
+				policy = route.Cache
+			}
+
+			switch c.Method() {
+			case http.MethodGet, http.MethodHead:
+			default:
+				if len(policy.Invalidates) == 0 {
+					return next(c)
+				}
+				if err := next(c); err != nil {
+					return err
+				}
+				if state, ok := AsResponseState(c); ok && state.StatusCode() >= http.StatusBadRequest {
+					return nil
+				}
+				if err := cfg.Store.Invalidate(c.Context(), expandCacheTags(policy.Invalidates, c.RouteParams())...); err != nil {
+					rc.warn("cache invalidation failed: %v", err)
+				}
+				return nil
+			}
+
+			if credentialedCacheRequest(c, policy) {
+				return next(c)
+			}
+			return rc.serve(c, next, cacheKey(c, route, policy), policy)
+		}
+	}
+}
+
+// credentialedCacheRequest reports whether c carries credentials the policy
+// does not key entries by.
+func credentialedCacheRequest(c Context, policy *CachePolicy) bool {
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if c.Header(name) != "" && !containsFold(policy.Vary, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type responseCache struct {
+	config  *CacheConfig
+	mu      sync.Mutex
+	flights map[string]*cacheFlight
+}
+
+// cacheFlight is a handler run others wait on. entry is set before done is
+// closed when the response was stored.
+type cacheFlight struct {
+	done  chan struct{}
+	entry *CacheEntry
+}
+
+func (rc *responseCache) serve(c Context, next HandlerFunc, key string, policy *CachePolicy) error {
+	entry, err := rc.lookup(c, key)
+	if err != nil {
+		rc.warn("cache lookup failed: %v", err)
+		return next(c)
+	}
+	now := time.Now()
+	if entry != nil && now.Before(entry.Expires) {
+		return replayCacheEntry(c, entry, "HIT", now)
+	}
+
+	rc.mu.Lock()
+	flight, running := rc.flights[key]
+	if !running {
+		flight = &cacheFlight{done: make(chan struct{})}
+		rc.flights[key] = flight
+	}
+	rc.mu.Unlock()
+
+	switch {
+	case running && entry != nil:
+		return replayCacheEntry(c, entry, "STALE", now)
+	case running:
+		return rc.await(c, next, key, policy, flight)
+	case entry != nil:
+		if refresh, ok := detachCacheRequest(c); ok {
+			go rc.refresh(refresh, next, key, policy, flight)
+			return replayCacheEntry(c, entry, "STALE", now)
+		}
+	default:
+		// The previous flight may have stored the entry since the lookup.
+		if stored, err := rc.lookup(c, key); err == nil && stored != nil && now.Before(stored.Expires) {
+			rc.land(key, flight)
+			return replayCacheEntry(c, stored, "HIT", now)
+		}
+	}
+
+	defer rc.land(key, flight)
+	return rc.fill(c, next, key, policy, flight)
+}
+
+// lookup returns the entry stored under key, or nil once it is past its
+// stale-while-revalidate window.
+func (rc *responseCache) lookup(c Context, key string) (*CacheEntry, error) {
+	entry, err := rc.config.Store.Get(c.Context(), key)
+	if err != nil || entry == nil || !time.Now().Before(entry.StaleUntil) {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// await waits for flight and replays its response, or runs the handler when
+// the response was not stored.
+func (rc *responseCache) await(c Context, next HandlerFunc, key string, policy *CachePolicy, flight *cacheFlight) error {
+	select {
+	case <-flight.done:
+	case <-c.Context().Done():
+		return c.Context().Err()
+	}
+	if flight.entry != nil {
+		return replayCacheEntry(c, flight.entry, "HIT", time.Now())
+	}
+	return rc.fill(c, next, key, policy, nil)
+}
+
+// land ends flight, releasing the requests waiting on it.
+func (rc *responseCache) land(key string, flight *cacheFlight) {
+	rc.mu.Lock()
+	delete(rc.flights, key)
+	rc.mu.Unlock()
+	close(flight.done)
+}
+
+// fill runs the handler, stores its response when it may be and sends it.
+func (rc *responseCache) fill(c Context, next HandlerFunc, key string, policy *CachePolicy, flight *cacheFlight) error {
+	captured, err := CaptureResponse(c, rc.config.MaxBodySize, next)
+	if err != nil {
+		return err
+	}
+	rc.store(c, key, policy, captured, flight)
+	c.SetHeader("X-Cache", "MISS")
+	return ReplayCapturedResponse(c, captured)
+}
+
+// refresh runs the handler for a stale entry after the request that found it
+// has been answered.
+func (rc *responseCache) refresh(c Context, next HandlerFunc, key string, policy *CachePolicy, flight *cacheFlight) {
+	defer rc.land(key, flight)
+	defer func() {
+		if r := recover(); r != nil {
+			rc.warn("cache refresh panicked: %v", r)
+		}
+	}()
+
+	captured, err := CaptureResponse(c, rc.config.MaxBodySize, next)
+	if err != nil {
+		rc.warn("cache refresh failed: %v", err)
+		return
+	}
+	rc.store(c, key, policy, captured, flight)
+}
+
+func (rc *responseCache) store(c Context, key string, policy *CachePolicy, captured *CapturedResponse, flight *cacheFlight) {
+	entry := newCacheEntry(c, policy, captured, time.Now())
+	if entry == nil {
+		return
+	}
+	if err := rc.config.Store.Set(c.Context(), key, entry); err != nil {
+		rc.warn("cache store failed: %v", err)
+	} else if flight != nil {
+		flight.entry = entry
+	}
+}
+
+func (rc *responseCache) warn(format string, args ...any) {
+	if rc.config.Logger != nil {
+		rc.config.Logger.Warn(format, args...)
+	}
+}
+
+func replayCacheEntry(c Context, entry *CacheEntry, status string, now time.Time) error {
+	c.SetHeader("Age", strconv.Itoa(int(now.Sub(entry.StoredAt).Seconds())))
+	c.SetHeader("X-Cache", status)
+	return ReplayCapturedResponse(c, entry.Response)
+}
+
+// detachCacheRequest returns a context for a copy of the request of c that
+// stays valid after c is answered, so a stale entry can be refreshed in the
+// background. Adapters may reuse the memory of a request once it is answered,
+// so every string is copied.
+func detachCacheRequest(c Context) (Context, bool) {
+	hc, ok := AsHTTPContext(c)
+	if !ok || hc.Request() == nil {
+		return nil, false
+	}
+	original := hc.Request()
+	target, err := url.ParseRequestURI(strings.Clone(c.OriginalURL()))
+	if err != nil {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(c.RouteParams()))
+	routeParams := make(httprouter.Params, 0, len(params))
+	for name, value := range c.RouteParams() {
+		name, value = strings.Clone(name), strings.Clone(value)
+		params[name] = value
+		routeParams = append(routeParams, httprouter.Param{Key: name, Value: value})
+	}
+	ctx := WithRouteParams(context.WithoutCancel(c.Context()), params)
+
+	req := (&http.Request{
+		Method:     strings.Clone(original.Method),
+		URL:        target,
+		Proto:      strings.Clone(original.Proto),
+		ProtoMajor: original.ProtoMajor,
+		ProtoMinor: original.ProtoMinor,
+		Header:     make(http.Header, len(original.Header)),
+		Body:       http.NoBody,
+		Host:       strings.Clone(original.Host),
+		RemoteAddr: strings.Clone(original.RemoteAddr),
+		RequestURI: target.RequestURI(),
+	}).WithContext(ctx)
+	for name, values := range original.Header {
+		for _, value := range values {
+			req.Header.Add(strings.Clone(name), strings.Clone(value))
+		}
+	}
+	return NewHTTPRouterContext(discardResponseWriter{header: make(http.Header)}, req, routeParams, nil), true
+}
+
+// discardResponseWriter drops the response of a background refresh, which is
+// captured before it would be written.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w discardResponseWriter) Header() http.Header         { return w.header }
+func (w discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w discardResponseWriter) WriteHeader(int)             {}
+
+// cacheableStatus lists the statuses stored, those RFC 9110 lets caches reuse
+// by default.
+var cacheableStatus = map[int]bool{
+	http.StatusOK: true, http.StatusNonAuthoritativeInfo: true, http.StatusNoContent: true,
+	http.StatusMultipleChoices: true, http.StatusMovedPermanently: true, http.StatusPermanentRedirect: true,
+	http.StatusNotFound: true, http.StatusMethodNotAllowed: true, http.StatusGone: true,
+	http.StatusRequestURITooLong: true, http.StatusNotImplemented: true,
+}
+
+// newCacheEntry returns the entry to store for captured, or nil when the
+// response must not be stored.
+func newCacheEntry(c Context, policy *CachePolicy, captured *CapturedResponse, now time.Time) *CacheEntry {
+	status := captured.StatusCode
+	if status <= 0 {
+		status = http.StatusOK
+	}
+	header := captured.Headers
+	if !cacheableStatus[status] || len(header.Values("Set-Cookie")) > 0 {
+		return nil
+	}
+	for _, value := range header.Values("Vary") {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = strings.TrimSpace(name); name != "" && !containsFold(policy.Vary, name) {
+				return nil
+			}
+		}
+	}
+
+	ttl, stale := policy.TTL, policy.StaleWhileRevalidate
+	var maxAge, sharedMaxAge time.Duration = -1, -1
+	for _, value := range header.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			seconds, err := strconv.Atoi(strings.Trim(arg, `"`))
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache", "private":
+				return nil
+			case "max-age":
+				if err == nil {
+					maxAge = time.Duration(seconds) * time.Second
+				}
+			case "s-maxage":
+				if err == nil {
+					sharedMaxAge = time.Duration(seconds) * time.Second
+				}
+			case "stale-while-revalidate":
+				if err == nil && stale <= 0 {
+					stale = time.Duration(seconds) * time.Second
+				}
+			}
+		}
+	}
+	if ttl <= 0 {
+		ttl = maxAge
+		if sharedMaxAge >= 0 {
+			ttl = sharedMaxAge
+		}
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	tags := expandCacheTags(policy.Tags, c.RouteParams())
+	if name := c.RouteName(); name != "" {
+		tags = append(tags, name)
+	}
+	return &CacheEntry{
+		Response: &CapturedResponse{
+			StatusCode: status,
+			Headers:    cloneHTTPHeader(header),
+			Body:       captured.Body,
+		},
+		Tags:       tags,
+		StoredAt:   now,
+		Expires:    now.Add(ttl),
+		StaleUntil: now.Add(ttl + max(stale, 0)),
+	}
+}
+
+// cacheKey identifies the response to a request under policy.
+func cacheKey(c Context, route *RouteDefinition, policy *CachePolicy) string {
+	var b strings.Builder
+	b.WriteString(c.Method())
+	b.WriteByte(' ')
+	switch {
+	case route == nil:
+		b.WriteString(c.Path())
+	case c.RouteName() != "":
+		b.WriteString(c.RouteName())
+	default:
+		b.WriteString(route.Path)
+	}
+	if route != nil && route.Host != "" {
+		b.WriteString("|host=" + strings.ToLower(c.Header("Host")))
+	}
+
+	params := c.RouteParams()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("|" + name + "=" + url.QueryEscape(params[name]))
+	}
+
+	_, rawQuery, _ := strings.Cut(c.OriginalURL(), "?")
+	query, _ := url.ParseQuery(rawQuery)
+	if policy.Query != nil {
+		for name := range query {
+			if !slices.Contains(policy.Query, name) {
+				delete(query, name)
+			}
+		}
+	}
+	if len(query) > 0 {
+		b.WriteString("|?" + query.Encode())
+	}
+	for _, name := range policy.Vary {
+		b.WriteString("|" + http.CanonicalHeaderKey(name) + ":" + c.Header(name))
+	}
+	return b.String()
+}
+
+// expandCacheTags replaces the "{name}" params of tags.
+func expandCacheTags(tags []string, params map[string]string) []string {
+	expanded := make([]string, 0, len(tags)+1)
+	for _, tag := range tags {
+		for name, value := range params {
+			tag = strings.ReplaceAll(tag, "{"+name+"}", value)
+		}
+		expanded = append(expanded, tag)
+	}
+	return expanded
+}
+
+func containsFold(values []string, target string) bool {
+	return slices.ContainsFunc(values, func(value string) bool {
+		return strings.EqualFold(value, target)
+	})
+}
+
+// InMemoryCacheStore keeps responses in process memory, evicting the least
+// recently used entries beyond its capacity.
+type InMemoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+	tags       map[string]map[string]struct{}
+}
+
+type inMemoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewInMemoryCacheStore returns an empty store holding up to maxEntries
+// responses, 1000 when maxEntries is not positive.
+func NewInMemoryCacheStore(maxEntries int) *InMemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &InMemoryCacheStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+var _ CacheStore = (*InMemoryCacheStore)(nil)
+
+// Get returns the entry stored under key. See CacheStore.
+func (s *InMemoryCacheStore) Get(_ context.Context, key string) (*CacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	item := element.Value.(*inMemoryCacheItem)
+	if !time.Now().Before(item.entry.StaleUntil) {
+		s.remove(element)
+		return nil, nil
+	}
+	s.order.MoveToFront(element)
+	return item.entry, nil
+}
+
+// Set stores entry under key. See CacheStore.
+func (s *InMemoryCacheStore) Set(_ context.Context, key string, entry *CacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+	s.entries[key] = s.order.PushFront(&inMemoryCacheItem{key: key, entry: entry})
+	for _, tag := range entry.Tags {
+		if s.tags[tag] == nil {
+			s.tags[tag] = make(map[string]struct{})
+		}
+		s.tags[tag][key] = struct{}{}
+	}
+	for s.order.Len() > s.maxEntries {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Invalidate drops the entries labelled with any of tags. See CacheStore.
+func (s *InMemoryCacheStore) Invalidate(_ context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if element, ok := s.entries[key]; ok {
+				s.remove(element)
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored entries.
+func (s *InMemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *InMemoryCacheStore) remove(element *list.Element) {
+	item := element.Value.(*inMemoryCacheItem)
+	s.order.Remove(element)
+	delete(s.entries, item.key)
+	for _, tag := range item.entry.Tags {
+		delete(s.tags[tag], item.key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
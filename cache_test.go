@@ -0,0 +1,282 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func TestInMemoryCacheStore_EvictsAndInvalidates(t *testing.T) {
+	ctx := context.Background()
+	store := router.NewInMemoryCacheStore(2)
+	entry := func(tags ...string) *router.CacheEntry {
+		now := time.Now()
+		return &router.CacheEntry{Tags: tags, StoredAt: now, Expires: now.Add(time.Minute), StaleUntil: now.Add(time.Minute)}
+	}
+
+	require.NoError(t, store.Set(ctx, "a", entry("user:1")))
+	require.NoError(t, store.Set(ctx, "b", entry("user:2")))
+	got, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.NoError(t, store.Set(ctx, "c", entry("user:1")))
+	got, _ = store.Get(ctx, "b")
+	assert.Nil(t, got, "the least recently used entry is evicted")
+	assert.Equal(t, 2, store.Len())
+
+	require.NoError(t, store.Invalidate(ctx, "user:1"))
+	assert.Equal(t, 0, store.Len())
+
+	expired := entry()
+	expired.StaleUntil = time.Now()
+	require.NoError(t, store.Set(ctx, "d", expired))
+	got, _ = store.Get(ctx, "d")
+	assert.Nil(t, got)
+}
+
+func TestCache_CoalescesAndInvalidates(t *testing.T) {
+	var (
+		store   *cacheTestStore
+		calls   atomic.Int32
+		release chan struct{}
+	)
+	clients := map[string]func() *routertest.Client{
+		"fiber": func() *routertest.Client {
+			app := router.NewFiberAdapter()
+			cacheRoutes(app.Router(), store, &calls, release)
+			return routertest.NewClient(app)
+		},
+		"httprouter": func() *routertest.Client {
+			app := router.NewHTTPServer()
+			cacheRoutes(app.Router(), store, &calls, release)
+			return routertest.NewClient(app)
+		},
+		"servemux": func() *routertest.Client {
+			app := router.NewServeMuxServer()
+			cacheRoutes(app.Router(), store, &calls, release)
+			return routertest.NewClient(app)
+		},
+	}
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			store, release = newCacheTestStore(), make(chan struct{})
+			calls.Store(0)
+			client := newClient()
+
+			var wg sync.WaitGroup
+			bodies := make([]string, 5)
+			for i := range bodies {
+				wg.Go(func() {
+					bodies[i] = client.Get("/users/1").Do(t).String()
+				})
+			}
+			// Every request looks the entry up, and the one running the
+			// handler looks again before it does.
+			for range len(bodies) + 1 {
+				<-store.lookups
+			}
+			close(release)
+			wg.Wait()
+			assert.Equal(t, int32(1), calls.Load(), "concurrent misses run the handler once")
+			for _, body := range bodies {
+				assert.Equal(t, "user 1 #1", body)
+			}
+
+			res := client.Get("/users/1?utm_source=mail").Do(t).
+				AssertHeader("X-Cache", "HIT").
+				AssertBody("user 1 #1")
+			assert.NotEmpty(t, res.Header.Get("Age"), "unselected query parameters share the entry")
+			client.Get("/users/1?fields=name").Do(t).AssertHeader("X-Cache", "MISS")
+			client.Get("/users/2").Do(t).AssertHeader("X-Cache", "MISS").AssertBody("user 2 #3")
+			res = client.Get("/users/2").Header("Authorization", "Bearer token").Do(t)
+			assert.Empty(t, res.Header.Get("X-Cache"), "authorized requests are not cached")
+
+			client.Put("/users/1").Do(t).AssertStatus(http.StatusNoContent)
+			client.Get("/users/1").Do(t).AssertBody("user 1 #5")
+			client.Get("/users/2").Do(t).AssertHeader("X-Cache", "HIT")
+
+			client.Delete("/users").Do(t).AssertStatus(http.StatusNoContent)
+			client.Get("/users/2").Do(t).AssertHeader("X-Cache", "MISS")
+		})
+	}
+}
+
+func TestCache_ServesStaleWhileRevalidating(t *testing.T) {
+	var (
+		store            *cacheTestStore
+		version          atomic.Int32
+		entered, release chan struct{}
+	)
+	handler := func(c router.Context) error {
+		if version.Add(1) > 1 {
+			entered <- struct{}{}
+			<-release
+		}
+		c.SetHeader("Cache-Control", "max-age=1, stale-while-revalidate=60")
+		return c.SendString("report " + c.Param("id") + " " + c.Query("lang") + " v" + strconv.Itoa(int(version.Load())))
+	}
+	clients := map[string]func() *routertest.Client{
+		"fiber": func() *routertest.Client {
+			app := router.NewFiberAdapter()
+			staleRoutes(app.Router(), store, handler)
+			return routertest.NewClient(app)
+		},
+		"httprouter": func() *routertest.Client {
+			app := router.NewHTTPServer()
+			staleRoutes(app.Router(), store, handler)
+			return routertest.NewClient(app)
+		},
+		"servemux": func() *routertest.Client {
+			app := router.NewServeMuxServer()
+			staleRoutes(app.Router(), store, handler)
+			return routertest.NewClient(app)
+		},
+	}
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			store, entered, release = newCacheTestStore(), make(chan struct{}), make(chan struct{})
+			version.Store(0)
+			client := newClient()
+
+			client.Get("/reports/7?lang=en").Do(t).AssertHeader("X-Cache", "MISS").AssertBody("report 7 en v1")
+			<-store.stored
+			store.age.Store(int64(2 * time.Second))
+
+			client.Get("/reports/7?lang=en").Do(t).
+				AssertHeader("X-Cache", "STALE").
+				AssertBody("report 7 en v1")
+			<-entered
+			client.Get("/reports/7?lang=en").Do(t).
+				AssertHeader("X-Cache", "STALE").
+				AssertBody("report 7 en v1")
+			close(release)
+			<-store.stored
+
+			store.age.Store(0)
+			client.Get("/reports/7?lang=en").Do(t).
+				AssertHeader("X-Cache", "HIT").
+				AssertBody("report 7 en v2")
+			assert.Equal(t, int32(2), version.Load(), "one background request refreshes the entry")
+		})
+	}
+}
+
+func TestCache_BypassesPrivateRequestsAndResponses(t *testing.T) {
+	routertest.EachAdapter(t, routertest.Setup{
+		Fiber:      privateCacheRoutes,
+		HTTPRouter: privateCacheRoutes,
+		ServeMux:   privateCacheRoutes,
+	}, func(t *testing.T, client *routertest.Client) {
+		client.Get("/public").Do(t).AssertHeader("X-Cache", "MISS").AssertBody("public #1")
+		res := client.Get("/public").Header("Cookie", "session=a").Do(t).AssertBody("public #2")
+		assert.Empty(t, res.Header.Get("X-Cache"), "requests with cookies bypass the cache")
+		res = client.Get("/public").Header("Authorization", "Bearer token").Do(t).AssertBody("public #3")
+		assert.Empty(t, res.Header.Get("X-Cache"), "authorized requests bypass the cache")
+		client.Get("/public").Do(t).AssertHeader("X-Cache", "HIT").AssertBody("public #1")
+
+		// The client keeps the cookie, which makes later requests bypass the
+		// cache, so /set-cookie goes last.
+		for _, path := range []string{"/private", "/no-store", "/set-cookie"} {
+			client.Get(path).Do(t).AssertHeader("X-Cache", "MISS").AssertBody(path[1:] + " #1")
+			client.Get(path).Do(t).AssertBody(path[1:] + " #2")
+		}
+	})
+}
+
+// privateCacheRoutes serves routes numbering their responses, so a test sees
+// whether the handler ran.
+func privateCacheRoutes[T any](r router.Router[T]) {
+	r.Use(router.Cache(router.CacheConfig{CachePolicy: router.CachePolicy{TTL: time.Minute}}))
+	route := func(path string, header func(c router.Context)) {
+		var calls atomic.Int32
+		r.Get(path, func(c router.Context) error {
+			header(c)
+			return c.SendString(path[1:] + " #" + strconv.Itoa(int(calls.Add(1))))
+		})
+	}
+	route("/public", func(router.Context) {})
+	route("/set-cookie", func(c router.Context) { c.SetHeader("Set-Cookie", "seen=1") })
+	route("/private", func(c router.Context) { c.SetHeader("Cache-Control", "private, max-age=60") })
+	route("/no-store", func(c router.Context) { c.SetHeader("Cache-Control", "no-store") })
+}
+
+func staleRoutes[T any](r router.Router[T], store router.CacheStore, handler router.HandlerFunc) {
+	r.Use(router.Cache(router.CacheConfig{Store: store}))
+	r.Get("/reports/:id", handler)
+}
+
+func cacheRoutes[T any](r router.Router[T], store router.CacheStore, calls *atomic.Int32, release <-chan struct{}) {
+	r.Use(router.Cache(router.CacheConfig{Store: store}))
+	router.SetCachePolicy(r.Get("/users/:id", func(c router.Context) error {
+		n := calls.Add(1)
+		<-release
+		return c.SendString("user " + c.Param("id") + " #" + strconv.Itoa(int(n)))
+	}).SetName("user:read"), router.CachePolicy{
+		TTL:   time.Minute,
+		Query: []string{"fields"},
+		Tags:  []string{"user:{id}"},
+	})
+	router.SetCachePolicy(r.Put("/users/:id", func(c router.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}).SetName("user:update"), router.CachePolicy{Invalidates: []string{"user:{id}"}})
+	router.SetCachePolicy(r.Delete("/users", func(c router.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}).SetName("user:purge"), router.CachePolicy{Invalidates: []string{"user:read"}})
+}
+
+// cacheTestStore reports the lookups and writes of an InMemoryCacheStore and
+// makes its entries look age older, so tests step through the cache without
+// sleeping.
+type cacheTestStore struct {
+	*router.InMemoryCacheStore
+	age     atomic.Int64
+	lookups chan struct{}
+	stored  chan struct{}
+}
+
+func newCacheTestStore() *cacheTestStore {
+	return &cacheTestStore{
+		InMemoryCacheStore: router.NewInMemoryCacheStore(100),
+		lookups:            make(chan struct{}, 100),
+		stored:             make(chan struct{}, 100),
+	}
+}
+
+func (s *cacheTestStore) Get(ctx context.Context, key string) (*router.CacheEntry, error) {
+	entry, err := s.InMemoryCacheStore.Get(ctx, key)
+	notify(s.lookups)
+	if entry == nil || err != nil {
+		return entry, err
+	}
+	age := time.Duration(s.age.Load())
+	aged := *entry
+	aged.StoredAt = entry.StoredAt.Add(-age)
+	aged.Expires = entry.Expires.Add(-age)
+	aged.StaleUntil = entry.StaleUntil.Add(-age)
+	return &aged, nil
+}
+
+func (s *cacheTestStore) Set(ctx context.Context, key string, entry *router.CacheEntry) error {
+	err := s.InMemoryCacheStore.Set(ctx, key, entry)
+	notify(s.stored)
+	return err
+}
+
+func notify(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/goliatone/go-router"
+)
+
+type severity string
+
+const (
+	breaking severity = "breaking"
+	additive severity = "additive"
+	cosmetic severity = "cosmetic"
+)
+
+var severityOrder = []severity{breaking, additive, cosmetic}
+
+type change struct {
+	Severity severity `json:"severity"`
+	Route    string   `json:"route"`
+	Detail   string   `json:"detail"`
+}
+
+type report struct {
+	Breaking int      `json:"breaking"`
+	Additive int      `json:"additive"`
+	Cosmetic int      `json:"cosmetic"`
+	Changes  []change `json:"changes"`
+}
+
+func (r *report) add(s severity, route, format string, args ...any) {
+	r.Changes = append(r.Changes, change{Severity: s, Route: route, Detail: fmt.Sprintf(format, args...)})
+	switch s {
+	case breaking:
+		r.Breaking++
+	case additive:
+		r.Additive++
+	default:
+		r.Cosmetic++
+	}
+}
+
+// compare classifies the changes from before to after. Routes are paired by
+// name first, as router.DiffRouteManifests does, and then by method, host
+// and path shape, so renamed routes and path parameters are cosmetic.
+func compare(before, after *spec) report {
+	r := report{Changes: []change{}}
+	diff := router.DiffRouteManifests(before.routes, after.routes)
+
+	for _, c := range diff.Changed {
+		label := routeLabel(c.Before)
+		switch {
+		case routeKey(c.Before) != routeKey(c.After):
+			r.add(breaking, label, "route %q moved to %s", c.Identity, routeLabel(c.After))
+		case c.Before.Path != c.After.Path:
+			r.add(cosmetic, label, "path parameters renamed: %s", c.After.Path)
+		case c.Before.Version != c.After.Version:
+			r.add(cosmetic, label, "version label changed from %q to %q", c.Before.Version, c.After.Version)
+		}
+	}
+
+	added := make(map[string][]router.RouteManifestEntry)
+	for _, entry := range diff.Added {
+		added[routeKey(entry)] = append(added[routeKey(entry)], entry)
+	}
+	for _, entry := range diff.Removed {
+		key := routeKey(entry)
+		candidates := added[key]
+		if len(candidates) == 0 {
+			r.add(breaking, routeLabel(entry), "route removed")
+			continue
+		}
+		next := candidates[0]
+		added[key] = candidates[1:]
+		if entry.Name != next.Name {
+			r.add(cosmetic, routeLabel(entry), "route renamed from %q to %q", entry.Name, next.Name)
+		}
+		if entry.Path != next.Path {
+			r.add(cosmetic, routeLabel(entry), "path parameters renamed: %s", next.Path)
+		}
+	}
+	for _, entry := range diff.Added {
+		if slices.Contains(added[routeKey(entry)], entry) {
+			r.add(additive, routeLabel(entry), "route added")
+		}
+	}
+
+	if before.operations != nil && after.operations != nil {
+		for _, entry := range after.routes {
+			key := routeKey(entry)
+			if prev, ok := before.operations[key]; ok {
+				compareOperations(&r, routeLabel(entry), prev, after.operations[key])
+			}
+		}
+	}
+
+	slices.SortStableFunc(r.Changes, func(a, b change) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(severityOrder, a.Severity), slices.Index(severityOrder, b.Severity)),
+			cmp.Compare(a.Route, b.Route),
+			cmp.Compare(a.Detail, b.Detail),
+		)
+	})
+	return r
+}
+
+func compareOperations(r *report, route string, before, after *operation) {
+	compareParameters(r, route, before.parameters, after.parameters)
+	compareRequestBodies(r, route, before.RequestBody, after.RequestBody)
+	compareResponses(r, route, before.Responses, after.Responses)
+
+	var docs []string
+	if before.Summary != after.Summary {
+		docs = append(docs, "summary")
+	}
+	if before.Description != after.Description {
+		docs = append(docs, "description")
+	}
+	if !slices.Equal(before.Tags, after.Tags) {
+		docs = append(docs, "tags")
+	}
+	if before.OperationID != after.OperationID {
+		docs = append(docs, "operationId")
+	}
+	if before.Deprecated != after.Deprecated {
+		docs = append(docs, "deprecated")
+	}
+	if len(docs) > 0 {
+		r.add(cosmetic, route, "documentation changed: %s", strings.Join(docs, ", "))
+	}
+}
+
+// compareParameters compares query, header and cookie parameters. Path
+// parameters are part of the route key.
+func compareParameters(r *report, route string, before, after openapi3.Parameters) {
+	index := func(params openapi3.Parameters) map[string]*openapi3.Parameter {
+		out := make(map[string]*openapi3.Parameter)
+		for _, ref := range params {
+			if ref != nil && ref.Value != nil && ref.Value.In != openapi3.ParameterInPath {
+				out[ref.Value.In+" parameter "+ref.Value.Name] = ref.Value
+			}
+		}
+		return out
+	}
+	prev, next := index(before), index(after)
+
+	for _, key := range slices.Sorted(maps.Keys(next)) {
+		param, ok := prev[key]
+		switch {
+		case !ok && next[key].Required:
+			r.add(breaking, route, "required %s added", key)
+		case !ok:
+			r.add(additive, route, "optional %s added", key)
+		case !param.Required && next[key].Required:
+			r.add(breaking, route, "%s made required", key)
+		case param.Required && !next[key].Required:
+			r.add(additive, route, "%s made optional", key)
+		}
+		if ok {
+			compareSchemas(r, route, key, "", schemaOf(param.Schema), schemaOf(next[key].Schema), false, nil)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(prev)) {
+		if _, ok := next[key]; !ok {
+			r.add(breaking, route, "%s removed", key)
+		}
+	}
+}
+
+func compareRequestBodies(r *report, route string, before, after *openapi3.RequestBodyRef) {
+	var prev, next *openapi3.RequestBody
+	if before != nil {
+		prev = before.Value
+	}
+	if after != nil {
+		next = after.Value
+	}
+	switch {
+	case next == nil && prev == nil:
+		return
+	case next == nil:
+		r.add(breaking, route, "request body removed")
+		return
+	case prev == nil && next.Required:
+		r.add(breaking, route, "required request body added")
+		return
+	case prev == nil:
+		r.add(additive, route, "optional request body added")
+		return
+	case !prev.Required && next.Required:
+		r.add(breaking, route, "request body made required")
+	}
+
+	for _, mediaType := range slices.Sorted(maps.Keys(prev.Content)) {
+		nextMedia := next.Content[mediaType]
+		if nextMedia == nil {
+			r.add(breaking, route, "request content type %s removed", mediaType)
+			continue
+		}
+		compareSchemas(r, route, "request body", "", schemaOf(prev.Content[mediaType].Schema), schemaOf(nextMedia.Schema), false, nil)
+	}
+	for _, mediaType := range slices.Sorted(maps.Keys(next.Content)) {
+		if _, ok := prev.Content[mediaType]; !ok {
+			r.add(additive, route, "request content type %s added", mediaType)
+		}
+	}
+}
+
+func compareResponses(r *report, route string, before, after *openapi3.Responses) {
+	prev, next := responseMap(before), responseMap(after)
+	for _, status := range slices.Sorted(maps.Keys(prev)) {
+		nextResponse := next[status]
+		if nextResponse == nil {
+			r.add(breaking, route, "response %s removed", status)
+			continue
+		}
+		for _, mediaType := range slices.Sorted(maps.Keys(prev[status].Content)) {
+			nextMedia := nextResponse.Content[mediaType]
+			if nextMedia == nil {
+				r.add(breaking, route, "response %s content type %s removed", status, mediaType)
+				continue
+			}
+			compareSchemas(r, route, "response "+status, "", schemaOf(prev[status].Content[mediaType].Schema), schemaOf(nextMedia.Schema), true, nil)
+		}
+	}
+	for _, status := range slices.Sorted(maps.Keys(next)) {
+		if _, ok := prev[status]; !ok {
+			r.add(additive, route, "response %s added", status)
+		}
+	}
+}
+
+func responseMap(responses *openapi3.Responses) map[string]*openapi3.Response {
+	out := make(map[string]*openapi3.Response)
+	if responses == nil {
+		return out
+	}
+	for status, ref := range responses.Map() {
+		if ref != nil && ref.Value != nil {
+			out[status] = ref.Value
+		}
+	}
+	return out
+}
+
+func schemaOf(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+// compareSchemas reports how the schema of field in scope changed. Response
+// schemas break clients when they narrow what a client can rely on: a field
+// removed or no longer required. Request schemas break clients when they
+// demand more: a field made required. A changed type breaks both.
+func compareSchemas(r *report, route, scope, field string, before, after *openapi3.Schema, response bool, seen map[[2]*openapi3.Schema]bool) {
+	if before == nil || after == nil {
+		return
+	}
+	if seen == nil {
+		seen = make(map[[2]*openapi3.Schema]bool)
+	}
+	pair := [2]*openapi3.Schema{before, after}
+	if seen[pair] {
+		return
+	}
+	seen[pair] = true
+
+	prevTypes, nextTypes := schemaTypes(before), schemaTypes(after)
+	if len(prevTypes) > 0 && len(nextTypes) > 0 && !slices.Equal(prevTypes, nextTypes) {
+		r.add(breaking, route, "%s type changed from %s to %s", describeField(scope, field), strings.Join(prevTypes, "|"), strings.Join(nextTypes, "|"))
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(before.Properties)) {
+		path := describeField(scope, joinField(field, name))
+		nextProp, ok := after.Properties[name]
+		switch {
+		case !ok:
+			r.add(breaking, route, "%s removed", path)
+			continue
+		case response && slices.Contains(before.Required, name) && !slices.Contains(after.Required, name):
+			r.add(breaking, route, "%s no longer required", path)
+		case !response && !slices.Contains(before.Required, name) && slices.Contains(after.Required, name):
+			r.add(breaking, route, "%s made required", path)
+		}
+		compareSchemas(r, route, scope, joinField(field, name), schemaOf(before.Properties[name]), schemaOf(nextProp), response, seen)
+	}
+	for _, name := range slices.Sorted(maps.Keys(after.Properties)) {
+		if _, ok := before.Properties[name]; ok {
+			continue
+		}
+		path := describeField(scope, joinField(field, name))
+		if !response && slices.Contains(after.Required, name) {
+			r.add(breaking, route, "required %s added", path)
+		} else {
+			r.add(additive, route, "%s added", path)
+		}
+	}
+	compareSchemas(r, route, scope, field+"[]", schemaOf(before.Items), schemaOf(after.Items), response, seen)
+}
+
+func schemaTypes(s *openapi3.Schema) []string {
+	if s.Type == nil {
+		return nil
+	}
+	return slices.Sorted(slices.Values(s.Type.Slice()))
+}
+
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+func describeField(scope, field string) string {
+	if field == "" {
+		return scope
+	}
+	return scope + " field " + field
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/goliatone/go-router"
+)
+
+// spec is a loaded manifest or OpenAPI document.
+type spec struct {
+	routes []router.RouteManifestEntry
+	// operations is set for OpenAPI documents, keyed by routeKey.
+	operations map[string]*operation
+}
+
+type operation struct {
+	*openapi3.Operation
+	// parameters include those declared on the path item.
+	parameters openapi3.Parameters
+}
+
+func loadSpec(path string) (*spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// parseSpec reads a JSON array of manifest entries or an OpenAPI document.
+func parseSpec(data []byte) (*spec, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var routes []router.RouteManifestEntry
+		if err := json.Unmarshal(data, &routes); err != nil {
+			return nil, fmt.Errorf("invalid route manifest: %w", err)
+		}
+		return &spec{routes: routes}, nil
+	}
+
+	var probe struct {
+		OpenAPI string `json:"openapi"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil || probe.OpenAPI == "" {
+		return nil, fmt.Errorf("not a route manifest or OpenAPI document")
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return openAPISpec(doc), nil
+}
+
+func openAPISpec(doc *openapi3.T) *spec {
+	s := &spec{operations: map[string]*operation{}}
+	if doc.Paths == nil {
+		return s
+	}
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			entry := router.RouteManifestEntry{
+				Method: router.HTTPMethod(method),
+				Path:   routePath(path),
+				Name:   operationName(op.OperationID),
+			}
+			s.routes = append(s.routes, entry)
+			params := append(openapi3.Parameters{}, item.Parameters...)
+			s.operations[routeKey(entry)] = &operation{Operation: op, parameters: append(params, op.Parameters...)}
+		}
+	}
+	return s
+}
+
+// routePath turns the "{name}" parameters of an OpenAPI path into router
+// ":name" segments.
+func routePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, "{"); ok && strings.HasSuffix(name, "}") {
+			segments[i] = ":" + strings.TrimSuffix(name, "}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationName returns the route identity of an operation ID. The generator
+// writes "method-name", which leaves a bare "method-" for unnamed routes.
+func operationName(id string) string {
+	if strings.HasSuffix(id, "-") {
+		return ""
+	}
+	return id
+}
+
+// routeKey identifies a route by method, host and path shape, ignoring the
+// names and constraints of path parameters.
+func routeKey(entry router.RouteManifestEntry) string {
+	segments := strings.Split(entry.Path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":") && strings.HasSuffix(segment, "?"):
+			segments[i] = ":?"
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = ":"
+		case strings.HasPrefix(segment, "*"):
+			segments[i] = "*"
+		}
+	}
+	return string(entry.Method) + " " + entry.Host + strings.Join(segments, "/")
+}
+
+func routeLabel(entry router.RouteManifestEntry) string {
+	return string(entry.Method) + " " + entry.Host + entry.Path
+}
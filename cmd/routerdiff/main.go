@@ -0,0 +1,73 @@
+// Command routerdiff compares two route manifests or OpenAPI documents and
+// classifies every change as breaking, additive or cosmetic:
+//
+//	routerdiff [-format text|json|markdown] before.json after.json
+//
+// Manifests are the JSON encoding of router.BuildRouterManifest. OpenAPI
+// documents, such as the one router.OpenAPIRenderer generates, are also
+// compared operation by operation: parameters, request bodies and response
+// schemas.
+//
+// The exit status is 1 when a change is breaking, 2 when the files cannot be
+// read, and 0 otherwise, so the command can gate a deploy pipeline.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK       = 0
+	exitBreaking = 1
+	exitError    = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("routerdiff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "text", "output format: text, json or markdown")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: routerdiff [-format text|json|markdown] before.json after.json")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitError
+	}
+	write, ok := reportWriters[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "routerdiff: unknown format %q\n", *format)
+		return exitError
+	}
+
+	before, err := loadSpec(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "routerdiff: %v\n", err)
+		return exitError
+	}
+	after, err := loadSpec(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "routerdiff: %v\n", err)
+		return exitError
+	}
+
+	report := compare(before, after)
+	if err := write(stdout, report); err != nil {
+		fmt.Fprintf(stderr, "routerdiff: %v\n", err)
+		return exitError
+	}
+	if report.Breaking > 0 {
+		return exitBreaking
+	}
+	return exitOK
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/julienschmidt/httprouter"
+)
+
+func writeFile(t *testing.T, name string, v any) string {
+	t.Helper()
+	data, ok := v.(string)
+	if !ok {
+		encoded, err := json.Marshal(v)
+		require.NoError(t, err)
+		data = string(encoded)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func manifest(register func(r router.Router[*httprouter.Router])) []router.RouteManifestEntry {
+	app := router.NewHTTPServer()
+	register(app.Router())
+	return router.BuildRouterManifest(app.Router())
+}
+
+func noop(c router.Context) error { return nil }
+
+func TestRun_ClassifiesManifestChanges(t *testing.T) {
+	before := writeFile(t, "before.json", manifest(func(r router.Router[*httprouter.Router]) {
+		r.Get("/users/:id", noop).SetName("users.show")
+		r.Get("/teams/:id", noop).SetName("teams.show")
+		r.Get("/legacy", noop)
+		r.Get("/posts/:id", noop).SetName("posts.show")
+	}))
+	after := writeFile(t, "after.json", manifest(func(r router.Router[*httprouter.Router]) {
+		r.Get("/users/:user_id", noop).SetName("users.show")
+		r.Get("/orgs/:id/teams", noop).SetName("teams.show")
+		r.Get("/posts/:id", noop).SetName("posts.read")
+		r.Post("/posts", noop)
+	}))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-format", "json", before, after}, &stdout, &stderr)
+	assert.Equal(t, exitBreaking, code, stderr.String())
+
+	var got report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	assert.Equal(t, []change{
+		{Severity: breaking, Route: "GET /legacy", Detail: "route removed"},
+		{Severity: breaking, Route: "GET /teams/:id", Detail: `route "teams.show" moved to GET /orgs/:id/teams`},
+		{Severity: additive, Route: "POST /posts", Detail: "route added"},
+		{Severity: cosmetic, Route: "GET /posts/:id", Detail: `route renamed from "posts.show" to "posts.read"`},
+		{Severity: cosmetic, Route: "GET /users/:id", Detail: "path parameters renamed: /users/:user_id"},
+	}, got.Changes)
+	assert.Equal(t, 2, got.Breaking)
+
+	stdout.Reset()
+	code = run([]string{before, before}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "routerdiff: 0 breaking, 0 additive, 0 cosmetic\n", stdout.String())
+}
+
+const openAPIBefore = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Users", "version": "1.0.0"},
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "get-users.show",
+        "summary": "Get a user",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "fields", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+          "404": {"description": "missing"}
+        }
+      },
+      "patch": {
+        "operationId": "patch-users.update",
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"name": {"type": "string"}, "email": {"type": "string"}}
+        }}}},
+        "responses": {"204": {"description": "updated"}}
+      }
+    }
+  },
+  "components": {"schemas": {"User": {
+    "type": "object",
+    "required": ["id", "email"],
+    "properties": {
+      "id": {"type": "string"},
+      "email": {"type": "string"},
+      "age": {"type": "integer"},
+      "tags": {"type": "array", "items": {"type": "object", "properties": {"label": {"type": "string"}}}}
+    }
+  }}}
+}`
+
+const openAPIAfter = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Users", "version": "2.0.0"},
+  "paths": {
+    "/users/{user_id}": {
+      "get": {
+        "operationId": "get-users.show",
+        "summary": "Fetch a user",
+        "parameters": [
+          {"name": "user_id", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "fields", "in": "query", "required": true, "schema": {"type": "string"}},
+          {"name": "X-Trace", "in": "header", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "ok", "content": {"application/json": {"schema": {
+            "type": "object",
+            "required": ["id"],
+            "properties": {
+              "id": {"type": "string"},
+              "email": {"type": "string"},
+              "age": {"type": "string"},
+              "tags": {"type": "array", "items": {"type": "object", "properties": {}}},
+              "avatar": {"type": "string"}
+            }
+          }}}}
+        }
+      },
+      "patch": {
+        "operationId": "patch-users.update",
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "required": ["email"],
+          "properties": {"name": {"type": "string"}, "email": {"type": "string"}}
+        }}}},
+        "responses": {"204": {"description": "updated"}}
+      }
+    }
+  }
+}`
+
+func TestRun_ClassifiesOpenAPIChanges(t *testing.T) {
+	before := writeFile(t, "before.json", openAPIBefore)
+	after := writeFile(t, "after.json", openAPIAfter)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-format", "json", before, after}, &stdout, &stderr)
+	require.Equal(t, exitBreaking, code, stderr.String())
+
+	var got report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	details := map[string][]string{}
+	for _, c := range got.Changes {
+		details[string(c.Severity)] = append(details[string(c.Severity)], c.Route+": "+c.Detail)
+	}
+	assert.Equal(t, []string{
+		"GET /users/:user_id: query parameter fields made required",
+		"GET /users/:user_id: response 200 field age type changed from integer to string",
+		"GET /users/:user_id: response 200 field email no longer required",
+		"GET /users/:user_id: response 200 field tags[].label removed",
+		"GET /users/:user_id: response 404 removed",
+		"PATCH /users/:user_id: request body field email made required",
+	}, details["breaking"])
+	assert.Equal(t, []string{
+		"GET /users/:user_id: optional header parameter X-Trace added",
+		"GET /users/:user_id: response 200 field avatar added",
+	}, details["additive"])
+	assert.Equal(t, []string{
+		"GET /users/:id: path parameters renamed: /users/:user_id",
+		"GET /users/:user_id: documentation changed: summary",
+		"PATCH /users/:id: path parameters renamed: /users/:user_id",
+	}, details["cosmetic"])
+}
+
+func TestRun_MarkdownAndErrors(t *testing.T) {
+	before := writeFile(t, "before.json", []router.RouteManifestEntry{{Method: router.GET, Path: "/a|b"}})
+	after := writeFile(t, "after.json", []router.RouteManifestEntry{})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-format", "markdown", before, after}, &stdout, &stderr)
+	assert.Equal(t, exitBreaking, code)
+	assert.Equal(t, "## Route changes\n\n1 breaking, 0 additive, 0 cosmetic.\n\n"+
+		"### Breaking\n\n| Route | Change |\n| --- | --- |\n| `GET /a\\|b` | route removed |\n", stdout.String())
+
+	assert.Equal(t, exitError, run([]string{"-format", "xml", before, after}, &stdout, &stderr))
+	assert.Equal(t, exitError, run([]string{before}, &stdout, &stderr))
+	assert.Equal(t, exitError, run([]string{before, writeFile(t, "bad.json", `{"routes": []}`)}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "not a route manifest or OpenAPI document")
+}
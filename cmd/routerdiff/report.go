@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var reportWriters = map[string]func(io.Writer, report) error{
+	"text":     writeText,
+	"json":     writeJSON,
+	"markdown": writeMarkdown,
+}
+
+func (r report) summary() string {
+	return fmt.Sprintf("%d breaking, %d additive, %d cosmetic", r.Breaking, r.Additive, r.Cosmetic)
+}
+
+func (r report) bySeverity(s severity) []change {
+	var out []change
+	for _, c := range r.Changes {
+		if c.Severity == s {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func writeText(w io.Writer, r report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "routerdiff: %s\n", r.summary())
+	for _, s := range severityOrder {
+		changes := r.bySeverity(s)
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", strings.ToUpper(string(s)))
+		for _, c := range changes {
+			fmt.Fprintf(&b, "  %s: %s\n", c.Route, c.Detail)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeJSON(w io.Writer, r report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func writeMarkdown(w io.Writer, r report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Route changes\n\n%s.\n", r.summary())
+	for _, s := range severityOrder {
+		changes := r.bySeverity(s)
+		if len(changes) == 0 {
+			continue
+		}
+		title := strings.ToUpper(string(s[:1])) + string(s[1:])
+		fmt.Fprintf(&b, "\n### %s\n\n| Route | Change |\n| --- | --- |\n", title)
+		for _, c := range changes {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", escapeCell(c.Route), escapeCell(c.Detail))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeCell escapes the pipes that would end a Markdown table cell.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,340 @@
+package router
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings supported by Compress.
+const (
+	EncodingZstd   = "zstd"
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// staticEncodingExtensions are the file extensions of precompressed static
+// files, served when Static.Compress is set.
+var staticEncodingExtensions = map[string]string{
+	EncodingZstd:   ".zst",
+	EncodingBrotli: ".br",
+	EncodingGzip:   ".gz",
+}
+
+// CompressConfig configures the Compress middleware.
+type CompressConfig struct {
+	Skip func(Context) bool
+	// Encodings lists the codings to offer, in the order preferred when a
+	// client accepts several equally. Defaults to zstd, br and gzip.
+	Encodings []string
+	// MinSize is the smallest body compressed, in bytes. Defaults to 1024.
+	// Flushed responses are compressed whatever their size.
+	MinSize int
+	// ContentTypes lists the media types compressed, such as
+	// "application/json", or "text/*" for a whole type. Defaults to text,
+	// JSON, XML, JavaScript, SVG and WebAssembly types; event streams are left
+	// out so they reach clients as written.
+	ContentTypes []string
+}
+
+// CompressConfigDefault is the default configuration of Compress.
+var CompressConfigDefault = CompressConfig{
+	Encodings: []string{EncodingZstd, EncodingBrotli, EncodingGzip},
+	MinSize:   1024,
+	ContentTypes: []string{
+		"text/html", "text/css", "text/plain", "text/javascript", "text/csv", "text/xml", "text/markdown",
+		"application/json", "application/problem+json", "application/ld+json", "application/manifest+json",
+		"application/javascript", "application/xml", "application/rss+xml", "application/atom+xml",
+		"application/wasm", "image/svg+xml",
+	},
+}
+
+func compressConfigDefault(config ...CompressConfig) CompressConfig {
+	if len(config) == 0 {
+		return CompressConfigDefault
+	}
+	cfg := config[0]
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = CompressConfigDefault.Encodings
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = CompressConfigDefault.MinSize
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = CompressConfigDefault.ContentTypes
+	}
+	return cfg
+}
+
+// Compress returns a middleware that compresses the responses of the
+// httprouter and ServeMux adapters with the coding negotiated through
+// Accept-Encoding. Fiber contexts pass through; use Fiber's compress
+// middleware there:
+//
+//	app.Router().Use(router.Compress(router.CompressConfig{MinSize: 512}))
+//
+// Responses that already carry a Content-Encoding, partial content, range
+// requests and upgrades are left alone. Streamed responses stay streamed:
+// each flush emits what was compressed so far.
+func Compress(config ...CompressConfig) MiddlewareFunc {
+	cfg := compressConfigDefault(config...)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			ctx, ok := c.(*httpRouterContext)
+			if !ok || (cfg.Skip != nil && cfg.Skip(c)) || c.Method() == http.MethodHead ||
+				c.Header("Range") != "" || c.Header("Upgrade") != "" {
+				return next(c)
+			}
+
+			w := &compressWriter{
+				ResponseWriter: ctx.w,
+				config:         &cfg,
+				encoding:       negotiateEncoding(c.Header("Accept-Encoding"), cfg.Encodings),
+			}
+			ctx.w = w
+			defer func() { ctx.w = w.ResponseWriter }()
+			err := next(c)
+			return errors.Join(err, w.close())
+		}
+	}
+}
+
+// compressEncoder is implemented by the gzip, brotli and zstd writers.
+type compressEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+var compressEncoders = map[string]*sync.Pool{
+	EncodingGzip: {New: func() any { return gzip.NewWriter(nil) }},
+	EncodingBrotli: {New: func() any {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+	EncodingZstd: {New: func() any {
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(err)
+		}
+		return encoder
+	}},
+}
+
+// compressWriter holds back the start of a response until MinSize bytes or a
+// flush show whether compressing it pays off.
+type compressWriter struct {
+	http.ResponseWriter
+	config   *CompressConfig
+	encoding string
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder compressEncoder
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if status >= 100 && status < 200 {
+		// Informational responses, such as 103 Early Hints, go out as sent.
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.config.MinSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// FlushError commits the response and flushes it, see
+// http.ResponseController.
+func (w *compressWriter) FlushError() error {
+	if !w.decided {
+		if err := w.start(true); err != nil {
+			return err
+		}
+	}
+	if w.encoder != nil {
+		if err := w.encoder.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Flush() {
+	_ = w.FlushError()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the header, compressing the response when it is eligible and,
+// unless more is known to follow, at least MinSize bytes long.
+func (w *compressWriter) start(more bool) error {
+	w.decided = true
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if w.compressible(status, header) {
+		if missing := missingVary(header.Values("Vary"), "Accept-Encoding"); len(missing) > 0 {
+			header.Add("Vary", strings.Join(missing, ", "))
+		}
+		if w.encoding != "" && (more || len(w.buf) >= w.config.MinSize) {
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			// The compressed body is another representation.
+			if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				header.Set("ETag", "W/"+etag)
+			}
+			w.encoder = compressEncoders[w.encoding].Get().(compressEncoder)
+			w.encoder.Reset(w.ResponseWriter)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func (w *compressWriter) compressible(status int, header http.Header) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusPartialContent ||
+		status == http.StatusNotModified || header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < w.config.MinSize {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	return slices.ContainsFunc(w.config.ContentTypes, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		return allowed == mediaType || allowed == major+"/*"
+	})
+}
+
+// close finishes the response: a body shorter than MinSize is sent as is, and
+// the encoder is flushed and returned to its pool.
+func (w *compressWriter) close() error {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			return nil
+		}
+		if err := w.start(false); err != nil {
+			return err
+		}
+	}
+	if w.encoder == nil {
+		return nil
+	}
+	err := w.encoder.Close()
+	w.encoder.Reset(nil)
+	compressEncoders[w.encoding].Put(w.encoder)
+	w.encoder = nil
+	return err
+}
+
+// acceptedEncodings returns the offers Accept-Encoding accepts, the ones the
+// client weighs highest first and offers in their order otherwise.
+func acceptedEncodings(acceptEncoding string, offers []string) []string {
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for part := range strings.SplitSeq(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding == "*" {
+			wildcard = q
+			continue
+		}
+		weights[coding] = q
+	}
+
+	var accepted []string
+	for _, offer := range offers {
+		q, ok := weights[offer]
+		if !ok {
+			q = wildcard
+		}
+		if q > 0 {
+			accepted = append(accepted, offer)
+		}
+	}
+	slices.SortStableFunc(accepted, func(a, b string) int {
+		qa, qb := weights[a], weights[b]
+		if _, ok := weights[a]; !ok {
+			qa = wildcard
+		}
+		if _, ok := weights[b]; !ok {
+			qb = wildcard
+		}
+		switch {
+		case qa > qb:
+			return -1
+		case qa < qb:
+			return 1
+		}
+		return 0
+	})
+	return accepted
+}
+
+// negotiateEncoding returns the coding to compress with, or "" to send the
+// response as is.
+func negotiateEncoding(acceptEncoding string, offers []string) string {
+	if accepted := acceptedEncodings(acceptEncoding, offers); len(accepted) > 0 {
+		return accepted[0]
+	}
+	return ""
+}
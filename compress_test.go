@@ -0,0 +1,173 @@
+package router_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+var compressPayload = strings.Repeat(`{"name":"compressible"}`, 100)
+
+func TestCompress_NegotiatesAndSkips(t *testing.T) {
+	decoders := map[string]func(io.Reader) io.Reader{
+		"gzip": func(r io.Reader) io.Reader {
+			gz, err := gzip.NewReader(r)
+			require.NoError(t, err)
+			return gz
+		},
+		"br": func(r io.Reader) io.Reader { return brotli.NewReader(r) },
+		"zstd": func(r io.Reader) io.Reader {
+			zr, err := zstd.NewReader(r)
+			require.NoError(t, err)
+			return zr
+		},
+	}
+	setup := routertest.Setup{HTTPRouter: compressRoutes, ServeMux: compressRoutes}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		for accept, encoding := range map[string]string{
+			"gzip":              "gzip",
+			"gzip;q=0.5, br":    "br",
+			"gzip, br, zstd":    "zstd",
+			"*;q=0.1, gzip;q=0": "zstd",
+		} {
+			res := client.Get("/json").Header("Accept-Encoding", accept).Do(t).
+				AssertHeader("Content-Encoding", encoding).
+				AssertHeader("Vary", "Accept-Encoding").
+				AssertHeader("ETag", `W/"v1"`)
+			decoded, err := io.ReadAll(decoders[encoding](bytes.NewReader(res.Body)))
+			require.NoError(t, err)
+			assert.Equal(t, compressPayload, string(decoded))
+		}
+
+		res := client.Get("/json").Do(t).
+			AssertHeader("Vary", "Accept-Encoding").
+			AssertBody(compressPayload)
+		assert.Empty(t, res.Header.Get("Content-Encoding"))
+
+		res = client.Get("/small").Header("Accept-Encoding", "gzip").Do(t).AssertBody(`{"ok":true}`)
+		assert.Empty(t, res.Header.Get("Content-Encoding"), "bodies under MinSize are sent as is")
+
+		res = client.Get("/file").Header("Accept-Encoding", "gzip").Header("Range", "bytes=0-9").Do(t).
+			AssertStatus(http.StatusPartialContent).
+			AssertBody(compressPayload[:10])
+		assert.Empty(t, res.Header.Get("Content-Encoding"))
+		res = client.Get("/file").Header("Accept-Encoding", "gzip").Do(t).AssertHeader("Content-Encoding", "gzip")
+		assert.NotEqual(t, strconv.Itoa(len(compressPayload)), res.Header.Get("Content-Length"))
+	})
+
+	fiberApp := router.NewFiberAdapter()
+	compressRoutes(fiberApp.Router())
+	res := routertest.NewClient(fiberApp).Get("/json").Header("Accept-Encoding", "gzip").Do(t).AssertBody(compressPayload)
+	assert.Empty(t, res.Header.Get("Content-Encoding"), "Fiber contexts pass through")
+}
+
+// TestCompress_FlushesCompressedStreams reads streams while they are open, so
+// it needs a real connection.
+func TestCompress_FlushesCompressedStreams(t *testing.T) {
+	release := make(chan struct{})
+	app := router.NewServeMuxServer()
+	app.Router().Use(router.Compress())
+	app.Router().Get("/ndjson", func(c router.Context) error {
+		reader, writer := io.Pipe()
+		go func() {
+			_, _ = io.WriteString(writer, "{\"n\":1}\n")
+			<-release
+			_ = writer.Close()
+		}()
+		c.SetHeader("Content-Type", "text/plain")
+		return c.SendStream(reader)
+	})
+	app.Router().Get("/events", streamEvents(release))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.(router.ListenerServer).ServeListener(ln)
+	defer app.Shutdown(context.Background())
+	defer close(release)
+	base := "http://" + ln.Addr().String()
+
+	res := compressStream(t, base+"/ndjson")
+	require.Equal(t, "gzip", res.Header.Get("Content-Encoding"), "flushed responses are compressed whatever their size")
+	gz, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+	line, err := bufio.NewReader(gz).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":1}\n", line)
+
+	res = compressStream(t, base+"/events")
+	assert.Empty(t, res.Header.Get("Content-Encoding"), "event streams are not compressed")
+	line, err = bufio.NewReader(res.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: first\n", line, "the first event arrives while the stream is open")
+}
+
+func TestStatic_ServesPrecompressedSiblings(t *testing.T) {
+	setup := routertest.Setup{Fiber: precompressedRoutes, HTTPRouter: precompressedRoutes, ServeMux: precompressedRoutes}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		for accept, want := range map[string]string{"gzip, br": "brotli", "gzip": "gzip", "": "plain", "zstd": "plain"} {
+			client.Get("/assets/app.js").Header("Accept-Encoding", accept).Do(t).
+				AssertBody(want).
+				AssertHeaderContains("Content-Type", "javascript").
+				AssertHeader("Vary", "Accept-Encoding")
+		}
+
+		client.Get("/assets/docs/").Header("Accept-Encoding", "br").Do(t).
+			AssertBody("brotli index").
+			AssertHeader("Content-Encoding", "br").
+			AssertHeaderContains("Content-Type", "text/html")
+		client.Get("/assets/docs/").Do(t).AssertBody("plain index")
+	})
+}
+
+// precompressedRoutes serves testdata/precompressed, whose .br and .gz
+// siblings hold their encoding name rather than compressed bytes.
+func precompressedRoutes[T any](r router.Router[T]) {
+	r.Static("/assets", "testdata/precompressed", router.Static{Compress: true})
+}
+
+func compressRoutes[T any](r router.Router[T]) {
+	r.Use(router.Compress())
+	r.Get("/json", func(c router.Context) error {
+		c.SetHeader("Content-Type", "application/json")
+		c.SetHeader("ETag", `"v1"`)
+		return c.SendString(compressPayload)
+	})
+	r.Get("/small", func(c router.Context) error {
+		c.SetHeader("Content-Type", "application/json")
+		return c.SendString(`{"ok":true}`)
+	})
+	r.Get("/file", router.HandlerFromHTTP(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		http.ServeContent(w, req, "file.txt", time.Time{}, strings.NewReader(compressPayload))
+	})))
+}
+
+// compressStream opens a gzip accepting request to url, leaving decoding to
+// the test. The body is closed with the test.
+func compressStream(t *testing.T, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{DisableCompression: true}}
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
@@ -0,0 +1,274 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Validators identify the current representation of a resource, see RFC 9110
+// section 8.8. ETag is an entity tag such as `"v42"` or `W/"v42"`; a bare
+// value such as a version is quoted. LastModified is truncated to seconds.
+type Validators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// ConditionalPolicy sets how the Conditional middleware validates requests.
+type ConditionalPolicy struct {
+	// Weak generates weak ETags, for responses whose bytes may change while
+	// meaning the same, such as JSON with unordered maps.
+	Weak bool
+	// Current returns the validators of the resource a request targets, such
+	// as its version and update time, before the handler runs. Conditional
+	// GETs then skip the handler, and writes are checked against If-Match and
+	// If-Unmodified-Since. It returns zero Validators for missing resources.
+	Current func(Context) (Validators, error)
+	// RequireIfMatch rejects writes without If-Match or If-Unmodified-Since
+	// with 428 Precondition Required, so clients cannot overwrite changes
+	// they have not seen. It needs Current, since writes carrying those
+	// headers are rejected without it.
+	RequireIfMatch bool
+}
+
+// ConditionalConfig configures the Conditional middleware.
+type ConditionalConfig struct {
+	// ConditionalPolicy applies to routes without a policy of their own, see
+	// SetConditional.
+	ConditionalPolicy
+	Skip func(Context) bool
+	// MaxBodySize is the largest response an ETag is generated for, in bytes.
+	// Defaults to DefaultMaxCapturedBodySize. Larger and streamed responses
+	// need Current or handler-supplied validators.
+	MaxBodySize int64
+}
+
+// SetConditional sets the conditional request policy of a single route, in
+// place of the policy of the Conditional middleware:
+//
+//	router.SetConditional(r.Put("/articles/:id", update), router.ConditionalPolicy{
+//		Current: func(c router.Context) (router.Validators, error) {
+//			article, err := articles.Find(c.Context(), c.Param("id"))
+//			if err != nil {
+//				return router.Validators{}, err
+//			}
+//			return router.Validators{ETag: article.Version, LastModified: article.UpdatedAt}, nil
+//		},
+//		RequireIfMatch: true,
+//	})
+func SetConditional(ri RouteInfo, policy ConditionalPolicy) RouteInfo {
+	if route, ok := ri.(*RouteDefinition); ok {
+		route.Conditional = &policy
+	}
+	return ri
+}
+
+// SetETag sets the ETag header of the response to value, quoted unless it is
+// already an entity tag.
+func SetETag(c Context, value string, weak bool) {
+	c.SetHeader("ETag", FormatETag(value, weak))
+}
+
+// SetLastModified sets the Last-Modified header of the response.
+func SetLastModified(c Context, t time.Time) {
+	c.SetHeader("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// FormatETag returns value as an entity tag, quoted unless it already is one.
+func FormatETag(value string, weak bool) string {
+	if strings.HasPrefix(value, `W/"`) {
+		return value
+	}
+	if !strings.HasPrefix(value, `"`) {
+		value = `"` + value + `"`
+	}
+	if weak {
+		return "W/" + value
+	}
+	return value
+}
+
+// Conditional returns a middleware that answers conditional requests, on every
+// adapter:
+//
+//	app.Router().Use(router.Conditional())
+//
+// GET and HEAD responses with status 200 get an ETag computed from their body
+// unless the handler set one, with SetETag or SetLastModified, and requests
+// whose If-None-Match or If-Modified-Since match get 304 Not Modified. Routes
+// whose policy sets Current are validated before the handler runs: writes
+// that fail If-Match, If-Unmodified-Since or If-None-Match get 412
+// Precondition Failed. Without Current the middleware cannot evaluate the
+// preconditions of a write, so writes carrying If-Match or
+// If-Unmodified-Since get 412 rather than running unchecked. Skip routes whose
+// handlers evaluate them.
+func Conditional(config ...ConditionalConfig) MiddlewareFunc {
+	var cfg ConditionalConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			policy := &cfg.ConditionalPolicy
+			if route, ok := routeFromContext(c.Context()); ok && route.Conditional != nil {
+				policy = route.Conditional
+			}
+			safe := c.Method() == http.MethodGet || c.Method() == http.MethodHead
+
+			if !safe && policy.RequireIfMatch && c.Header("If-Match") == "" && c.Header("If-Unmodified-Since") == "" {
+				return NewPreconditionRequiredError("If-Match header is required")
+			}
+			if policy.Current != nil {
+				validators, err := policy.Current(c)
+				if err != nil {
+					return err
+				}
+				if validators.ETag != "" {
+					validators.ETag = FormatETag(validators.ETag, policy.Weak)
+				}
+				switch evaluatePreconditions(c, validators) {
+				case http.StatusNotModified:
+					return notModified(c, validators)
+				case http.StatusPreconditionFailed:
+					return NewPreconditionFailedError("precondition failed", map[string]any{"etag": validators.ETag})
+				}
+				if safe {
+					writeValidators(c, validators)
+				}
+				return next(c)
+			}
+			if !safe {
+				if c.Header("If-Match") != "" || c.Header("If-Unmodified-Since") != "" {
+					return NewPreconditionFailedError("precondition cannot be evaluated without current validators")
+				}
+				return next(c)
+			}
+
+			captured, err := CaptureResponse(c, cfg.MaxBodySize, next)
+			if err != nil {
+				return err
+			}
+			if captured.StatusCode != http.StatusOK {
+				return ReplayCapturedResponse(c, captured)
+			}
+			if captured.Headers.Get("ETag") == "" && len(captured.Body) > 0 {
+				hash := sha256.Sum256(captured.Body)
+				captured.Headers.Set("ETag", FormatETag(hex.EncodeToString(hash[:16]), policy.Weak))
+			}
+			validators := Validators{ETag: captured.Headers.Get("ETag")}
+			if lastModified, err := http.ParseTime(captured.Headers.Get("Last-Modified")); err == nil {
+				validators.LastModified = lastModified
+			}
+			switch evaluatePreconditions(c, validators) {
+			case http.StatusNotModified:
+				return notModified(c, validators, captured.Headers)
+			case http.StatusPreconditionFailed:
+				return NewPreconditionFailedError("precondition failed", map[string]any{"etag": validators.ETag})
+			}
+			return ReplayCapturedResponse(c, captured)
+		}
+	}
+}
+
+// evaluatePreconditions returns 304 or 412 when the conditional headers of c
+// fail against validators, in the order of RFC 9110 section 13.2.2, and 0
+// otherwise.
+func evaluatePreconditions(c Context, validators Validators) int {
+	safe := c.Method() == http.MethodGet || c.Method() == http.MethodHead
+	lastModified := validators.LastModified.Truncate(time.Second)
+
+	if ifMatch := c.Header("If-Match"); ifMatch != "" {
+		if !matchETag(ifMatch, validators.ETag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(c.Header("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if ifNoneMatch := c.Header("If-None-Match"); ifNoneMatch != "" {
+		if matchETag(ifNoneMatch, validators.ETag, true) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(c.Header("If-Modified-Since")); err == nil && safe && !lastModified.IsZero() {
+		if !lastModified.After(since) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// contentETag returns an entity tag for content: prefix followed by a hash of
+// content.
+func contentETag(prefix string, content []byte, weak bool) string {
+	hash := sha256.Sum256(content)
+	return FormatETag(prefix+hex.EncodeToString(hash[:16]), weak)
+}
+
+// matchesIfNoneMatch reports whether the If-None-Match header of c matches
+// etag.
+func matchesIfNoneMatch(c Context, etag string) bool {
+	return matchETag(c.Header("If-None-Match"), etag, true)
+}
+
+// matchETag reports whether the entity tag list of a header matches etag,
+// with the weak comparison of If-None-Match or the strong one of If-Match.
+// "*" matches any existing resource.
+func matchETag(header, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if !weak && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		} else if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeValidators(c Context, validators Validators) {
+	if validators.ETag != "" {
+		c.SetHeader("ETag", validators.ETag)
+	}
+	if !validators.LastModified.IsZero() {
+		SetLastModified(c, validators.LastModified)
+	}
+}
+
+// notModifiedHeaders are the headers a 304 response repeats from the 200 one,
+// see RFC 9110 section 15.4.5.
+var notModifiedHeaders = []string{"Cache-Control", "Content-Location", "Date", "Expires", "Vary"}
+
+func notModified(c Context, validators Validators, headers ...http.Header) error {
+	response := &CapturedResponse{StatusCode: http.StatusNotModified, Headers: make(http.Header)}
+	for _, header := range headers {
+		for _, name := range notModifiedHeaders {
+			if values := header.Values(name); len(values) > 0 {
+				response.Headers[name] = values
+			}
+		}
+	}
+	writeValidators(c, validators)
+	return ReplayCapturedResponse(c, response)
+}
@@ -0,0 +1,108 @@
+package router_test
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func TestConditional_ValidatesReadsAndWrites(t *testing.T) {
+	clients := map[string]func(*conditionalArticle) *routertest.Client{
+		"fiber": func(article *conditionalArticle) *routertest.Client {
+			app := newFiberAPIAdapter()
+			conditionalRoutes(app.Router(), article)
+			return routertest.NewClient(app)
+		},
+		"httprouter": func(article *conditionalArticle) *routertest.Client {
+			app := router.NewHTTPServer()
+			conditionalRoutes(app.Router(), article)
+			return routertest.NewClient(app)
+		},
+		"servemux": func(article *conditionalArticle) *routertest.Client {
+			app := router.NewServeMuxServer()
+			conditionalRoutes(app.Router(), article)
+			return routertest.NewClient(app)
+		},
+	}
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			article := &conditionalArticle{}
+			article.version.Store(1)
+			client := newClient(article)
+
+			res := client.Get("/feed").Do(t).AssertStatus(http.StatusOK).AssertBody("feed")
+			etag := res.Header.Get("ETag")
+			require.NotEmpty(t, etag, "ETags are computed from the body")
+			for _, match := range []string{etag, "W/" + etag, `"other", ` + etag} {
+				client.Get("/feed").Header("If-None-Match", match).Do(t).
+					AssertStatus(http.StatusNotModified).
+					AssertBody("").
+					AssertHeader("ETag", etag).
+					AssertHeader("Cache-Control", "max-age=60")
+			}
+			client.Get("/feed").Header("If-Modified-Since", conditionalModified.Format(http.TimeFormat)).Do(t).
+				AssertStatus(http.StatusNotModified)
+			client.Get("/feed").Header("If-Modified-Since", conditionalModified.Add(-time.Hour).Format(http.TimeFormat)).Do(t).
+				AssertStatus(http.StatusOK)
+
+			client.Get("/articles/1").Do(t).AssertHeader("ETag", `"1"`)
+			reads := article.reads.Load()
+			client.Get("/articles/1").Header("If-None-Match", `"1"`).Do(t).AssertStatus(http.StatusNotModified)
+			assert.Equal(t, reads, article.reads.Load(), "current validators skip the handler")
+
+			client.Put("/articles/1").Do(t).AssertStatus(http.StatusPreconditionRequired)
+			client.Put("/articles/1").Header("If-Match", `"7"`).Do(t).AssertStatus(http.StatusPreconditionFailed)
+			client.Put("/articles/1").Header("If-Match", `"1"`).Do(t).AssertStatus(http.StatusNoContent)
+			client.Put("/articles/1").Header("If-Match", `"1"`).Do(t).AssertStatus(http.StatusPreconditionFailed)
+			client.Put("/articles/1").Header("If-Match", "*").Do(t).AssertStatus(http.StatusNoContent)
+
+			client.Put("/notes/1").Do(t).AssertStatus(http.StatusNoContent)
+			client.Put("/notes/1").Header("If-Match", `"1"`).Do(t).AssertStatus(http.StatusPreconditionFailed)
+			client.Put("/notes/1").Header("If-Unmodified-Since", conditionalModified.Format(http.TimeFormat)).Do(t).
+				AssertStatus(http.StatusPreconditionFailed)
+			assert.Equal(t, int32(1), article.notes.Load(), "writes without current validators skip unchecked preconditions")
+		})
+	}
+}
+
+var conditionalModified = time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+type conditionalArticle struct {
+	version atomic.Int32
+	reads   atomic.Int32
+	notes   atomic.Int32
+}
+
+func (a *conditionalArticle) current(router.Context) (router.Validators, error) {
+	return router.Validators{ETag: strconv.Itoa(int(a.version.Load()))}, nil
+}
+
+func conditionalRoutes[T any](r router.Router[T], article *conditionalArticle) {
+	r.Use(router.Conditional())
+	r.Get("/feed", func(c router.Context) error {
+		c.SetHeader("Cache-Control", "max-age=60")
+		router.SetLastModified(c, conditionalModified)
+		return c.SendString("feed")
+	})
+	router.SetConditional(r.Get("/articles/:id", func(c router.Context) error {
+		article.reads.Add(1)
+		return c.JSON(http.StatusOK, map[string]any{"version": article.version.Load()})
+	}), router.ConditionalPolicy{Current: article.current})
+	router.SetConditional(r.Put("/articles/:id", func(c router.Context) error {
+		article.version.Add(1)
+		return c.NoContent(http.StatusNoContent)
+	}), router.ConditionalPolicy{Current: article.current, RequireIfMatch: true})
+	r.Put("/notes/:id", func(c router.Context) error {
+		article.notes.Add(1)
+		return c.NoContent(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,253 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy describes which cross-origin requests a route accepts.
+type CORSPolicy struct {
+	// AllowOrigins lists the origins allowed to read responses. Entries are
+	// origins or host patterns, such as "https://app.example.com",
+	// "*.example.com" or "https://*.example.com:8443", and "*" allows any
+	// origin. An empty list allows no cross-origin request.
+	AllowOrigins []string
+	// AllowCredentials lets browsers send cookies and authorization headers
+	// and read the responses. It cannot be combined with the "*" origin or
+	// "*" header lists, which browsers do not honour for credentialed
+	// requests.
+	AllowCredentials bool
+	// AllowHeaders lists the request headers preflights allow. An empty list
+	// allows the headers the preflight asks for.
+	AllowHeaders []string
+	// ExposeHeaders lists the response headers scripts may read, besides the
+	// CORS-safelisted ones.
+	ExposeHeaders []string
+	// MaxAge is how long browsers may cache a preflight response. Zero omits
+	// the header, leaving the browser default.
+	MaxAge time.Duration
+	// AllowPrivateNetwork answers Private Network Access preflights, sent
+	// before a public site calls a server on a private network.
+	AllowPrivateNetwork bool
+}
+
+// CORSConfig configures the CORS middleware. The embedded policy applies to
+// routes without a policy of their own, see SetCORSPolicy.
+type CORSConfig struct {
+	CORSPolicy
+	Skip func(Context) bool
+}
+
+// SetCORSPolicy sets the CORS policy of a single route, in place of the
+// policy of the CORS middleware. It panics when the policy is invalid.
+func SetCORSPolicy(ri RouteInfo, policy CORSPolicy) RouteInfo {
+	if err := policy.validate(); err != nil {
+		panic("router: " + err.Error())
+	}
+	if route, ok := ri.(*RouteDefinition); ok {
+		route.CORS = &policy
+	}
+	return ri
+}
+
+// CORS returns a middleware that adds CORS headers to the responses of
+// routes for allowed origins. Preflight requests usually match no route, see
+// UseCORS to answer them. It panics when the policy is invalid.
+func CORS(config ...CORSConfig) MiddlewareFunc {
+	var cfg CORSConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if err := cfg.validate(); err != nil {
+		panic("router: " + err.Error())
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			policy := &cfg.CORSPolicy
+			if route, ok := routeFromContext(c.Context()); ok && route.CORS != nil {
+				policy = route.CORS
+			}
+			policy.writeHeaders(c)
+			return next(c)
+		}
+	}
+}
+
+// UseCORS adds the CORS middleware to r and answers preflight requests with
+// the methods r registered for the requested path, through an OPTIONS miss
+// handler. Routes that declare OPTIONS themselves answer their preflights.
+//
+// Call it on the root router before declaring routes, groups or mounts. The
+// miss handler serves the whole app and looks the path up in every route at
+// each preflight, so it covers routes declared later anywhere, including by a
+// RouteMutation. The middleware only reaches the routes declared after it, on
+// r and on the groups and mounts created from r afterwards, so preflights for
+// earlier routes would be answered while their responses lack CORS headers:
+//
+//	err := router.UseCORS(app.Router(), router.CORSConfig{
+//		CORSPolicy: router.CORSPolicy{
+//			AllowOrigins:     []string{"https://app.example.com", "https://*.example.dev"},
+//			AllowCredentials: true,
+//			ExposeHeaders:    []string{"X-Request-ID"},
+//			MaxAge:           10 * time.Minute,
+//		},
+//	})
+func UseCORS[T any](r Router[T], config ...CORSConfig) error {
+	registrar, ok := r.(MissHandlerRegistrar)
+	if !ok {
+		return fmt.Errorf("%T does not support miss handlers", r)
+	}
+	var cfg CORSConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	middleware := CORS(cfg)
+	r.Use(middleware)
+	registrar.HandleMiss(HTTPMethod(http.MethodOptions), func(c Context) error {
+		if cfg.Skip != nil && cfg.Skip(c) {
+			return c.SendStatus(http.StatusNotFound)
+		}
+		return servePreflight(c, r.Routes(), &cfg.CORSPolicy)
+	})
+	return nil
+}
+
+// servePreflight answers an OPTIONS request from the routes matching its path.
+func servePreflight(c Context, routes []RouteDefinition, policy *CORSPolicy) error {
+	hostname := requestHostname(requestHost(c))
+	requested := HTTPMethod(strings.ToUpper(strings.TrimSpace(c.Header("Access-Control-Request-Method"))))
+	var methods []string
+	for i := range routes {
+		route := &routes[i]
+		if _, ok := route.host.match(hostname); !ok {
+			continue
+		}
+		if _, ok := matchMountPath(stripParamConstraints(route.Path), c.Path()); !ok {
+			continue
+		}
+		if !slices.Contains(methods, string(route.Method)) {
+			methods = append(methods, string(route.Method))
+		}
+		if route.Method == requested && route.CORS != nil {
+			policy = route.CORS
+		}
+	}
+	if len(methods) == 0 {
+		return c.SendStatus(http.StatusNotFound)
+	}
+	methods = append(methods, http.MethodOptions)
+	c.SetHeader("Allow", strings.Join(methods, ", "))
+
+	if requested == "" || !policy.writeHeaders(c) {
+		return c.NoContent(http.StatusNoContent)
+	}
+	c.SetHeader("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(policy.AllowHeaders) > 0 {
+		c.SetHeader("Access-Control-Allow-Headers", strings.Join(policy.AllowHeaders, ", "))
+	} else {
+		addVary(c, "Access-Control-Request-Headers")
+		if headers := c.Header("Access-Control-Request-Headers"); headers != "" {
+			c.SetHeader("Access-Control-Allow-Headers", headers)
+		}
+	}
+	if policy.MaxAge > 0 {
+		c.SetHeader("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+	if policy.AllowPrivateNetwork {
+		addVary(c, "Access-Control-Request-Private-Network")
+		if c.Header("Access-Control-Request-Private-Network") == "true" {
+			c.SetHeader("Access-Control-Allow-Private-Network", "true")
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// writeHeaders sets the headers every response to an allowed origin carries
+// and reports whether the request origin is allowed. Responses that depend on
+// the origin vary by it, including those to requests without one, so caches
+// do not serve one origin's response to another.
+func (p *CORSPolicy) writeHeaders(c Context) bool {
+	anyOrigin := slices.Contains(p.AllowOrigins, "*")
+	if !anyOrigin {
+		addVary(c, "Origin")
+	}
+	origin := c.Header("Origin")
+	if origin == "" || !matchesAnyOriginPattern(origin, p.AllowOrigins) {
+		return false
+	}
+
+	if anyOrigin {
+		c.SetHeader("Access-Control-Allow-Origin", "*")
+	} else {
+		c.SetHeader("Access-Control-Allow-Origin", origin)
+	}
+	if p.AllowCredentials {
+		c.SetHeader("Access-Control-Allow-Credentials", "true")
+	}
+	if len(p.ExposeHeaders) > 0 {
+		c.SetHeader("Access-Control-Expose-Headers", strings.Join(p.ExposeHeaders, ", "))
+	}
+	return true
+}
+
+func (p CORSPolicy) validate() error {
+	if !p.AllowCredentials {
+		return nil
+	}
+	if slices.Contains(p.AllowOrigins, "*") {
+		return errors.New("cors credentials cannot be allowed for any origin, list the origins instead")
+	}
+	if slices.Contains(p.AllowHeaders, "*") || slices.Contains(p.ExposeHeaders, "*") {
+		return errors.New("cors credentials cannot be combined with \"*\" header lists")
+	}
+	return nil
+}
+
+// addVary adds names to the Vary header of the response, keeping the names
+// set before, such as the ones versioned routes vary by.
+func addVary(c Context, names ...string) {
+	var existing []string
+	if state, ok := AsResponseState(c); ok {
+		existing = state.ResponseHeaders().Values("Vary")
+	}
+	missing := missingVary(existing, names...)
+	if len(missing) == 0 {
+		return
+	}
+	if appender, ok := AsResponseHeaderAppender(c); ok {
+		appender.AppendResponseHeader("Vary", strings.Join(missing, ", "))
+		return
+	}
+	c.SetHeader("Vary", strings.Join(append(existing, missing...), ", "))
+}
+
+// missingVary returns the names not listed in the Vary header values, or
+// none when they hold "*".
+func missingVary(values []string, names ...string) []string {
+	var tokens []string
+	for _, value := range values {
+		for token := range strings.SplitSeq(value, ",") {
+			tokens = append(tokens, strings.ToLower(strings.TrimSpace(token)))
+		}
+	}
+	if slices.Contains(tokens, "*") {
+		return nil
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !slices.Contains(tokens, strings.ToLower(name)) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
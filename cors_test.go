@@ -0,0 +1,125 @@
+package router_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func TestUseCORS_PreflightAndResponses(t *testing.T) {
+	setup := routertest.Setup{Fiber: corsRoutes, HTTPRouter: corsRoutes, ServeMux: corsRoutes}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		res := client.Request(http.MethodOptions, "/users/42").
+			Header("Origin", "https://app.example.com").
+			Header("Access-Control-Request-Method", "DELETE").
+			Header("Access-Control-Request-Headers", "X-Token").
+			Header("Access-Control-Request-Private-Network", "true").
+			Do(t).
+			AssertStatus(http.StatusNoContent).
+			AssertHeader("Access-Control-Allow-Origin", "https://app.example.com").
+			AssertHeader("Access-Control-Allow-Credentials", "true").
+			AssertHeader("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS").
+			AssertHeader("Access-Control-Allow-Headers", "X-Token").
+			AssertHeader("Access-Control-Max-Age", "600").
+			AssertHeader("Access-Control-Allow-Private-Network", "true")
+		assert.Equal(t, "Origin, Access-Control-Request-Headers, Access-Control-Request-Private-Network", vary(res))
+
+		res = client.Get("/users/42").Header("Origin", "https://api.example.dev").Do(t).
+			AssertHeader("Access-Control-Allow-Origin", "https://api.example.dev").
+			AssertHeader("Access-Control-Expose-Headers", "X-Request-ID")
+		assert.Equal(t, "Origin", vary(res))
+
+		for _, origin := range []string{"https://evil.test", ""} {
+			req := client.Get("/users/42")
+			if origin != "" {
+				req.Header("Origin", origin)
+			}
+			res = req.Do(t).AssertStatus(http.StatusOK).AssertHeader("Access-Control-Allow-Origin", "")
+			assert.Equal(t, "Origin", vary(res), "responses vary by origin even when none is allowed")
+		}
+
+		res = client.Get("/public").Header("Origin", "https://evil.test").Do(t).
+			AssertHeader("Access-Control-Allow-Origin", "*").
+			AssertHeader("Access-Control-Allow-Credentials", "")
+		assert.Empty(t, vary(res))
+
+		client.Request(http.MethodOptions, "/admin/settings").
+			Header("Origin", "https://app.example.com").
+			Header("Access-Control-Request-Method", "PUT").
+			Do(t).
+			AssertStatus(http.StatusNoContent).
+			AssertHeader("Access-Control-Allow-Methods", "PUT, OPTIONS")
+		client.Put("/admin/settings").Header("Origin", "https://app.example.com").Do(t).
+			AssertHeader("Access-Control-Allow-Origin", "https://app.example.com")
+
+		client.Request(http.MethodOptions, "/missing").
+			Header("Origin", "https://app.example.com").
+			Header("Access-Control-Request-Method", "GET").
+			Do(t).
+			AssertStatus(http.StatusNotFound)
+	})
+}
+
+func TestUseCORS_PreflightsRoutesAddedWhileServing(t *testing.T) {
+	app := router.NewHTTPServer()
+	corsRoutes(app.Router())
+	client := routertest.NewClient(app)
+	preflight := func() *routertest.RequestBuilder {
+		return client.Request(http.MethodOptions, "/reports/7").
+			Header("Origin", "https://app.example.com").
+			Header("Access-Control-Request-Method", "POST")
+	}
+	preflight().Do(t).AssertStatus(http.StatusNotFound)
+
+	m := app.Router().(router.RouteTransactor).BeginRouteMutation()
+	m.Add(router.POST, "/reports/:id", ping)
+	_, err := m.Commit()
+	require.NoError(t, err)
+
+	preflight().Do(t).
+		AssertStatus(http.StatusNoContent).
+		AssertHeader("Access-Control-Allow-Methods", "POST, OPTIONS")
+	client.Post("/reports/7").Header("Origin", "https://app.example.com").Do(t).
+		AssertHeader("Access-Control-Allow-Origin", "https://app.example.com")
+}
+
+func TestCORS_RejectsCredentialsForAnyOrigin(t *testing.T) {
+	assert.Panics(t, func() {
+		router.CORS(router.CORSConfig{CORSPolicy: router.CORSPolicy{AllowOrigins: []string{"*"}, AllowCredentials: true}})
+	})
+	assert.Panics(t, func() {
+		router.SetCORSPolicy(router.NewRouteDefinition(), router.CORSPolicy{
+			AllowOrigins:     []string{"https://app.example.com"},
+			AllowCredentials: true,
+			ExposeHeaders:    []string{"*"},
+		})
+	})
+}
+
+func corsRoutes[T any](r router.Router[T]) {
+	err := router.UseCORS(r, router.CORSConfig{CORSPolicy: router.CORSPolicy{
+		AllowOrigins:        []string{"https://app.example.com", "https://*.example.dev"},
+		AllowCredentials:    true,
+		ExposeHeaders:       []string{"X-Request-ID"},
+		MaxAge:              10 * time.Minute,
+		AllowPrivateNetwork: true,
+	}})
+	if err != nil {
+		panic(err)
+	}
+	r.Get("/users/:id", ping)
+	r.Delete("/users/:id", ping)
+	router.SetCORSPolicy(r.Get("/public", ping), router.CORSPolicy{AllowOrigins: []string{"*"}})
+	r.Group("/admin").Put("/settings", ping)
+}
+
+func vary(res *routertest.Response) string {
+	return strings.Join(res.Header.Values("Vary"), ", ")
+}
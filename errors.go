@@ -58,6 +58,30 @@ func NewConflictError(message string, metas ...map[string]any) *errors.Error {
 		WithMetadata(metas...)
 }
 
+// NewUnprocessableEntityError for well-formed requests that cannot be processed
+func NewUnprocessableEntityError(message string, metas ...map[string]any) *errors.Error {
+	return errors.New(message, errors.CategoryBadInput).
+		WithCode(http.StatusUnprocessableEntity).
+		WithTextCode("UNPROCESSABLE_ENTITY").
+		WithMetadata(metas...)
+}
+
+// NewPreconditionFailedError for requests whose conditional headers do not match
+func NewPreconditionFailedError(message string, metas ...map[string]any) *errors.Error {
+	return errors.New(message, errors.CategoryConflict).
+		WithCode(http.StatusPreconditionFailed).
+		WithTextCode("PRECONDITION_FAILED").
+		WithMetadata(metas...)
+}
+
+// NewPreconditionRequiredError for writes that must be conditional
+func NewPreconditionRequiredError(message string, metas ...map[string]any) *errors.Error {
+	return errors.New(message, errors.CategoryBadInput).
+		WithCode(http.StatusPreconditionRequired).
+		WithTextCode("PRECONDITION_REQUIRED").
+		WithMetadata(metas...)
+}
+
 // NewTooManyRequestsError for rate-limiting scenarios
 func NewTooManyRequestsError(message string, metas ...map[string]any) *errors.Error {
 	return errors.New(message, errors.CategoryRateLimit).
@@ -21,6 +21,7 @@ const (
 	dropReasonSlowConsumer     = "slow_consumer"
 	dropReasonClientDisconnect = "client_disconnect"
 	dropReasonCursorNotFound   = "cursor_not_found"
+	dropReasonShutdown         = "shutdown"
 )
 
 // Option mutates stream configuration before construction.
@@ -288,6 +289,22 @@ func (s *stream) SnapshotStats() Stats {
 	return stats
 }
 
+// Drain ends every open subscription, closing its Records channel, and
+// returns how many it ended. See AsDrainer.
+func (s *stream) Drain(context.Context) (int, error) {
+	s.mu.Lock()
+	drops := make([]DropEvent, 0, len(s.subscribers))
+	for sub := range s.subscribers {
+		if drop := s.removeSubscriberLocked(sub, dropReasonShutdown); drop != nil {
+			drops = append(drops, *drop)
+		}
+	}
+	s.mu.Unlock()
+
+	s.dispatchDropHooks(drops)
+	return len(drops), nil
+}
+
 func (s *stream) ensureBucketLocked(scope Scope, scopeKey string) *scopeBucket {
 	if bucket, ok := s.buckets[scopeKey]; ok {
 		return bucket
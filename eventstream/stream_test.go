@@ -224,3 +224,22 @@ func assertNoRecord(t *testing.T, records <-chan eventstream.Record) {
 	case <-time.After(50 * time.Millisecond):
 	}
 }
+
+type undrainableStream struct {
+	eventstream.Stream
+}
+
+func TestAsDrainerReportsStreamsThatCannotDrain(t *testing.T) {
+	stream := eventstream.New()
+	sub, err := stream.Subscribe(t.Context(), eventstream.Scope{"tenant": "t1"}, "")
+	require.NoError(t, err)
+
+	ended, err := eventstream.AsDrainer(stream).Drain(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, ended)
+	_, open := <-sub.Records
+	assert.False(t, open)
+
+	_, err = eventstream.AsDrainer(undrainableStream{stream}).Drain(t.Context())
+	require.ErrorIs(t, err, eventstream.ErrNotDrainable)
+}
@@ -3,6 +3,8 @@ package eventstream
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -84,3 +86,34 @@ type Stream interface {
 	Subscribe(ctx context.Context, scope Scope, afterCursor string) (*Subscription, error)
 	SnapshotStats() Stats
 }
+
+// ErrNotDrainable is returned when draining a stream that cannot end its
+// subscriptions.
+var ErrNotDrainable = errors.New("eventstream: stream cannot be drained")
+
+// Drainer ends every open subscription of a stream and returns how many it
+// ended. It matches router.StreamDrainer.
+type Drainer interface {
+	Drain(ctx context.Context) (int, error)
+}
+
+// AsDrainer adapts s for router.ShutdownCoordinator.AddStreams:
+//
+//	coordinator.AddStreams(eventstream.AsDrainer(stream))
+//
+// Streams returned by New are drained. Other implementations report
+// ErrNotDrainable from Drain, so the shutdown report shows the mistake.
+func AsDrainer(s Stream) Drainer {
+	if drainer, ok := s.(Drainer); ok {
+		return drainer
+	}
+	return notDrainable{stream: s}
+}
+
+type notDrainable struct {
+	stream Stream
+}
+
+func (n notDrainable) Drain(context.Context) (int, error) {
+	return 0, fmt.Errorf("%w: %T", ErrNotDrainable, n.stream)
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
 	"slices"
+	"strings"
 	"sync"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +15,7 @@ import (
 )
 
 type FiberAdapter struct {
+	lifecycle
 	mu            sync.Mutex
 	app           *fiber.App
 	initialized   bool
@@ -261,29 +265,104 @@ func (r *FiberRouter) routeRegistration(route *RouteDefinition) {
 		return
 	}
 
-	r.logger.Info("registering route", "method", route.Method, "path", route.Path, "name", route.Name)
+	r.logger.Info("registering route", "method", route.Method, "path", route.Path, "name", route.Name, "host", route.Host)
 
-	r.app.Add(string(route.Method), route.Path, func(c *fiber.Ctx) error {
-		ctx := NewFiberContext(c, r.logger)
-		if fc, ok := ctx.(*fiberContext); ok {
-			fc.setMergeStrategy(r.mergeStrategy)
-			fc.setHandlers(route.Handlers)
-			fc.index = -1 // reset index to ensure proper chain execution
-
-			// Inject route context
-			goCtx := fc.Context()
-			goCtx = WithRouteName(goCtx, route.Name)
-			goCtx = WithRouteParams(goCtx, c.AllParams())
-			fc.SetContext(goCtx)
+	// A path already mounted for another host or version, or left by a
+	// removed route, dispatches here without a new Fiber route.
+	_ = r.root.attachRoute(route, func(mount *routeMount) error {
+		if r.root.registrationState() == RegistrationSealed {
+			// Fiber cannot take routes while serving, so the overlay
+			// handler matches the path after every native route.
+			mount.overlay = true
+			return nil
+		}
+		r.app.Add(string(mount.method), mount.path, r.fiberRouteHandler(mount))
+		if route.Name != "" && route.nameMode == routeNameModePublic {
+			r.app.Name(route.Name)
+		}
+		return nil
+	})
+	r.root.recordMounted(route)
+}
 
-			return fc.Next()
+func (r *FiberRouter) fiberRouteHandler(mount *routeMount) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		route, hostParams, ok := mount.resolve(routeRequest{
+			hostname: requestHostname(c.Hostname()),
+			header:   func(name string) string { return c.Get(name) },
+			param:    func(name string) string { return c.Params(name) },
+		})
+		if !ok {
+			// Fall through to later routes, the miss handler or Fiber's 404.
+			return c.Next()
 		}
+		return r.serveRoute(c, route, hostParams)
+	}
+}
+
+// serveRoute runs the handler chain of route. params holds the parameters
+// Fiber did not capture, from the host pattern or the overlay.
+func (r *FiberRouter) serveRoute(c *fiber.Ctx, route *RouteDefinition, params map[string]string) error {
+	ctx := NewFiberContext(c, r.logger)
+	fc, ok := ctx.(*fiberContext)
+	if !ok {
 		return fmt.Errorf("context cast failed")
-	})
-	if route.Name != "" && route.nameMode == routeNameModePublic {
-		r.app.Name(route.Name)
 	}
-	r.root.recordMounted(route)
+	fc.setMergeStrategy(r.mergeStrategy)
+	fc.setHandlers(route.Handlers)
+	fc.hostParams = params
+	fc.index = -1 // reset index to ensure proper chain execution
+
+	// Inject route context
+	allParams := c.AllParams()
+	maps.Copy(allParams, params)
+	goCtx := fc.Context()
+	goCtx = WithRouteName(goCtx, route.Name)
+	goCtx = WithRouteParams(goCtx, allParams)
+	goCtx = withRoute(goCtx, route)
+	fc.SetContext(goCtx)
+	writeRouteHeaders(fc, route)
+
+	return fc.Next()
+}
+
+// registerOverlay mounts the handler for routes added by a RouteMutation
+// after Init. It runs after every native route and before the miss handlers.
+func (r *FiberRouter) registerOverlay() {
+	r.app.Use(func(c *fiber.Ctx) error {
+		route, matched, hostParams, ok := r.root.matchOverlay(c.Method(), c.Path(), routeRequest{
+			hostname: requestHostname(c.Hostname()),
+			header:   func(name string) string { return c.Get(name) },
+		})
+		if !ok {
+			return c.Next()
+		}
+		params := make(map[string]string, len(matched)+len(hostParams))
+		for _, p := range matched {
+			// Fiber catch-all values have no leading slash.
+			params[p.Key] = strings.TrimPrefix(p.Value, "/")
+		}
+		maps.Copy(params, hostParams)
+		return r.serveRoute(c, route, params)
+	})
+}
+
+// mountMutation mounts a route added by a RouteMutation.
+func (r *FiberRouter) mountMutation(route *RouteDefinition) error {
+	r.routeRegistration(route)
+	return nil
+}
+
+func (r *FiberRouter) mutationPath(path string) string {
+	return r.joinPath(r.prefix, path)
+}
+
+func (r *FiberRouter) BeginRouteMutation() *RouteMutation {
+	return newRouteMutation(&r.BaseRouter, r)
+}
+
+func (r *FiberRouter) TryRemove(method HTTPMethod, path string) error {
+	return tryRemoveRoute(r.BeginRouteMutation(), method, path)
 }
 
 func (r *FiberRouter) registerDeferredRoutes() {
@@ -335,7 +414,7 @@ func (r *FiberRouter) registerMissHandlers() {
 
 func (r *FiberRouter) detectRouteConflict(method HTTPMethod, fullPath string) *routeConflict {
 	for _, route := range r.root.routes {
-		if route.Method != method {
+		if route.Method != method || r.isRouteVariant(route, fullPath) {
 			continue
 		}
 		if route.Path == fullPath {
@@ -357,12 +436,19 @@ func (r *FiberRouter) GetPrefix() string {
 	return r.prefix
 }
 
+// Init mounts the declared routes. It panics if a route hook rejects a route.
 func (a *FiberAdapter) Init() {
+	if err := a.initialize(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *FiberAdapter) initialize() (err error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	if a.initialized {
-		return
+		return nil
 	}
 
 	// Ensure router is initialized even when callers only use WrappedRouter().
@@ -375,8 +461,10 @@ func (a *FiberAdapter) Init() {
 	a.router.registerLateRoutes(a.router)
 	if !a.router.root.beginFinalization() {
 		a.initialized = true
-		return
+		return nil
 	}
+	// Route hooks run once the root lock is released.
+	defer func() { err = a.router.root.announceRoutes() }()
 	defer a.router.root.finishFinalization()
 
 	if a.strictRoutes {
@@ -386,17 +474,66 @@ func (a *FiberAdapter) Init() {
 	}
 
 	a.router.registerDeferredRoutes()
+	a.router.registerOverlay()
 	a.router.registerMissHandlers()
 	a.initialized = true
+	return nil
 }
 
+var _ LifecycleHooks = (*FiberAdapter)(nil)
+
+var (
+	_ ListenerServer = (*FiberAdapter)(nil)
+	_ TLSServer      = (*FiberAdapter)(nil)
+)
+
 func (a *FiberAdapter) Serve(address string) error {
-	a.Init()
-	return a.app.Listen(address)
+	if err := a.runStartup(context.Background(), a.prepare); err != nil {
+		return err
+	}
+
+	config := a.app.Config()
+	if config.Prefork {
+		// Each prefork child binds the address itself, so OnListen hooks
+		// do not run.
+		return a.app.Listen(address)
+	}
+	ln, err := listenTCP(config.Network, address)
+	if err != nil {
+		return err
+	}
+	return a.ServeListener(ln)
+}
+
+// ServeListener serves the app on ln. See ListenerServer.
+func (a *FiberAdapter) ServeListener(ln net.Listener) error {
+	if err := a.runStartup(context.Background(), a.prepare); err != nil {
+		return errors.Join(err, ln.Close())
+	}
+	if err := a.runListen(ln); err != nil {
+		return err
+	}
+	return a.app.Listener(ln)
+}
+
+// ServeTLS serves the app over TLS on address. See TLSServer.
+func (a *FiberAdapter) ServeTLS(address string, config TLSConfig) error {
+	return serveTLS(&a.lifecycle, a.prepare, a, a.app.Config().Network, address, config)
+}
+
+func (a *FiberAdapter) prepare() error {
+	return a.initialize()
 }
 
 func (a *FiberAdapter) Shutdown(ctx context.Context) error {
-	return a.app.ShutdownWithContext(ctx)
+	err := a.app.ShutdownWithContext(ctx)
+	return errors.Join(err, a.runShutdown(ctx))
+}
+
+// OnRouteMounted adds a route hook. See LifecycleHooks.
+func (a *FiberAdapter) OnRouteMounted(hook func(route RouteDefinition) error) {
+	a.Router()
+	a.router.root.addRouteHook(hook)
 }
 
 func (a *FiberAdapter) WrappedRouter() *fiber.App {
@@ -437,6 +574,7 @@ func (r *FiberRouter) Group(prefix string) Router[*fiber.App] {
 			namedRoutePolicy: r.namedRoutePolicy,
 			routes:           r.routes,
 			root:             r.root,
+			routeScope:       r.routeScope,
 		},
 	}
 }
@@ -460,10 +598,51 @@ func (r *FiberRouter) Mount(prefix string) Router[*fiber.App] {
 			namedRoutePolicy: r.namedRoutePolicy,
 			routes:           r.routes,
 			root:             r.root,
+			routeScope:       r.routeScope,
 		},
 	}
 }
 
+// Host returns a group whose routes only answer requests for hosts matching
+// pattern. See HostRouter.
+func (r *FiberRouter) Host(pattern string) Router[*fiber.App] {
+	scope := r.routeScope
+	scope.host = mustCompileHostPattern(pattern)
+	return r.scoped(scope)
+}
+
+// Version returns a group whose routes belong to the API version name. See
+// VersionRouter.
+func (r *FiberRouter) Version(name string, strategy VersionStrategy) Router[*fiber.App] {
+	g := r.scoped(r.routeScope)
+	g.enterVersion(name, strategy)
+	return g
+}
+
+// scoped returns a group with the prefix and middleware of r and scope.
+func (r *FiberRouter) scoped(scope routeScope) *FiberRouter {
+	g := r.Group("").(*FiberRouter)
+	g.prefix = r.prefix
+	g.routeScope = scope
+	return g
+}
+
+func (r *FiberRouter) withScope(scope routeScope) lateRouteRegistrar {
+	if scope == r.routeScope {
+		return r
+	}
+	return r.scoped(scope)
+}
+
+func (r *FiberRouter) mountFallback(route *RouteDefinition) error {
+	g := r.scoped(route.routeScope)
+	if conflict := g.detectRouteConflict(route.Method, route.Path); conflict != nil {
+		return newRouteConflictError(route.Method, route.Path, conflict, r.conflictPolicy, r.pathConflictMode)
+	}
+	g.routeRegistration(route)
+	return nil
+}
+
 func (r *FiberRouter) WithGroup(path string, cb func(r Router[*fiber.App])) Router[*fiber.App] {
 	g := r.Group(path)
 	cb(g)
@@ -543,7 +722,7 @@ func (r *FiberRouter) TryReplaceWithOptions(method HTTPMethod, pathStr string, h
 	}
 
 	for _, route := range r.root.routes {
-		if route.Method != method || route.Path != fullPath {
+		if !r.isSameRoute(route, method, fullPath) {
 			continue
 		}
 		allMw := slices.Clone(route.middlewares)
@@ -576,7 +755,7 @@ func (r *FiberRouter) TryUpsertWithOptions(method HTTPMethod, pathStr string, ha
 		return nil, false, newRegistrationError("upsert route", method, fullPath, r.root.registrationState(), ErrRouterSealed)
 	}
 	for _, route := range r.root.routes {
-		if route.Method == method && route.Path == fullPath {
+		if r.isSameRoute(route, method, fullPath) {
 			allMw := slices.Clone(route.middlewares)
 			if options.ReplaceMiddleware {
 				allMw = slices.Clone(r.middlewares)
@@ -683,6 +862,15 @@ func (r *FiberRouter) WebSocket(path string, config WebSocketConfig, handler fun
 	return route
 }
 
+// RoutingCapabilities reports the configured Fiber conflict semantics.
+func (r *FiberRouter) RoutingCapabilities() RoutingCapabilities {
+	caps := r.BaseRouter.RoutingCapabilities()
+	caps.PathConflictMode = r.pathConflictMode.normalize()
+	caps.CatchAllSiblings = !r.enforceCatchAllConflicts
+	caps.OrderIndependent = r.orderRoutesBySpecificity
+	return caps
+}
+
 func (r *FiberRouter) PrintRoutes() {
 	r.BaseRouter.PrintRoutes()
 }
@@ -694,14 +882,18 @@ func (r *FiberRouter) WithLogger(logger Logger) Router[*fiber.App] {
 
 func (r *FiberRouter) ValidateRoutes() []error {
 	routes := collectRoutesForValidation(&r.BaseRouter)
-	errs := ValidateRouteDefinitionsWithOptions(routes, RouteValidationOptions{
+	errs := ValidateRouteDefinitionsWithOptions(routes, r.routeValidationOptions())
+	if r.namedRoutePolicy.normalize() == NamedRouteCollisionPolicyError {
+		errs = append(errs, r.namedRouteConflicts()...)
+	}
+	return errs
+}
+
+func (r *FiberRouter) routeValidationOptions() RouteValidationOptions {
+	return RouteValidationOptions{
 		PathConflictMode:         r.pathConflictMode,
 		EnforceCatchAllConflicts: r.enforceCatchAllConflicts,
 		EnforceRouteLints:        r.enforceRouteLints,
 		NamedRoutePolicy:         r.namedRoutePolicy,
-	})
-	if r.namedRoutePolicy.normalize() == NamedRouteCollisionPolicyError {
-		errs = append(errs, r.namedRouteConflicts()...)
 	}
-	return errs
 }
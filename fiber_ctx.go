@@ -3,6 +3,7 @@ package router
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,6 +32,8 @@ type fiberContext struct {
 	written       bool
 	bodySize      int64
 	stream        bool
+	// hostParams are the parameters captured by the route's Host pattern.
+	hostParams map[string]string
 }
 
 // fiberRequestMeta caches request data needed after fasthttp hijacks the connection.
@@ -163,6 +166,15 @@ func (c *fiberContext) Request() *http.Request {
 	return req
 }
 
+// TLSConnectionState returns the TLS state of the request. See TLSContext.
+func (c *fiberContext) TLSConnectionState() *tls.ConnectionState {
+	ctx := c.liveCtx()
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Context().TLSConnectionState()
+}
+
 func (c *fiberContext) Response() http.ResponseWriter {
 	if c == nil {
 		return nil
@@ -218,6 +230,7 @@ func (c *fiberContext) captureRequestMeta() {
 	})
 
 	maps.Copy(meta.params, ctx.AllParams())
+	maps.Copy(meta.params, c.hostParams)
 
 	ctx.Request().Header.VisitAllCookie(func(key, value []byte) {
 		meta.cookies[string(key)] = string(value)
@@ -383,6 +396,9 @@ func (c *fiberContext) Path() string {
 }
 
 func (c *fiberContext) Param(name string, defaultValue ...string) string {
+	if val, ok := c.hostParams[name]; ok {
+		return val
+	}
 	if ctx := c.liveCtx(); ctx != nil {
 		return ctx.Params(name, defaultValue...)
 	}
@@ -458,7 +474,11 @@ func (c *fiberContext) Redirect(location string, status ...int) error {
 		code = status[0]
 	}
 	c.logger.Info("redirect request", "location", location, "code", code)
-	return ctx.Redirect(location, code)
+	if err := ctx.Redirect(location, code); err != nil {
+		return err
+	}
+	c.syncFiberResponseState(true, false)
+	return nil
 }
 
 func (c *fiberContext) RedirectToRoute(routeName string, params ViewContext, status ...int) error {
@@ -478,6 +498,12 @@ func (c *fiberContext) RedirectBack(fallback string, status ...int) error {
 }
 
 func (c *fiberContext) ParamsInt(name string, defaultValue int) int {
+	if val, ok := c.hostParams[name]; ok {
+		if out, err := strconv.Atoi(val); err == nil {
+			return out
+		}
+		return defaultValue
+	}
 	if ctx := c.liveCtx(); ctx != nil {
 		if out, err := ctx.ParamsInt(name, defaultValue); err == nil {
 			return out
@@ -651,10 +677,29 @@ func (c *fiberContext) NoContent(code int) error {
 }
 
 func (c *fiberContext) Bind(v any) error {
-	if ctx := c.liveCtx(); ctx != nil {
-		return ctx.BodyParser(v)
+	if c.liveCtx() == nil {
+		return fmt.Errorf("context unavailable")
 	}
-	return fmt.Errorf("context unavailable")
+	return bindBody(c, v)
+}
+
+func (c *fiberContext) BindQuery(v any) error {
+	return bindQuery(c, v)
+}
+
+func (c *fiberContext) BindParams(v any) error {
+	return bindParams(c, v)
+}
+
+func (c *fiberContext) BindHeaders(v any) error {
+	return bindHeaders(c, v)
+}
+
+func (c *fiberContext) BindForm(v any) error {
+	if c.liveCtx() == nil {
+		return fmt.Errorf("context unavailable")
+	}
+	return bindForm(c, v)
 }
 
 func (c *fiberContext) Context() context.Context {
@@ -824,12 +869,19 @@ func (c *fiberContext) GetBool(key string, def bool) bool {
 }
 
 func (c *fiberContext) Next() error {
-	c.index++
-	if c.index >= len(c.handlers) {
+	handler, ok := c.nextHandler()
+	if !ok {
 		return nil
 	}
+	return handler(c)
+}
 
-	return c.handlers[c.index].Handler(c)
+func (c *fiberContext) nextHandler() (HandlerFunc, bool) {
+	c.index++
+	if c.index >= len(c.handlers) {
+		return nil, false
+	}
+	return c.handlers[c.index].Handler, true
 }
 
 // RouteName returns the route name from context
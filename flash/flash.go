@@ -67,17 +67,28 @@ func New(config Config) *Flash {
 	}
 }
 
+// CookieName returns the name of the cookie that carries flash data.
+func (f *Flash) CookieName() string {
+	return f.config.Name
+}
+
+// Decode parses a raw flash cookie value as set by Redirect and the With*
+// helpers. Values are returned as strings.
+func Decode(value string) router.ViewContext {
+	out := router.ViewContext{}
+	parseKeyValueCookie(value, func(key string, val any) {
+		out[key] = val
+	})
+	return out
+}
+
 func (f *Flash) Get(c router.Context) router.ViewContext {
 	cookieValue := c.Cookies(f.config.Name)
 	if cookieValue == "" {
 		return router.ViewContext{}
 	}
 
-	out := router.ViewContext{}
-	parseKeyValueCookie(cookieValue, func(key string, val any) {
-		out[key] = val
-	})
-
+	out := Decode(cookieValue)
 	f.clearCookie(c)
 	return out
 }
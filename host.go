@@ -0,0 +1,223 @@
+package router
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	goerrors "github.com/goliatone/go-errors"
+	"github.com/julienschmidt/httprouter"
+)
+
+// HostRouter is implemented by routers that can partition routes by the
+// request host.
+//
+// Host returns a group whose routes only answer requests for hosts matching
+// pattern. Patterns are dot separated labels where "{name}" captures a label
+// as a route parameter and "*" matches any single label:
+//
+//	api := router.Host(app.Router(), "api.example.com")
+//	tenant := router.Host(app.Router(), "{tenant}.example.com")
+//	preview := router.Host(app.Router(), "*.preview.example.com")
+//
+// The pattern "*" matches every host. Routes registered outside a Host group
+// form the default host: they answer requests for the same method and path
+// when no host group does. An empty pattern returns such a default group.
+type HostRouter[T any] interface {
+	Host(pattern string) Router[T]
+}
+
+// Host returns the host group of r for pattern. It panics when r does not
+// implement HostRouter.
+func Host[T any](r Router[T], pattern string) Router[T] {
+	hr, ok := r.(HostRouter[T])
+	if !ok {
+		panic(fmt.Sprintf("router: %T does not support host groups", r))
+	}
+	return hr.Host(pattern)
+}
+
+// hostPattern is a compiled Host group pattern such as "{tenant}.example.com".
+type hostPattern struct {
+	pattern  string
+	labels   []string
+	literals int
+	params   int
+	anyHost  bool
+}
+
+func compileHostPattern(pattern string) (*hostPattern, error) {
+	pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+	if pattern == "" {
+		return nil, nil
+	}
+	if pattern == "*" {
+		return &hostPattern{pattern: pattern, anyHost: true}, nil
+	}
+	if strings.ContainsAny(pattern, ":/") {
+		return nil, fmt.Errorf("host pattern %q must be a host name without scheme, port or path", pattern)
+	}
+
+	compiled := &hostPattern{pattern: pattern, labels: strings.Split(pattern, ".")}
+	seen := map[string]struct{}{}
+	for _, label := range compiled.labels {
+		switch {
+		case label == "":
+			return nil, fmt.Errorf("host pattern %q has an empty label", pattern)
+		case label == "*":
+		case strings.HasPrefix(label, "{") && strings.HasSuffix(label, "}"):
+			name := label[1 : len(label)-1]
+			if name == "" || strings.ContainsAny(name, "{}") {
+				return nil, fmt.Errorf("host pattern %q has an invalid parameter %q", pattern, label)
+			}
+			if _, dup := seen[name]; dup {
+				return nil, fmt.Errorf("host pattern %q repeats parameter %q", pattern, name)
+			}
+			seen[name] = struct{}{}
+			compiled.params++
+		case strings.ContainsAny(label, "{}*"):
+			return nil, fmt.Errorf("host pattern %q has an invalid label %q", pattern, label)
+		default:
+			compiled.literals++
+		}
+	}
+	return compiled, nil
+}
+
+// mustCompileHostPattern panics on malformed patterns, the way invalid route
+// paths do.
+func mustCompileHostPattern(pattern string) *hostPattern {
+	compiled, err := compileHostPattern(pattern)
+	if err != nil {
+		panic(goerrors.Wrap(err, goerrors.CategoryBadInput, "invalid host pattern").
+			WithCode(http.StatusInternalServerError).
+			WithTextCode("ROUTE_HOST_PATTERN").
+			WithMetadata(map[string]any{"host": pattern}))
+	}
+	return compiled
+}
+
+// String returns the normalized pattern, or "" for the default host.
+func (p *hostPattern) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.pattern
+}
+
+// match reports whether hostname satisfies the pattern and returns the
+// captured host parameters. A nil pattern is the default host and matches
+// every request.
+func (p *hostPattern) match(hostname string) (map[string]string, bool) {
+	if p == nil || p.anyHost {
+		return nil, true
+	}
+	labels := strings.Split(hostname, ".")
+	if len(labels) != len(p.labels) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, label := range p.labels {
+		switch {
+		case label == "*":
+			if labels[i] == "" {
+				return nil, false
+			}
+		case label[0] == '{':
+			if labels[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string, p.params)
+			}
+			params[label[1:len(label)-1]] = labels[i]
+		case label != labels[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// compareHostPatterns orders patterns from the most to the least specific:
+// more literal labels first, then named parameters before wildcards, then the
+// "*" host and finally the default host.
+func compareHostPatterns(a, b *hostPattern) int {
+	rank := func(p *hostPattern) [3]int {
+		switch {
+		case p == nil:
+			return [3]int{-2, 0, 0}
+		case p.anyHost:
+			return [3]int{-1, 0, 0}
+		default:
+			return [3]int{p.literals, p.params, -len(p.labels)}
+		}
+	}
+	ra, rb := rank(a), rank(b)
+	for i := range ra {
+		if ra[i] != rb[i] {
+			if ra[i] > rb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// requestHostname normalizes a Host header value for matching: the port and
+// a trailing dot are removed and the name is lower cased.
+func requestHostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// withHostParams appends the host parameters to the path parameters. It
+// copies params, which httprouter may reuse once the request completes.
+func withHostParams(params httprouter.Params, hostParams map[string]string) httprouter.Params {
+	if len(hostParams) == 0 {
+		return params
+	}
+	out := make(httprouter.Params, 0, len(params)+len(hostParams))
+	out = append(out, params...)
+	for _, key := range slices.Sorted(maps.Keys(hostParams)) {
+		out = append(out, httprouter.Param{Key: key, Value: hostParams[key]})
+	}
+	return out
+}
+
+// hostServer describes a host pattern as an OpenAPI server object. Host
+// parameters and wildcards become server variables.
+func hostServer(pattern *hostPattern) map[string]any {
+	if pattern == nil || pattern.anyHost {
+		return nil
+	}
+	labels := slices.Clone(pattern.labels)
+	variables := map[string]any{}
+	wildcards := 0
+	for i, label := range labels {
+		switch {
+		case label == "*":
+			wildcards++
+			name := "subdomain"
+			if wildcards > 1 {
+				name = fmt.Sprintf("subdomain%d", wildcards)
+			}
+			labels[i] = "{" + name + "}"
+			variables[name] = map[string]any{"default": name}
+		case label[0] == '{':
+			name := label[1 : len(label)-1]
+			variables[name] = map[string]any{"default": name}
+		}
+	}
+	server := map[string]any{"url": "https://" + strings.Join(labels, ".")}
+	if len(variables) > 0 {
+		server["variables"] = variables
+	}
+	return server
+}
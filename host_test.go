@@ -0,0 +1,108 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func registerHosts[T any](r router.Router[T]) {
+	api := router.Host(r, "api.example.com")
+	api.Get("/status", func(c router.Context) error { return c.SendString("api") })
+
+	tenant := router.Host(r, "{tenant}.example.com")
+	tenant.Get("/status", func(c router.Context) error { return c.SendString("tenant:" + c.Param("tenant")) })
+	tenant.Get("/users/:id", func(c router.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{
+			"tenant": c.Param("tenant"),
+			"id":     c.Param("id"),
+			"params": c.RouteParams(),
+		})
+	})
+
+	router.Host(r, "*.preview.example.com").Get("/status", func(c router.Context) error { return c.SendString("preview") })
+	router.Host(r, "admin.example.com").Group("/admin").Get("/users", func(c router.Context) error { return c.SendString("admin") })
+
+	r.Get("/status", func(c router.Context) error { return c.SendString("default") })
+}
+
+func TestHost_DispatchesByHost(t *testing.T) {
+	setup := routertest.Setup{Fiber: registerHosts, HTTPRouter: registerHosts, ServeMux: registerHosts}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		client.Get("http://api.example.com/status").Do(t).AssertStatus(http.StatusOK).AssertBody("api")
+		client.Get("http://API.Example.com:8080/status").Do(t).AssertStatus(http.StatusOK).AssertBody("api")
+		client.Get("http://acme.example.com/status").Do(t).AssertStatus(http.StatusOK).AssertBody("tenant:acme")
+		client.Get("http://pr-7.preview.example.com/status").Do(t).AssertStatus(http.StatusOK).AssertBody("preview")
+		client.Get("http://localhost/status").Do(t).AssertStatus(http.StatusOK).AssertBody("default")
+		client.Get("http://a.b.example.com/status").Do(t).AssertStatus(http.StatusOK).AssertBody("default")
+
+		client.Get("http://acme.example.com/users/7").Do(t).
+			AssertStatus(http.StatusOK).
+			AssertJSONPath("tenant", "acme").
+			AssertJSONPath("id", "7").
+			AssertJSONPath("params.tenant", "acme")
+		client.Get("http://localhost/users/7").Do(t).AssertStatus(http.StatusNotFound)
+
+		client.Get("http://admin.example.com/admin/users").Do(t).AssertStatus(http.StatusOK).AssertBody("admin")
+		client.Get("http://api.example.com/admin/users").Do(t).AssertStatus(http.StatusNotFound)
+	})
+}
+
+func TestHost_RoutesManifestAndOpenAPI(t *testing.T) {
+	app := router.NewHTTPServer()
+	registerHosts(app.Router())
+
+	hosts := map[string]string{}
+	for _, route := range app.Router().Routes() {
+		if route.Path == "/users/:id" || route.Path == "/admin/users" {
+			hosts[route.Path] = route.Host
+		}
+	}
+	assert.Equal(t, map[string]string{"/users/:id": "{tenant}.example.com", "/admin/users": "admin.example.com"}, hosts)
+
+	var statusHosts []string
+	for _, entry := range router.BuildRouterManifest(app.Router()) {
+		if entry.Path == "/status" {
+			statusHosts = append(statusHosts, entry.Host)
+		}
+	}
+	assert.Equal(t, []string{"", "*.preview.example.com", "api.example.com", "{tenant}.example.com"}, statusHosts)
+
+	doc := router.NewOpenAPIRenderer().AppendServer("https://example.com", "Default").
+		AppenRouteInfo(app.Router().Routes()).GenerateOpenAPI()
+	paths := doc["paths"].(map[string]any)
+
+	users := paths["/users/{id}"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{
+		"url":       "https://{tenant}.example.com",
+		"variables": map[string]any{"tenant": map[string]any{"default": "tenant"}},
+	}}, users["servers"])
+
+	status := paths["/status"].(map[string]any)["get"].(map[string]any)
+	var urls []any
+	for _, server := range status["servers"].([]any) {
+		urls = append(urls, server.(map[string]any)["url"])
+	}
+	assert.Equal(t, []any{
+		"https://api.example.com",
+		"https://{tenant}.example.com",
+		"https://{subdomain}.preview.example.com",
+		"https://example.com",
+	}, urls)
+}
+
+func TestHost_RejectsInvalidPatterns(t *testing.T) {
+	r := router.NewHTTPServer().Router()
+	for _, pattern := range []string{"api.example.com:8080", "https://api.example.com", "api..example.com", "{}.example.com", "a{b}.example.com", "{x}.{x}.com"} {
+		assert.Panics(t, func() { router.Host(r, pattern) }, pattern)
+	}
+
+	route := router.Host(r, "").Get("/plain", func(c router.Context) error { return nil })
+	require.IsType(t, &router.RouteDefinition{}, route)
+	assert.Empty(t, route.(*router.RouteDefinition).Host, "an empty pattern is the default host")
+}
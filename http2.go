@@ -0,0 +1,125 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// HTTP2Config tunes HTTP/2 for the net/http based adapters. HTTP/2 is always
+// offered over TLS; H2C also serves it over plain TCP. Zero values keep the
+// net/http defaults.
+type HTTP2Config struct {
+	// H2C serves HTTP/2 without TLS to clients with prior knowledge, such as
+	// services behind a mesh, next to HTTP/1.1.
+	H2C bool
+	// IdleTimeout closes connections without active requests or streams.
+	IdleTimeout time.Duration
+	// MaxConcurrentStreams limits the streams each client may open at once.
+	MaxConcurrentStreams int
+	// MaxDecoderHeaderTableSize and MaxEncoderHeaderTableSize bound the HPACK
+	// header tables used for requests and responses.
+	MaxDecoderHeaderTableSize int
+	MaxEncoderHeaderTableSize int
+	// MaxReadFrameSize is the largest frame the server reads.
+	MaxReadFrameSize int
+	// MaxReceiveBufferPerConnection and MaxReceiveBufferPerStream size the
+	// flow control windows for request bodies.
+	MaxReceiveBufferPerConnection int
+	MaxReceiveBufferPerStream     int
+	// SendPingTimeout pings idle connections after this long, and
+	// PingTimeout closes them when the ping is not answered in time.
+	SendPingTimeout time.Duration
+	PingTimeout     time.Duration
+	// WriteByteTimeout closes connections that accept no data for this long.
+	WriteByteTimeout time.Duration
+}
+
+var httpRouterHTTP2Mu sync.Mutex
+var httpRouterHTTP2 = map[*httprouter.Router]*HTTP2Config{}
+
+// WithHTTPRouterHTTP2 configures HTTP/2 for NewHTTPServer:
+//
+//	app := router.NewHTTPServer(router.DefaultHTTPRouterOptions, router.WithHTTPRouterHTTP2(router.HTTP2Config{
+//		H2C:                  true,
+//		MaxConcurrentStreams: 250,
+//		IdleTimeout:          2 * time.Minute,
+//	}))
+func WithHTTPRouterHTTP2(config HTTP2Config) func(*httprouter.Router) *httprouter.Router {
+	return func(router *httprouter.Router) *httprouter.Router {
+		httpRouterHTTP2Mu.Lock()
+		httpRouterHTTP2[router] = &config
+		httpRouterHTTP2Mu.Unlock()
+		return router
+	}
+}
+
+func popHTTPRouterHTTP2(router *httprouter.Router) (*HTTP2Config, bool) {
+	httpRouterHTTP2Mu.Lock()
+	defer httpRouterHTTP2Mu.Unlock()
+	config, ok := httpRouterHTTP2[router]
+	if ok {
+		delete(httpRouterHTTP2, router)
+	}
+	return config, ok
+}
+
+// newNetHTTPServer returns the http.Server of the net/http based adapters.
+func newNetHTTPServer(handler http.Handler, config *HTTP2Config) *http.Server {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if config == nil {
+		return srv
+	}
+
+	srv.IdleTimeout = config.IdleTimeout
+	srv.Protocols = new(http.Protocols)
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetHTTP2(true)
+	srv.Protocols.SetUnencryptedHTTP2(config.H2C)
+	srv.HTTP2 = &http.HTTP2Config{
+		MaxConcurrentStreams:          config.MaxConcurrentStreams,
+		MaxDecoderHeaderTableSize:     config.MaxDecoderHeaderTableSize,
+		MaxEncoderHeaderTableSize:     config.MaxEncoderHeaderTableSize,
+		MaxReadFrameSize:              config.MaxReadFrameSize,
+		MaxReceiveBufferPerConnection: config.MaxReceiveBufferPerConnection,
+		MaxReceiveBufferPerStream:     config.MaxReceiveBufferPerStream,
+		SendPingTimeout:               config.SendPingTimeout,
+		PingTimeout:                   config.PingTimeout,
+		WriteByteTimeout:              config.WriteByteTimeout,
+	}
+	return srv
+}
+
+// withHTTP2Protos offers HTTP/2 through ALPN unless config lists its own
+// protocols, as http.Server.ListenAndServeTLS does.
+func withHTTP2Protos(config TLSConfig) TLSConfig {
+	if len(config.NextProtos) == 0 {
+		config.NextProtos = []string{"h2", "http/1.1"}
+	}
+	return config
+}
+
+// flushWriter flushes the response after each write, so streamed bodies
+// reach HTTP/1.1 and HTTP/2 clients as they are produced.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if err := f.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return n, err
+	}
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package router_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+)
+
+func h2cClient() *http.Client {
+	transport := &http.Transport{Protocols: new(http.Protocols)}
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: transport, Timeout: 5 * time.Second}
+}
+
+func protoHandler(c router.Context) error {
+	hc, _ := router.AsHTTPContext(c)
+	return c.SendString(hc.Request().Proto)
+}
+
+func TestHTTP2_H2CServesHTTP2AndHTTP1(t *testing.T) {
+	release := make(chan struct{})
+	h2c := router.HTTP2Config{H2C: true, MaxConcurrentStreams: 16, IdleTimeout: time.Minute}
+	servers := []struct {
+		name   string
+		server func() lifecycleServer
+	}{
+		{"httprouter", func() lifecycleServer {
+			app := router.NewHTTPServer(router.DefaultHTTPRouterOptions, router.WithHTTPRouterHTTP2(h2c))
+			app.Router().Get("/proto", protoHandler)
+			app.Router().Get("/events", streamEvents(release))
+			return app.(lifecycleServer)
+		}},
+		{"servemux", func() lifecycleServer {
+			app := router.NewServeMuxServerWithConfig(router.ServeMuxConfig{HTTP2: &h2c})
+			app.Router().Get("/proto", protoHandler)
+			app.Router().Get("/events", streamEvents(release))
+			return app.(lifecycleServer)
+		}},
+	}
+
+	for _, tc := range servers {
+		t.Run(tc.name, func(t *testing.T) {
+			app := tc.server()
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go app.(router.ListenerServer).ServeListener(ln)
+			defer app.Shutdown(context.Background())
+			base := "http://" + ln.Addr().String()
+
+			assert.Equal(t, "HTTP/2.0", getBody(t, h2cClient(), base+"/proto"))
+			assert.Equal(t, "HTTP/1.1", getBody(t, &http.Client{Timeout: 5 * time.Second}, base+"/proto"))
+
+			res, err := h2cClient().Get(base + "/events")
+			require.NoError(t, err)
+			defer res.Body.Close()
+			reader := bufio.NewReader(res.Body)
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "data: first\n", line, "the first event arrives while the stream is open")
+			release <- struct{}{}
+			rest, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, "\ndata: second\n\n", string(rest))
+		})
+	}
+}
+
+func streamEvents(release <-chan struct{}) router.HandlerFunc {
+	return func(c router.Context) error {
+		reader, writer := io.Pipe()
+		go func() {
+			_, _ = io.WriteString(writer, "data: first\n\n")
+			<-release
+			_, _ = io.WriteString(writer, "data: second\n\n")
+			_ = writer.Close()
+		}()
+		c.SetHeader("Content-Type", "text/event-stream")
+		return c.SendStream(reader)
+	}
+}
+
+func TestHTTP2_ServeTLSNegotiatesHTTP2(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	cert := ca.issue(t, dir, "server", serverCert("localhost"))
+
+	app := router.NewServeMuxServer()
+	app.Router().Get("/proto", protoHandler)
+	listening := make(chan router.ListenInfo, 1)
+	app.(router.LifecycleHooks).OnListen(func(info router.ListenInfo) error {
+		listening <- info
+		return nil
+	})
+	go app.(router.TLSServer).ServeTLS("127.0.0.1:0", router.TLSConfig{Certificates: []router.TLSCertificate{cert}})
+	info := <-listening
+	defer app.Shutdown(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: ca.pool},
+		ForceAttemptHTTP2: true,
+	}}
+	assert.Equal(t, "HTTP/2.0", getBody(t, client, "https://localhost:"+portOf(t, info.Addr)+"/proto"))
+}
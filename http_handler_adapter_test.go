@@ -36,6 +36,10 @@ func (s *stubContext) QueryInt(name string, defaultValue int) int { return defau
 func (s *stubContext) Queries() map[string]string                 { return map[string]string{} }
 func (s *stubContext) Body() []byte                               { return nil }
 func (s *stubContext) Bind(v any) error                           { return nil }
+func (s *stubContext) BindQuery(v any) error                      { return nil }
+func (s *stubContext) BindParams(v any) error                     { return nil }
+func (s *stubContext) BindHeaders(v any) error                    { return nil }
+func (s *stubContext) BindForm(v any) error                       { return nil }
 func (s *stubContext) Locals(key any, value ...any) any           { return nil }
 func (s *stubContext) LocalsMerge(key any, value map[string]any) map[string]any {
 	return value
@@ -3,6 +3,7 @@ package router
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +16,6 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/gofiber/utils"
 	"github.com/julienschmidt/httprouter"
@@ -51,6 +51,8 @@ var httpRouterPathConflictModeMu sync.Mutex
 var httpRouterPathConflictMode = map[*httprouter.Router]PathConflictMode{}
 var httpRouterNamedRoutePolicyMu sync.Mutex
 var httpRouterNamedRoutePolicy = map[*httprouter.Router]NamedRouteCollisionPolicy{}
+var httpRouterViewsMu sync.Mutex
+var httpRouterViews = map[*httprouter.Router]Views{}
 
 // WithHTTPRouterConflictPolicy configures conflict handling for NewHTTPServer.
 func WithHTTPRouterConflictPolicy(policy HTTPRouterConflictPolicy) func(*httprouter.Router) *httprouter.Router {
@@ -93,6 +95,16 @@ func WithHTTPRouterNamedRoutePolicy(policy NamedRouteCollisionPolicy) func(*http
 	}
 }
 
+// WithHTTPRouterViews configures the view engine used by Render for NewHTTPServer.
+func WithHTTPRouterViews(views Views) func(*httprouter.Router) *httprouter.Router {
+	return func(router *httprouter.Router) *httprouter.Router {
+		httpRouterViewsMu.Lock()
+		httpRouterViews[router] = views
+		httpRouterViewsMu.Unlock()
+		return router
+	}
+}
+
 func popHTTPRouterConflictPolicy(router *httprouter.Router) (HTTPRouterConflictPolicy, bool) {
 	httpRouterConflictPolicyMu.Lock()
 	defer httpRouterConflictPolicyMu.Unlock()
@@ -133,7 +145,18 @@ func popHTTPRouterNamedRoutePolicy(router *httprouter.Router) (NamedRouteCollisi
 	return policy, ok
 }
 
+func popHTTPRouterViews(router *httprouter.Router) (Views, bool) {
+	httpRouterViewsMu.Lock()
+	defer httpRouterViewsMu.Unlock()
+	views, ok := httpRouterViews[router]
+	if ok {
+		delete(httpRouterViews, router)
+	}
+	return views, ok
+}
+
 type HTTPServer struct {
+	lifecycle
 	mu                sync.Mutex
 	httpRouter        *httprouter.Router
 	server            *http.Server
@@ -148,6 +171,7 @@ type HTTPServer struct {
 	namedRoutePolicy  NamedRouteCollisionPolicy
 	notFoundHandler   http.Handler
 	methodNotAllowed  http.Handler
+	http2             *HTTP2Config
 }
 
 func NewHTTPServer(opts ...func(*httprouter.Router) *httprouter.Router) Server[*httprouter.Router] {
@@ -181,6 +205,8 @@ func NewHTTPServer(opts ...func(*httprouter.Router) *httprouter.Router) Server[*
 	if configured, ok := popHTTPRouterNamedRoutePolicy(router); ok {
 		namedRoutePolicy = configured.normalize()
 	}
+	views, _ := popHTTPRouterViews(router)
+	http2, _ := popHTTPRouterHTTP2(router)
 
 	return &HTTPServer{
 		httpRouter:       router,
@@ -191,7 +217,8 @@ func NewHTTPServer(opts ...func(*httprouter.Router) *httprouter.Router) Server[*
 		namedRoutePolicy: namedRoutePolicy,
 		notFoundHandler:  router.NotFound,
 		methodNotAllowed: router.MethodNotAllowed,
-		// views:      engine,
+		views:            views,
+		http2:            http2,
 	}
 }
 
@@ -229,6 +256,7 @@ func (a *HTTPServer) Router() Router[*httprouter.Router] {
 
 func (a *HTTPServer) WrapHandler(h HandlerFunc) any {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		a.Router()
 		c := newHTTPRouterContext(w, r, ps, a.views)
 		c.router = a.router
 		c.passLocalsToViews = a.passLocalsToViews
@@ -377,11 +405,18 @@ func pathMatchesPattern(pattern, path string) bool {
 	return true
 }
 
+// Init mounts the declared routes. It panics if a route hook rejects a route.
 func (a *HTTPServer) Init() {
+	if err := a.initialize(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *HTTPServer) initialize() (err error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if a.initialized {
-		return
+		return nil
 	}
 
 	if a.router == nil {
@@ -391,8 +426,10 @@ func (a *HTTPServer) Init() {
 	a.router.registerLateRoutes(a.router)
 	if !a.router.root.beginFinalization() {
 		a.initialized = true
-		return
+		return nil
 	}
+	// Route hooks run once the root lock is released.
+	defer func() { err = a.router.root.announceRoutes() }()
 	defer a.router.root.finishFinalization()
 
 	if a.strictRoutes {
@@ -401,57 +438,113 @@ func (a *HTTPServer) Init() {
 		}
 	}
 
-	if len(a.router.root.missHandlers) > 0 {
-		a.httpRouter.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if a.serveMissHandler(w, r) {
-				return
-			}
-			if a.notFoundHandler != nil {
-				a.notFoundHandler.ServeHTTP(w, r)
-				return
-			}
-			http.NotFound(w, r)
-		})
-		a.httpRouter.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if a.serveMissHandler(w, r) {
-				return
-			}
-			if a.methodNotAllowed != nil {
-				a.methodNotAllowed.ServeHTTP(w, r)
-				return
-			}
-			w.WriteHeader(http.StatusMethodNotAllowed)
+	// Routes added by a RouteMutation are matched once httprouter finds no
+	// route, ahead of the miss handlers.
+	missHandlers := len(a.router.root.missHandlers) > 0
+	// httprouter answers OPTIONS for declared paths itself, so hand those
+	// requests to the OPTIONS miss handler, such as the CORS preflight.
+	if a.router.missHandler(HTTPMethod(http.MethodOptions)) != nil {
+		a.httpRouter.GlobalOPTIONS = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a.serveMissHandler(w, r)
 		})
 	}
+	a.httpRouter.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.router.serveOverlay(w, r) || a.serveMissHandler(w, r) {
+			return
+		}
+		if a.notFoundHandler != nil {
+			a.notFoundHandler.ServeHTTP(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	})
+	a.httpRouter.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.router.serveOverlay(w, r) || a.serveMissHandler(w, r) {
+			return
+		}
+		if a.methodNotAllowed != nil {
+			a.methodNotAllowed.ServeHTTP(w, r)
+			return
+		}
+		if missHandlers {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	})
 
 	a.initialized = true
+	return nil
 }
 
+var (
+	_ LifecycleHooks = (*HTTPServer)(nil)
+	_ ListenerServer = (*HTTPServer)(nil)
+	_ TLSServer      = (*HTTPServer)(nil)
+)
+
 func (a *HTTPServer) Serve(address string) error {
+	if err := a.runStartup(context.Background(), a.prepare); err != nil {
+		return err
+	}
+	ln, err := listenTCP("tcp", address)
+	if err != nil {
+		return err
+	}
+	return a.ServeListener(ln)
+}
 
-	a.Init()
+// ServeListener serves the router on ln. See ListenerServer.
+func (a *HTTPServer) ServeListener(ln net.Listener) error {
+	if err := a.runStartup(context.Background(), a.prepare); err != nil {
+		return errors.Join(err, ln.Close())
+	}
+	if err := a.runListen(ln); err != nil {
+		return err
+	}
+	return a.httpServer().Serve(ln)
+}
 
+// ServeTLS serves the router over TLS on address. See TLSServer.
+func (a *HTTPServer) ServeTLS(address string, config TLSConfig) error {
+	return serveTLS(&a.lifecycle, a.prepare, a, "tcp", address, withHTTP2Protos(config))
+}
+
+func (a *HTTPServer) prepare() error {
+	if err := a.initialize(); err != nil {
+		return err
+	}
 	if a.views != nil {
-		if err := a.views.Load(); err != nil {
-			return err
-		}
+		return a.views.Load()
 	}
+	return nil
+}
 
-	srv := &http.Server{
-		Addr:              address,
-		Handler:           a.httpRouter,
-		ReadHeaderTimeout: 10 * time.Second,
+// httpServer returns the server shared by every listener.
+func (a *HTTPServer) httpServer() *http.Server {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.server == nil {
+		a.server = newNetHTTPServer(a.httpRouter, a.http2)
 	}
-
-	a.server = srv
-	return srv.ListenAndServe()
+	return a.server
 }
 
 func (a *HTTPServer) Shutdown(ctx context.Context) error {
-	if a.server != nil {
-		return a.server.Shutdown(ctx)
+	a.mu.Lock()
+	srv := a.server
+	a.mu.Unlock()
+	var err error
+	if srv != nil {
+		err = srv.Shutdown(ctx)
 	}
-	return nil
+	return errors.Join(err, a.runShutdown(ctx))
+}
+
+// OnRouteMounted adds a route hook. See LifecycleHooks.
+func (a *HTTPServer) OnRouteMounted(hook func(route RouteDefinition) error) {
+	a.Router()
+	a.router.root.addRouteHook(hook)
 }
 
 // HTTPRouter implements Router for httprouter
@@ -517,6 +610,7 @@ func (r *HTTPRouter) Group(prefix string) Router[*httprouter.Router] {
 			root:              r.root,
 			views:             r.views,
 			passLocalsToViews: r.passLocalsToViews,
+			routeScope:        r.routeScope,
 		},
 	}
 }
@@ -537,10 +631,51 @@ func (r *HTTPRouter) Mount(prefix string) Router[*httprouter.Router] {
 			root:              r.root,
 			views:             r.views,
 			passLocalsToViews: r.passLocalsToViews,
+			routeScope:        r.routeScope,
 		},
 	}
 }
 
+// Host returns a group whose routes only answer requests for hosts matching
+// pattern. See HostRouter.
+func (r *HTTPRouter) Host(pattern string) Router[*httprouter.Router] {
+	scope := r.routeScope
+	scope.host = mustCompileHostPattern(pattern)
+	return r.scoped(scope)
+}
+
+// Version returns a group whose routes belong to the API version name. See
+// VersionRouter.
+func (r *HTTPRouter) Version(name string, strategy VersionStrategy) Router[*httprouter.Router] {
+	g := r.scoped(r.routeScope)
+	g.enterVersion(name, strategy)
+	return g
+}
+
+// scoped returns a group with the prefix and middleware of r and scope.
+func (r *HTTPRouter) scoped(scope routeScope) *HTTPRouter {
+	g := r.Group("").(*HTTPRouter)
+	g.prefix = r.prefix
+	g.routeScope = scope
+	return g
+}
+
+func (r *HTTPRouter) withScope(scope routeScope) lateRouteRegistrar {
+	if scope == r.routeScope {
+		return r
+	}
+	return r.scoped(scope)
+}
+
+func (r *HTTPRouter) mountFallback(route *RouteDefinition) error {
+	g := r.scoped(route.routeScope)
+	if conflict := g.detectRouteConflict(route.Method, route.Path); conflict != nil {
+		return newRouteConflictError(route.Method, route.Path, conflict, r.conflictPolicy, r.pathConflictMode)
+	}
+	g.mountRoute(route)
+	return nil
+}
+
 func (r *HTTPRouter) WithGroup(path string, cb func(r Router[*httprouter.Router])) Router[*httprouter.Router] {
 	g := r.Group(path)
 	cb(g)
@@ -607,43 +742,118 @@ func (r *HTTPRouter) Handle(method HTTPMethod, pathStr string, handler HandlerFu
 	}
 
 	// Register final handler with httprouter.
-	r.router.Handle(string(method), fullPath, r.httpRouteHandler(route))
-	r.root.recordMounted(route)
+	r.mountRoute(route)
 	changed = true
 
 	return route
 }
 
-func (r *HTTPRouter) httpRouteHandler(route *RouteDefinition) httprouter.Handle {
-	return func(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
-		ctx := newHTTPRouterContext(w, req, params, r.views)
-		ctx.router = r
-		ctx.passLocalsToViews = r.passLocalsToViews
-		ctx.setHandlers(route.Handlers)
+// mountRoute registers route with httprouter, unless its path is already
+// mounted, and records it as mounted.
+func (r *HTTPRouter) mountRoute(route *RouteDefinition) {
+	_ = r.root.attachRoute(route, func(mount *routeMount) error {
+		r.router.Handle(string(mount.method), mount.path, r.httpRouteHandler(mount))
+		return nil
+	})
+	r.root.recordMounted(route)
+}
 
-		// Inject route context
-		goCtx := ctx.Context()
+// mountMutation mounts a route added by a RouteMutation. httprouter cannot
+// take routes while serving, and a removed route may still hold a conflicting
+// path, so new paths are matched in the overlay once httprouter finds no
+// route.
+func (r *HTTPRouter) mountMutation(route *RouteDefinition) error {
+	err := r.root.attachRoute(route, func(mount *routeMount) error {
+		mount.overlay = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	route.onSetName = func(route *RouteDefinition, name string) error {
+		return r.setPublicRouteName(route, name, nil)
+	}
+	r.root.recordMounted(route)
+	return nil
+}
 
-		// Always inject route name (empty string for unnamed routes)
-		goCtx = WithRouteName(goCtx, route.Name)
+func (r *HTTPRouter) mutationPath(path string) string {
+	return r.joinPath(r.prefix, path)
+}
 
-		// Always inject route parameters
-		paramMap := make(map[string]string, len(params))
-		for _, p := range params {
-			paramMap[p.Key] = p.Value
-		}
-		goCtx = WithRouteParams(goCtx, paramMap)
-		ctx.SetContext(goCtx)
+func (r *HTTPRouter) BeginRouteMutation() *RouteMutation {
+	return newRouteMutation(&r.BaseRouter, r)
+}
 
-		if err := ctx.Next(); err != nil {
-			r.logger.Error("handler chain error: %v", err)
-			if r.errorHandler == nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			if handleErr := r.errorHandler(ctx, err); handleErr != nil {
-				r.logger.Error("error handler failed: %v", handleErr)
+func (r *HTTPRouter) TryRemove(method HTTPMethod, path string) error {
+	return tryRemoveRoute(r.BeginRouteMutation(), method, path)
+}
+
+func (r *HTTPRouter) httpRouteHandler(mount *routeMount) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+		route, hostParams, ok := mount.resolve(routeRequest{
+			hostname: requestHostname(req.Host),
+			header:   req.Header.Get,
+			param:    params.ByName,
+		})
+		if !ok {
+			// httprouter has no next candidate, so a constraint, host or
+			// version mismatch is served like an unmatched path.
+			if r.router.NotFound != nil {
+				r.router.NotFound.ServeHTTP(w, req)
+			} else {
+				http.NotFound(w, req)
 			}
+			return
+		}
+		r.serveRoute(w, req, route, withHostParams(params, hostParams))
+	}
+}
+
+// serveOverlay serves req from the overlay and reports whether a route
+// matched.
+func (r *HTTPRouter) serveOverlay(w http.ResponseWriter, req *http.Request) bool {
+	route, params, hostParams, ok := r.root.matchOverlay(req.Method, req.URL.Path, routeRequest{
+		hostname: requestHostname(req.Host),
+		header:   req.Header.Get,
+	})
+	if !ok {
+		return false
+	}
+	r.serveRoute(w, req, route, withHostParams(params, hostParams))
+	return true
+}
+
+func (r *HTTPRouter) serveRoute(w http.ResponseWriter, req *http.Request, route *RouteDefinition, params httprouter.Params) {
+	ctx := newHTTPRouterContext(w, req, params, r.views)
+	ctx.router = r
+	ctx.passLocalsToViews = r.passLocalsToViews
+	ctx.setHandlers(route.Handlers)
+
+	// Inject route context
+	goCtx := ctx.Context()
+
+	// Always inject route name (empty string for unnamed routes)
+	goCtx = WithRouteName(goCtx, route.Name)
+
+	// Always inject route parameters
+	paramMap := make(map[string]string, len(params))
+	for _, p := range params {
+		paramMap[p.Key] = p.Value
+	}
+	goCtx = WithRouteParams(goCtx, paramMap)
+	goCtx = withRoute(goCtx, route)
+	ctx.SetContext(goCtx)
+	writeRouteHeaders(ctx, route)
+
+	if err := ctx.Next(); err != nil {
+		r.logger.Error("handler chain error: %v", err)
+		if r.errorHandler == nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if handleErr := r.errorHandler(ctx, err); handleErr != nil {
+			r.logger.Error("error handler failed: %v", handleErr)
 		}
 	}
 }
@@ -661,7 +871,7 @@ func (r *HTTPRouter) TryReplaceWithOptions(method HTTPMethod, pathStr string, ha
 	}
 
 	for _, route := range r.root.routes {
-		if route.Method != method || route.Path != fullPath {
+		if !r.isSameRoute(route, method, fullPath) {
 			continue
 		}
 		allMw := append([]namedMiddleware{}, route.middlewares...)
@@ -691,7 +901,7 @@ func (r *HTTPRouter) TryUpsertWithOptions(method HTTPMethod, pathStr string, han
 		return nil, false, newRegistrationError("upsert route", method, fullPath, r.root.registrationState(), ErrRouterSealed)
 	}
 	for _, route := range r.root.routes {
-		if route.Method == method && route.Path == fullPath {
+		if r.isSameRoute(route, method, fullPath) {
 			allMw := append([]namedMiddleware{}, route.middlewares...)
 			if options.ReplaceMiddleware {
 				allMw = append([]namedMiddleware{}, r.middlewares...)
@@ -722,15 +932,14 @@ func (r *HTTPRouter) TryUpsertWithOptions(method HTTPMethod, pathStr string, han
 	route.onSetName = func(route *RouteDefinition, name string) error {
 		return r.setPublicRouteName(route, name, nil)
 	}
-	r.router.Handle(string(method), fullPath, r.httpRouteHandler(route))
-	r.root.recordMounted(route)
+	r.mountRoute(route)
 	r.root.revision++
 	return route, false, nil
 }
 
 func (r *HTTPRouter) detectRouteConflict(method HTTPMethod, fullPath string) *routeConflict {
 	for _, route := range r.root.routes {
-		if route.Method != method {
+		if route.Method != method || r.isRouteVariant(route, fullPath) {
 			continue
 		}
 		if route.Path == fullPath {
@@ -821,32 +1030,50 @@ func (r *HTTPRouter) WebSocket(path string, config WebSocketConfig, handler func
 	route.onSetName = func(route *RouteDefinition, name string) error {
 		return r.setPublicRouteName(route, name, nil)
 	}
-	r.router.Handle("GET", fullPath, r.httpRouteHandler(route))
-	r.root.recordMounted(route)
+	r.mountRoute(route)
 	changed = true
 
 	return route
 }
 
+// RoutingCapabilities reports httprouter's radix-tree semantics: static and
+// wildcard siblings conflict and catch-alls cannot share a prefix.
+func (r *HTTPRouter) RoutingCapabilities() RoutingCapabilities {
+	caps := r.BaseRouter.RoutingCapabilities()
+	caps.PathConflictMode = r.pathConflictMode.normalize()
+	caps.CatchAllSiblings = false
+	caps.OrderIndependent = true
+	return caps
+}
+
 func (r *HTTPRouter) PrintRoutes() {
 	r.BaseRouter.PrintRoutes()
 }
 
 func (r *HTTPRouter) ValidateRoutes() []error {
 	routes := collectRoutesForValidation(&r.BaseRouter)
-	errs := ValidateRouteDefinitionsWithOptions(routes, RouteValidationOptions{
+	errs := ValidateRouteDefinitionsWithOptions(routes, r.routeValidationOptions())
+	if r.namedRoutePolicy.normalize() == NamedRouteCollisionPolicyError {
+		errs = append(errs, r.namedRouteConflicts()...)
+	}
+	return errs
+}
+
+func (r *HTTPRouter) routeValidationOptions() RouteValidationOptions {
+	return RouteValidationOptions{
 		PathConflictMode:         r.pathConflictMode,
 		EnforceCatchAllConflicts: true,
 		EnforceRouteLints:        false,
 		NamedRoutePolicy:         r.namedRoutePolicy,
-	})
-	if r.namedRoutePolicy.normalize() == NamedRouteCollisionPolicyError {
-		errs = append(errs, r.namedRouteConflicts()...)
 	}
-	return errs
 }
 
-// httpRouterContext implements Context for httprouter
+// routeLookup resolves named routes for redirects from a net/http context.
+type routeLookup interface {
+	GetRoute(name string) *RouteDefinition
+}
+
+// httpRouterContext implements Context for the net/http based adapters
 type httpRouterContext struct {
 	w                 http.ResponseWriter
 	r                 *http.Request
@@ -857,7 +1084,8 @@ type httpRouterContext struct {
 	views             Views
 	passLocalsToViews bool
 	locals            ViewContext
-	router            *HTTPRouter
+	router            routeLookup
+	adapter           string
 	written           bool
 	committed         bool
 	statusCode        int
@@ -886,6 +1114,11 @@ func (c *httpRouterContext) Request() *http.Request {
 	return c.r
 }
 
+// TLSConnectionState returns the TLS state of the request. See TLSContext.
+func (c *httpRouterContext) TLSConnectionState() *tls.ConnectionState {
+	return c.r.TLS
+}
+
 func (c *httpRouterContext) Response() http.ResponseWriter {
 	return c.w
 }
@@ -1075,6 +1308,7 @@ func (c *httpRouterContext) Redirect(location string, status ...int) error {
 		code = status[0]
 	}
 	http.Redirect(c.w, c.r, location, code)
+	c.markHTTPResponse(code, true, 0, false)
 	return nil
 }
 
@@ -1085,30 +1319,21 @@ func (c *httpRouterContext) RedirectToRoute(routeName string, params ViewContext
 		return fmt.Errorf("route not found: %s", routeName)
 	}
 
-	path := route.Path
-
-	// replace :param placeholders
-	for key, val := range params {
-		if key == "queries" {
-			continue
-		}
-		placeholder := ":" + key
-		path = strings.ReplaceAll(path, placeholder, fmt.Sprintf("%v", val))
+	built, err := buildRouteURL(route, params, nil)
+	if err != nil {
+		return err
 	}
 
-	// handle "queries" as a map that becomes a query string
+	// handle "queries" as a map that becomes a query string; other params
+	// only fill the path
+	built.query = url.Values{}
 	if qs, ok := params["queries"].(map[string]string); ok {
-		q := url.Values{}
 		for k, v := range qs {
-			q.Set(k, v)
-		}
-		queryString := q.Encode()
-		if queryString != "" {
-			path += "?" + queryString
+			built.query.Set(k, v)
 		}
 	}
 
-	return c.Redirect(path, status...)
+	return c.Redirect(built.pathAndQuery(), status...)
 }
 
 // RedirectBack attempts to redirect to the 'Referer' header, falling back
@@ -1217,7 +1442,8 @@ func (c *httpRouterContext) SendStream(r io.Reader) error {
 	if r == nil {
 		return c.NoContent(http.StatusNoContent)
 	}
-	n, err := io.Copy(c.w, r)
+	// Flush each chunk, so streams such as SSE are not held in buffers.
+	n, err := io.Copy(flushWriter{w: c.w, rc: http.NewResponseController(c.w)}, r)
 	if err == nil {
 		c.markHTTPResponse(0, true, n, true)
 	}
@@ -1259,15 +1485,23 @@ func (c *httpRouterContext) NoContent(code int) error {
 }
 
 func (c *httpRouterContext) Bind(v any) error {
-	if v == nil {
-		return fmt.Errorf("bind: nil interface provided")
-	}
+	return bindBody(c, v)
+}
 
-	if c.r.Body == nil {
-		return fmt.Errorf("bind: request body is nil")
-	}
+func (c *httpRouterContext) BindQuery(v any) error {
+	return bindQuery(c, v)
+}
+
+func (c *httpRouterContext) BindParams(v any) error {
+	return bindParams(c, v)
+}
+
+func (c *httpRouterContext) BindHeaders(v any) error {
+	return bindHeaders(c, v)
+}
 
-	return json.NewDecoder(c.r.Body).Decode(v)
+func (c *httpRouterContext) BindForm(v any) error {
+	return bindForm(c, v)
 }
 
 func (c *httpRouterContext) SetContext(ctx context.Context) {
@@ -1415,11 +1649,19 @@ func (c *httpRouterContext) GetBool(key string, def bool) bool {
 }
 
 func (c *httpRouterContext) Next() error {
+	handler, ok := c.nextHandler()
+	if !ok {
+		return nil
+	}
+	return handler(c)
+}
+
+func (c *httpRouterContext) nextHandler() (HandlerFunc, bool) {
 	c.index++
 	if c.index >= len(c.handlers) {
-		return nil
+		return nil, false
 	}
-	return c.handlers[c.index].Handler(c)
+	return c.handlers[c.index].Handler, true
 }
 
 // RouteName returns the route name from context
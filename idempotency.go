@@ -0,0 +1,275 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HeaderIdempotencyKey carries the client key that makes retries of an unsafe
+// request safe.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// IdempotencyPolicy marks a route as protected by the Idempotency middleware,
+// whatever its method, and documents the Idempotency-Key header in OpenAPI.
+type IdempotencyPolicy struct {
+	// Required rejects requests without a key with 400 Bad Request.
+	Required bool `json:"required"`
+	// TTL overrides how long the responses of the route are kept.
+	TTL time.Duration `json:"ttl,omitempty"`
+}
+
+// IdempotencyRecord is the state of a key. Response is nil while the first
+// request with the key is in flight. Token identifies the reservation made by
+// that request.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Token       string
+	Response    *CapturedResponse
+	ExpiresAt   time.Time
+}
+
+// IdempotencyStore keeps the records of the Idempotency middleware. Implement
+// it over a shared backend, such as Redis, when retries may reach another
+// instance. Begin must reserve a key atomically, and Complete and Release
+// must only change a key still held by the given token, so a request that
+// outlived its reservation cannot overwrite a newer one.
+type IdempotencyStore interface {
+	// Begin reserves key for a request with fingerprint until now plus ttl,
+	// and returns the new record, with a unique Token, and true. When the key
+	// is already held it returns the held record and false.
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration, now time.Time) (*IdempotencyRecord, bool, error)
+	// Complete stores the response of the request holding key with token
+	// until now plus ttl. It stores nothing if the reservation was lost.
+	Complete(ctx context.Context, key, token string, response *CapturedResponse, ttl time.Duration, now time.Time) error
+	// Release frees key, if still held with token, so the request can be
+	// retried.
+	Release(ctx context.Context, key, token string) error
+}
+
+// IdempotencyConfig configures the Idempotency middleware.
+type IdempotencyConfig struct {
+	Skip func(Context) bool
+	// Methods lists the methods protected on every route. Defaults to POST
+	// and PATCH. Routes with an IdempotencyPolicy are protected whatever
+	// their method.
+	Methods []string
+	// TTL is how long responses are replayed. Defaults to 24 hours.
+	TTL time.Duration
+	// LeaseTTL is how long a key stays reserved while its first request
+	// runs, so a crashed process does not hold keys for the whole TTL. A
+	// request outliving its lease cannot store its response, and retries
+	// run again. Defaults to one minute.
+	LeaseTTL time.Duration
+	// Scope returns the client a key belongs to, so clients cannot replay
+	// each other's responses. Defaults to RateLimitByIP; use the
+	// authenticated user, such as RateLimitByLocal("user_id"), when clients
+	// share an address.
+	Scope func(Context) string
+	// Store defaults to a new InMemoryIdempotencyStore.
+	Store IdempotencyStore
+	// MaxBodySize is the largest response kept, in bytes. Defaults to
+	// DefaultMaxCapturedBodySize. Protected routes must not stream.
+	MaxBodySize int64
+	// Logger logs keys that could not be released after a failed request.
+	Logger Logger
+}
+
+// SetIdempotency protects a single route with the Idempotency middleware and
+// documents its Idempotency-Key header in OpenAPI.
+func SetIdempotency(ri RouteInfo, policy IdempotencyPolicy) RouteInfo {
+	if route, ok := ri.(*RouteDefinition); ok {
+		route.Idempotency = &policy
+	}
+	return ri
+}
+
+// Idempotency returns a middleware that honours the Idempotency-Key header of
+// unsafe requests:
+//
+//	app.Router().Use(router.Idempotency(router.IdempotencyConfig{
+//		Scope: router.RateLimitByLocal("user_id"),
+//	}))
+//	router.SetIdempotency(r.Post("/payments", createPayment), router.IdempotencyPolicy{Required: true})
+//
+// The first request with a key runs and its response is kept. Retries with
+// the same key and payload get that response again, marked with
+// Idempotent-Replayed, while retries arriving before it completes get 409
+// Conflict and retries with another payload 422 Unprocessable Entity. Handler
+// errors and panics release the key, so the request can be retried.
+func Idempotency(config ...IdempotencyConfig) MiddlewareFunc {
+	var cfg IdempotencyConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = time.Minute
+	}
+	if cfg.Scope == nil {
+		cfg.Scope = RateLimitByIP
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewInMemoryIdempotencyStore()
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			var policy IdempotencyPolicy
+			if route, ok := routeFromContext(c.Context()); ok && route.Idempotency != nil {
+				policy = *route.Idempotency
+			} else if !slices.Contains(cfg.Methods, c.Method()) {
+				return next(c)
+			}
+			ttl := cfg.TTL
+			if policy.TTL > 0 {
+				ttl = policy.TTL
+			}
+
+			key := c.Header(HeaderIdempotencyKey)
+			switch {
+			case key == "" && policy.Required:
+				return NewBadRequestError("Idempotency-Key header is required")
+			case key == "":
+				return next(c)
+			case len(key) > 255:
+				return NewBadRequestError("Idempotency-Key header is too long", map[string]any{"max_length": 255})
+			}
+			key = cfg.Scope(c) + "|" + key
+
+			fingerprint := idempotencyFingerprint(c)
+			record, acquired, err := cfg.Store.Begin(c.Context(), key, fingerprint, cfg.LeaseTTL, time.Now())
+			if err != nil {
+				return NewInternalError(err, "idempotency store failed")
+			}
+			if !acquired {
+				switch {
+				case record.Fingerprint != fingerprint:
+					return NewUnprocessableEntityError("Idempotency-Key was used with another request")
+				case record.Response == nil:
+					c.SetHeader("Retry-After", "1")
+					return NewConflictError("a request with this Idempotency-Key is in progress")
+				}
+				c.SetHeader("Idempotent-Replayed", "true")
+				return ReplayCapturedResponse(c, record.Response)
+			}
+
+			return runIdempotent(c, cfg, key, record.Token, ttl, next)
+		}
+	}
+}
+
+// runIdempotent runs the request holding the reservation of key with token and
+// stores its response. The reservation is released when the request fails or
+// panics, so it can be retried at once; a panic carries on afterwards.
+func runIdempotent(c Context, cfg IdempotencyConfig, key, token string, ttl time.Duration, next HandlerFunc) error {
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := cfg.Store.Release(context.WithoutCancel(c.Context()), key, token); err != nil && cfg.Logger != nil {
+			cfg.Logger.Warn("idempotency key release failed: %v", err)
+		}
+	}()
+
+	captured, err := CaptureResponse(c, cfg.MaxBodySize, next)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Store.Complete(c.Context(), key, token, captured, ttl, time.Now()); err != nil {
+		return NewInternalError(err, "idempotency store failed")
+	}
+	completed = true
+	return ReplayCapturedResponse(c, captured)
+}
+
+// idempotencyFingerprint identifies the payload of a request, so a key cannot
+// be reused for another one.
+func idempotencyFingerprint(c Context) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// InMemoryIdempotencyStore keeps records in process memory. Expired records
+// are dropped once a minute.
+type InMemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]*IdempotencyRecord
+	nextSweep time.Time
+}
+
+// NewInMemoryIdempotencyStore returns an empty in-memory store.
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{records: make(map[string]*IdempotencyRecord)}
+}
+
+var _ IdempotencyStore = (*InMemoryIdempotencyStore)(nil)
+
+// Begin reserves key. See IdempotencyStore.
+func (s *InMemoryIdempotencyStore) Begin(_ context.Context, key, fingerprint string, ttl time.Duration, now time.Time) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		held := *record
+		return &held, false, nil
+	}
+	record := &IdempotencyRecord{Fingerprint: fingerprint, Token: uuid.NewString(), ExpiresAt: now.Add(ttl)}
+	s.records[key] = record
+	reserved := *record
+	return &reserved, true, nil
+}
+
+// Complete stores the response for key. See IdempotencyStore.
+func (s *InMemoryIdempotencyStore) Complete(_ context.Context, key, token string, response *CapturedResponse, ttl time.Duration, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || record.Token != token {
+		// The reservation expired while the request ran.
+		return nil
+	}
+	record.Response = response
+	record.ExpiresAt = now.Add(ttl)
+	return nil
+}
+
+// Release frees key. See IdempotencyStore.
+func (s *InMemoryIdempotencyStore) Release(_ context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && record.Token == token {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+func (s *InMemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(time.Minute)
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func TestIdempotency_ReplaysConflictsAndRejects(t *testing.T) {
+	servers := map[string]func(*idempotencyHandlers) *routertest.Client{
+		"fiber": func(h *idempotencyHandlers) *routertest.Client {
+			app := router.NewFiberAdapter(func(*fiber.App) *fiber.App {
+				return fiber.New(fiber.Config{
+					DisableStartupMessage: true,
+					ProxyHeader:           fiber.HeaderXForwardedFor,
+					ErrorHandler:          router.DefaultFiberErrorHandler(router.FiberErrorHandlerConfig{APIPrefix: "/"}),
+				})
+			})
+			idempotencyRoutes(app.Router(), h)
+			return routertest.NewClient(app)
+		},
+		"httprouter": func(h *idempotencyHandlers) *routertest.Client {
+			app := router.NewHTTPServer()
+			idempotencyRoutes(app.Router(), h)
+			return routertest.NewClient(app)
+		},
+		"servemux": func(h *idempotencyHandlers) *routertest.Client {
+			app := router.NewServeMuxServer()
+			idempotencyRoutes(app.Router(), h)
+			return routertest.NewClient(app)
+		},
+	}
+
+	for name, newClient := range servers {
+		t.Run(name, func(t *testing.T) {
+			h := &idempotencyHandlers{entered: make(chan struct{}), release: make(chan struct{})}
+			client := newClient(h)
+			post := func(key, body string) *routertest.RequestBuilder {
+				req := client.Post("/payments").Body(strings.NewReader(body), "text/plain")
+				if key != "" {
+					req.Header(router.HeaderIdempotencyKey, key)
+				}
+				return req
+			}
+
+			post("k1", "amount=10").Do(t).AssertStatus(http.StatusCreated).AssertJSONPath("id", 1)
+			post("k1", "amount=10").Do(t).
+				AssertStatus(http.StatusCreated).
+				AssertJSONPath("id", 1).
+				AssertHeader("Idempotent-Replayed", "true")
+			post("k1", "amount=10").Header("X-Forwarded-For", "203.0.113.9").Do(t).
+				AssertHeader("Idempotent-Replayed", "").
+				AssertJSONPath("id", 2)
+
+			post("k1", "amount=99").Do(t).AssertStatus(http.StatusUnprocessableEntity)
+			post("", "amount=10").Do(t).AssertStatus(http.StatusBadRequest)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				post("k2", "slow").Do(t).AssertStatus(http.StatusCreated)
+			}()
+			<-h.entered
+			post("k2", "slow").Do(t).AssertStatus(http.StatusConflict).AssertHeader("Retry-After", "1")
+			close(h.release)
+			<-done
+
+			post("k3", "fail").Do(t).AssertStatus(http.StatusInternalServerError)
+			post("k3", "fail").Do(t).AssertStatus(http.StatusInternalServerError)
+			post("k5", "panic").Do(t).AssertStatus(http.StatusInternalServerError)
+			post("k5", "panic").Do(t).AssertStatus(http.StatusInternalServerError)
+
+			patch := func(key string) string {
+				req := client.Patch("/orders/7")
+				if key != "" {
+					req.Header(router.HeaderIdempotencyKey, key)
+				}
+				return req.Do(t).String()
+			}
+			first := patch("k4")
+			assert.Equal(t, first, patch("k4"), "PATCH requests are protected by default")
+			assert.NotEqual(t, first, patch(""))
+		})
+	}
+}
+
+func TestIdempotency_DocumentsRequiredKey(t *testing.T) {
+	app := router.NewHTTPServer()
+	r := app.Router()
+	idempotencyRoutes(r, &idempotencyHandlers{})
+
+	doc := router.NewOpenAPIRenderer().AppenRouteInfo(r.Routes()).GenerateOpenAPI()
+	op := doc["paths"].(map[string]any)["/payments"].(map[string]any)["post"].(map[string]any)
+	require.Len(t, op["parameters"], 1)
+	param := op["parameters"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Idempotency-Key", param["name"])
+	assert.Equal(t, "header", param["in"])
+	assert.Equal(t, true, param["required"])
+}
+
+func TestIdempotency_LeasesReservations(t *testing.T) {
+	store := &idempotencyTTLStore{IdempotencyStore: router.NewInMemoryIdempotencyStore()}
+	app := router.NewHTTPServer()
+	app.Router().Use(router.Idempotency(router.IdempotencyConfig{Store: store, TTL: time.Hour, LeaseTTL: 5 * time.Second}))
+	app.Router().Post("/payments", func(c router.Context) error { return c.SendString("paid") })
+
+	routertest.NewClient(app).Post("/payments").Header(router.HeaderIdempotencyKey, "k1").Do(t).AssertBody("paid")
+	assert.Equal(t, []time.Duration{5 * time.Second, time.Hour}, store.ttls, "reservations use the lease, responses the TTL")
+}
+
+func TestInMemoryIdempotencyStore_ExpiresRecords(t *testing.T) {
+	ctx := context.Background()
+	store := router.NewInMemoryIdempotencyStore()
+	now := time.Now()
+	response := func(body string) *router.CapturedResponse {
+		return &router.CapturedResponse{StatusCode: http.StatusCreated, Body: []byte(body)}
+	}
+
+	first, acquired, err := store.Begin(ctx, "k1", "fp", time.Minute, now)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NotEmpty(t, first.Token)
+	require.NoError(t, store.Complete(ctx, "k1", first.Token, response("first"), time.Hour, now))
+
+	held, acquired, err := store.Begin(ctx, "k1", "fp", time.Minute, now.Add(59*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "first", string(held.Response.Body), "responses are kept for the completion TTL")
+
+	_, acquired, err = store.Begin(ctx, "k1", "fp", time.Minute, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, acquired, "expired responses free the key")
+
+	stale, _, err := store.Begin(ctx, "k2", "fp", time.Minute, now)
+	require.NoError(t, err)
+	later := now.Add(2 * time.Minute)
+	fresh, acquired, err := store.Begin(ctx, "k2", "fp", time.Minute, later)
+	require.NoError(t, err)
+	require.True(t, acquired, "expired reservations free the key")
+	assert.NotEqual(t, stale.Token, fresh.Token)
+
+	require.NoError(t, store.Complete(ctx, "k2", stale.Token, response("stale"), time.Hour, later))
+	require.NoError(t, store.Release(ctx, "k2", stale.Token))
+	held, acquired, err = store.Begin(ctx, "k2", "fp", time.Minute, later)
+	require.NoError(t, err)
+	assert.False(t, acquired, "the expired request cannot release the new reservation")
+	assert.Nil(t, held.Response, "the expired request cannot complete the new reservation")
+
+	require.NoError(t, store.Complete(ctx, "k2", fresh.Token, response("fresh"), time.Hour, later))
+	held, _, err = store.Begin(ctx, "k2", "fp", time.Minute, later)
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", string(held.Response.Body))
+}
+
+type idempotencyHandlers struct {
+	payments atomic.Int32
+	entered  chan struct{}
+	release  chan struct{}
+}
+
+func idempotencyRoutes[T any](r router.Router[T], h *idempotencyHandlers) {
+	r.Use(recoverPanics)
+	r.Use(router.Idempotency())
+	router.SetIdempotency(r.Post("/payments", func(c router.Context) error {
+		switch string(c.Body()) {
+		case "slow":
+			h.entered <- struct{}{}
+			<-h.release
+		case "fail":
+			return errors.New("gateway unavailable")
+		case "panic":
+			panic("gateway crashed")
+		}
+		return c.JSON(http.StatusCreated, map[string]any{"id": h.payments.Add(1)})
+	}), router.IdempotencyPolicy{Required: true})
+	r.Patch("/orders/:id", func(c router.Context) error {
+		return c.SendString("order " + c.Param("id") + " #" + strconv.Itoa(int(h.payments.Add(1))))
+	})
+}
+
+// recoverPanics turns handler panics into errors, as a recover middleware
+// would in production.
+func recoverPanics(next router.HandlerFunc) router.HandlerFunc {
+	return func(c router.Context) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = router.NewInternalError(fmt.Errorf("panic: %v", recovered), "handler panicked")
+			}
+		}()
+		return next(c)
+	}
+}
+
+// idempotencyTTLStore records the TTLs the middleware asks for.
+type idempotencyTTLStore struct {
+	router.IdempotencyStore
+	ttls []time.Duration
+}
+
+func (s *idempotencyTTLStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration, now time.Time) (*router.IdempotencyRecord, bool, error) {
+	s.ttls = append(s.ttls, ttl)
+	return s.IdempotencyStore.Begin(ctx, key, fingerprint, ttl, now)
+}
+
+func (s *idempotencyTTLStore) Complete(ctx context.Context, key, token string, response *router.CapturedResponse, ttl time.Duration, now time.Time) error {
+	s.ttls = append(s.ttls, ttl)
+	return s.IdempotencyStore.Complete(ctx, key, token, response, ttl, now)
+}
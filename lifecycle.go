@@ -0,0 +1,170 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+)
+
+// ListenInfo describes a listener a server bound, as passed to OnListen hooks.
+type ListenInfo struct {
+	// Addr is the bound address. With ":0" it carries the port the system
+	// picked.
+	Addr net.Addr
+	// TLS reports whether the listener serves TLS.
+	TLS bool
+}
+
+// LifecycleHooks is implemented by servers that run hooks around Serve and
+// Shutdown. The Fiber, httprouter and ServeMux adapters implement it:
+//
+//	hooks := app.(router.LifecycleHooks)
+//	hooks.OnStartup(func(ctx context.Context) error { return cache.Warm(ctx) })
+//	hooks.OnListen(func(info router.ListenInfo) error {
+//		log.Printf("listening on %s", info.Addr)
+//		return nil
+//	})
+//	hooks.OnShutdown(func(ctx context.Context) error { return db.Close() })
+type LifecycleHooks interface {
+	// OnStartup adds a hook that runs once, after Init and before the server
+	// binds its address or serves its first listener. Hooks run in the order
+	// added; an error stops the rest and is returned by Serve.
+	OnStartup(hook func(ctx context.Context) error)
+	// OnListen adds a hook that runs for each listener once it is bound and
+	// before requests are accepted. An error closes the listener and is returned
+	// by Serve.
+	OnListen(hook func(info ListenInfo) error)
+	// OnShutdown adds a hook that runs after the server stopped, with the
+	// Shutdown context. Hooks run in reverse order, so resources close in
+	// reverse dependency order, and their errors are joined into the error
+	// Shutdown returns.
+	OnShutdown(hook func(ctx context.Context) error)
+	// OnRouteMounted adds a hook that receives a copy of each mounted route.
+	// Hooks do not run as routes are declared, since names and metadata are
+	// set after the declaration returns. They run in a batch once the routes
+	// are mounted: declared routes at the end of Init, and routes added by a
+	// RouteMutation once it commits. Routes mounted before the hook was added
+	// are not replayed.
+	//
+	// Hook errors are joined. At Init they abort startup: Serve returns them
+	// before listening, and Init called directly panics, as with strict route
+	// validation. A committed mutation is already serving its routes, so
+	// Commit returns the errors with its diff and the caller decides whether
+	// to revert.
+	OnRouteMounted(hook func(route RouteDefinition) error)
+}
+
+// lifecycle stores the hooks of a server. The adapters embed it.
+type lifecycle struct {
+	hooksMu       sync.Mutex
+	startupHooks  []func(context.Context) error
+	listenHooks   []func(ListenInfo) error
+	shutdownHooks []func(context.Context) error
+	startupOnce   sync.Once
+	startupErr    error
+	shutdownOnce  sync.Once
+	shutdownErr   error
+}
+
+func (l *lifecycle) OnStartup(hook func(ctx context.Context) error) {
+	l.hooksMu.Lock()
+	defer l.hooksMu.Unlock()
+	l.startupHooks = append(l.startupHooks, hook)
+}
+
+func (l *lifecycle) OnListen(hook func(info ListenInfo) error) {
+	l.hooksMu.Lock()
+	defer l.hooksMu.Unlock()
+	l.listenHooks = append(l.listenHooks, hook)
+}
+
+func (l *lifecycle) OnShutdown(hook func(ctx context.Context) error) {
+	l.hooksMu.Lock()
+	defer l.hooksMu.Unlock()
+	l.shutdownHooks = append(l.shutdownHooks, hook)
+}
+
+// runStartup runs prepare and then the startup hooks once, and returns their
+// error on every call.
+func (l *lifecycle) runStartup(ctx context.Context, prepare func() error) error {
+	l.startupOnce.Do(func() {
+		if err := prepare(); err != nil {
+			l.startupErr = err
+			return
+		}
+		l.hooksMu.Lock()
+		hooks := slices.Clone(l.startupHooks)
+		l.hooksMu.Unlock()
+		for _, hook := range hooks {
+			if err := hook(ctx); err != nil {
+				l.startupErr = err
+				return
+			}
+		}
+	})
+	return l.startupErr
+}
+
+// runListen runs the listen hooks for ln and closes ln when one fails.
+func (l *lifecycle) runListen(ln net.Listener) error {
+	l.hooksMu.Lock()
+	hooks := slices.Clone(l.listenHooks)
+	l.hooksMu.Unlock()
+	_, isTLS := ln.(*tlsListener)
+	info := ListenInfo{Addr: ln.Addr(), TLS: isTLS}
+	for _, hook := range hooks {
+		if err := hook(info); err != nil {
+			return errors.Join(err, ln.Close())
+		}
+	}
+	return nil
+}
+
+// runShutdown runs the shutdown hooks once, in reverse order, and joins
+// their errors.
+func (l *lifecycle) runShutdown(ctx context.Context) error {
+	l.shutdownOnce.Do(func() {
+		l.hooksMu.Lock()
+		hooks := slices.Clone(l.shutdownHooks)
+		l.hooksMu.Unlock()
+		var errs []error
+		for _, hook := range slices.Backward(hooks) {
+			errs = append(errs, hook(ctx))
+		}
+		l.shutdownErr = errors.Join(errs...)
+	})
+	return l.shutdownErr
+}
+
+// addRouteHook adds an OnRouteMounted hook to root.
+func (root *routerRoot) addRouteHook(hook func(RouteDefinition) error) {
+	root.registrationMu.Lock()
+	defer root.registrationMu.Unlock()
+	root.routeHooks = append(root.routeHooks, hook)
+}
+
+// announceRoutes passes the routes mounted since the last call to the route
+// hooks and joins their errors. Callers must not hold the root lock, so hooks
+// may inspect the router.
+func (root *routerRoot) announceRoutes() error {
+	root.registrationMu.Lock()
+	routes := root.announced
+	root.announced = nil
+	hooks := slices.Clone(root.routeHooks)
+	root.registrationMu.Unlock()
+	if len(hooks) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, route := range cloneRouteDefinitions(routes) {
+		for _, hook := range hooks {
+			if err := hook(route); err != nil {
+				errs = append(errs, fmt.Errorf("route %s %s: %w", route.Method, route.Path, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
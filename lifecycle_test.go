@@ -0,0 +1,200 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+)
+
+type lifecycleServer interface {
+	router.LifecycleHooks
+	Serve(address string) error
+	Shutdown(ctx context.Context) error
+}
+
+func lifecycleAdapters() []struct {
+	name   string
+	server func() lifecycleServer
+} {
+	return []struct {
+		name   string
+		server func() lifecycleServer
+	}{
+		{"fiber", func() lifecycleServer {
+			app := router.NewFiberAdapter(func(*fiber.App) *fiber.App {
+				return fiber.New(fiber.Config{DisableStartupMessage: true})
+			})
+			app.Router().Get("/ping", ping).SetName("ping")
+			return app.(lifecycleServer)
+		}},
+		{"httprouter", func() lifecycleServer {
+			app := router.NewHTTPServer()
+			app.Router().Get("/ping", ping).SetName("ping")
+			return app.(lifecycleServer)
+		}},
+		{"servemux", func() lifecycleServer {
+			app := router.NewServeMuxServer()
+			app.Router().Get("/ping", ping).SetName("ping")
+			return app.(lifecycleServer)
+		}},
+	}
+}
+
+func ping(c router.Context) error {
+	return c.SendString("pong")
+}
+
+func TestLifecycleHooks_RunAroundServeAndShutdown(t *testing.T) {
+	for _, tc := range lifecycleAdapters() {
+		t.Run(tc.name, func(t *testing.T) {
+			app := tc.server()
+
+			var (
+				mu     sync.Mutex
+				events []string
+			)
+			record := func(event string) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, event)
+			}
+			app.OnRouteMounted(func(route router.RouteDefinition) error {
+				record("route " + route.Name)
+				return nil
+			})
+			app.OnStartup(func(context.Context) error {
+				record("startup")
+				return nil
+			})
+			listening := make(chan string, 1)
+			app.OnListen(func(info router.ListenInfo) error {
+				record("listen")
+				listening <- info.Addr.String()
+				return nil
+			})
+			app.OnShutdown(func(context.Context) error {
+				record("close db")
+				return nil
+			})
+			app.OnShutdown(func(context.Context) error {
+				record("close cache")
+				return errors.New("cache busy")
+			})
+
+			served := make(chan error, 1)
+			go func() { served <- app.Serve("127.0.0.1:0") }()
+			addr := <-listening
+
+			res, err := http.Get("http://" + addr + "/ping")
+			require.NoError(t, err)
+			body, err := io.ReadAll(res.Body)
+			res.Body.Close()
+			require.NoError(t, err)
+			assert.Equal(t, "pong", string(body))
+
+			err = app.Shutdown(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "cache busy")
+			select {
+			case err := <-served:
+				if err != nil {
+					assert.ErrorIs(t, err, http.ErrServerClosed)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Serve did not return after Shutdown")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, []string{"route ping", "startup", "listen", "close cache", "close db"}, events)
+		})
+	}
+}
+
+func TestLifecycleHooks_StartupAndListenErrorsStopServe(t *testing.T) {
+	for _, tc := range lifecycleAdapters() {
+		t.Run(tc.name, func(t *testing.T) {
+			app := tc.server()
+			listened := false
+			app.OnStartup(func(context.Context) error { return errors.New("warmup failed") })
+			app.OnListen(func(router.ListenInfo) error {
+				listened = true
+				return nil
+			})
+			err := app.Serve("127.0.0.1:0")
+			require.Error(t, err)
+			assert.Equal(t, "warmup failed", err.Error())
+			assert.False(t, listened)
+
+			app = tc.server()
+			app.OnListen(func(router.ListenInfo) error { return errors.New("port not allowed") })
+			err = app.Serve("127.0.0.1:0")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "port not allowed")
+
+			app = tc.server()
+			started := false
+			app.OnRouteMounted(func(route router.RouteDefinition) error {
+				return fmt.Errorf("%s has no owner", route.Name)
+			})
+			app.OnStartup(func(context.Context) error {
+				started = true
+				return nil
+			})
+			err = app.Serve("127.0.0.1:0")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "GET /ping: ping has no owner")
+			assert.False(t, started)
+		})
+	}
+}
+
+func TestLifecycleHooks_RouteMountedOnMutation(t *testing.T) {
+	app := router.NewHTTPServer()
+	app.Router().Get("/users", ping).SetName("users.index")
+
+	var names []string
+	app.(router.LifecycleHooks).OnRouteMounted(func(route router.RouteDefinition) error {
+		names = append(names, string(route.Method)+" "+route.Path+" "+route.Name)
+		if route.Name == "" {
+			return errors.New("unnamed route")
+		}
+		return nil
+	})
+	app.Init()
+	assert.Equal(t, []string{"GET /users users.index"}, names)
+
+	m := app.Router().(router.RouteTransactor).BeginRouteMutation()
+	m.Add(router.GET, "/users/:id", ping).SetName("users.show")
+	_, err := m.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET /users users.index", "GET /users/:id users.show"}, names)
+
+	// The routes are live by the time the hooks run, so the diff comes back
+	// with the error.
+	m = app.Router().(router.RouteTransactor).BeginRouteMutation()
+	m.Add(router.GET, "/health", ping)
+	diff, err := m.Commit()
+	require.ErrorContains(t, err, "GET /health: unnamed route")
+	assert.Len(t, diff.Added, 1)
+}
+
+func TestLifecycleHooks_RouteMountedErrorPanicsInit(t *testing.T) {
+	app := router.NewServeMuxServer()
+	app.Router().Get("/ping", ping)
+	app.(router.LifecycleHooks).OnRouteMounted(func(router.RouteDefinition) error {
+		return errors.New("rejected")
+	})
+	assert.PanicsWithError(t, "route GET /ping: rejected", app.Init)
+}
@@ -0,0 +1,179 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ListenerServer is implemented by servers that serve on a listener the
+// caller created, such as a Unix socket or an inherited file descriptor. The
+// Fiber, httprouter and ServeMux adapters implement it.
+type ListenerServer interface {
+	// ServeListener serves requests on ln until the server shuts down.
+	// Startup hooks run before the first listener is served and OnListen
+	// hooks run for each listener. It may be called for several listeners,
+	// each from its own goroutine, and takes ownership of ln.
+	ServeListener(ln net.Listener) error
+}
+
+// ListenerBinding pairs a server with the listeners it serves.
+type ListenerBinding struct {
+	Server    ListenerServer
+	Listeners []net.Listener
+}
+
+// ServeListeners serves every listener of every binding at once. Use it to
+// serve one server on several addresses, or separate servers, each with its
+// own routes, on a public and an admin port:
+//
+//	public, _ := net.Listen("tcp", ":8080")
+//	admin, _ := router.ListenUnix("/run/app/admin.sock", 0o660)
+//	err := router.ServeListeners(
+//		router.ListenerBinding{Server: app.(router.ListenerServer), Listeners: []net.Listener{public}},
+//		router.ListenerBinding{Server: adminApp.(router.ListenerServer), Listeners: []net.Listener{admin}},
+//	)
+//
+// It returns nil once every listener stopped because its server shut down,
+// or the first other error at once. The remaining listeners keep serving
+// until their servers shut down.
+func ServeListeners(bindings ...ListenerBinding) error {
+	var serving sync.WaitGroup
+	errs := make(chan error, 1)
+	for _, binding := range bindings {
+		for _, ln := range binding.Listeners {
+			serving.Go(func() {
+				err := binding.Server.ServeListener(ln)
+				if err == nil || errors.Is(err, http.ErrServerClosed) {
+					return
+				}
+				select {
+				case errs <- err:
+				default:
+				}
+			})
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		serving.Wait()
+		close(done)
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-done:
+		select {
+		case err := <-errs:
+			return err
+		default:
+			return nil
+		}
+	}
+}
+
+// listenTCP binds address as http.Server.ListenAndServe would.
+func listenTCP(network, address string) (net.Listener, error) {
+	if address == "" {
+		address = ":http"
+	}
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	return ln, nil
+}
+
+// ListenUnix listens on the Unix domain socket at path and sets the file mode
+// of the socket, unless mode is zero. A socket file left by a process that
+// exited is removed first; a socket still accepting connections is not. The
+// socket file is removed when the listener closes.
+func ListenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %w", path, err)
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to set mode of unix socket %s: %w", path, err), ln.Close())
+		}
+	}
+	return ln, nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("unix socket path %s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("unix socket %s is in use", path)
+	}
+	return os.Remove(path)
+}
+
+// systemdFirstFD is SD_LISTEN_FDS_START, the first descriptor systemd passes.
+const systemdFirstFD = 3
+
+// SystemdListener is a socket passed by systemd socket activation. Name is
+// the FileDescriptorName= of the socket unit, or "unknown".
+type SystemdListener struct {
+	net.Listener
+	Name string
+}
+
+// SystemdListeners returns the sockets systemd passed to the process, in the
+// order of the socket units. It returns none when the process was not socket
+// activated. The LISTEN_* variables are unset, so child processes do not
+// claim the sockets.
+func SystemdListeners() ([]SystemdListener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_ = os.Unsetenv(key)
+	}
+
+	listeners := make([]SystemdListener, 0, count)
+	for i := range count {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(systemdFirstFD+i), name)
+		// FileListener duplicates the descriptor, so the original is closed.
+		ln, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("systemd socket %d (%s): %w", systemdFirstFD+i, name, err)
+		}
+		listeners = append(listeners, SystemdListener{Listener: ln, Name: name})
+	}
+	return listeners, nil
+}
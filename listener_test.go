@@ -0,0 +1,139 @@
+package router_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+)
+
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+func getBody(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	res, err := client.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestServeListeners_UnixSocketAndSeparateAdminServer(t *testing.T) {
+	for _, tc := range lifecycleAdapters() {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := os.MkdirTemp("", "router")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			socket := filepath.Join(dir, "app.sock")
+
+			app := tc.server()
+			unix, err := router.ListenUnix(socket, 0o600)
+			require.NoError(t, err)
+			info, err := os.Stat(socket)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			public, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			admin := router.NewHTTPServer()
+			admin.Router().Get("/health", func(c router.Context) error { return c.SendString("ok") })
+			internal, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			served := make(chan error, 1)
+			go func() {
+				served <- router.ServeListeners(
+					router.ListenerBinding{Server: app.(router.ListenerServer), Listeners: []net.Listener{unix, public}},
+					router.ListenerBinding{Server: admin.(router.ListenerServer), Listeners: []net.Listener{internal}},
+				)
+			}()
+
+			require.Eventually(t, func() bool {
+				res, err := http.Get("http://" + internal.Addr().String() + "/health")
+				if err == nil {
+					res.Body.Close()
+				}
+				return err == nil
+			}, time.Second, 10*time.Millisecond)
+			assert.Equal(t, "pong", getBody(t, unixClient(socket), "http://app/ping"))
+			assert.Equal(t, "pong", getBody(t, http.DefaultClient, "http://"+public.Addr().String()+"/ping"))
+			res, err := http.Get("http://" + internal.Addr().String() + "/ping")
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, http.StatusNotFound, res.StatusCode, "admin routes are separate")
+
+			require.NoError(t, app.Shutdown(context.Background()))
+			require.NoError(t, admin.Shutdown(context.Background()))
+			select {
+			case err := <-served:
+				assert.NoError(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("ServeListeners did not return after Shutdown")
+			}
+			_, err = os.Stat(socket)
+			assert.True(t, os.IsNotExist(err), "socket file is removed on close")
+		})
+	}
+}
+
+func TestListenUnix_ReplacesStaleSocketOnly(t *testing.T) {
+	dir, err := os.MkdirTemp("", "router")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "app.sock")
+
+	ln, err := router.ListenUnix(socket, 0)
+	require.NoError(t, err)
+	_, err = router.ListenUnix(socket, 0)
+	require.Error(t, err, "a socket in use is kept")
+	assert.Contains(t, err.Error(), "in use")
+
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+	ln, err = router.ListenUnix(socket, 0)
+	require.NoError(t, err, "a stale socket is replaced")
+	require.NoError(t, ln.Close())
+
+	file := filepath.Join(dir, "data")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = router.ListenUnix(file, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a socket")
+}
+
+func TestSystemdListeners_IgnoresOtherProcesses(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	listeners, err := router.SystemdListeners()
+	require.NoError(t, err)
+	assert.Empty(t, listeners)
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	listeners, err = router.SystemdListeners()
+	require.NoError(t, err)
+	assert.Empty(t, listeners)
+	assert.Equal(t, "1", os.Getenv("LISTEN_FDS"), "variables for another process are kept")
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "x")
+	_, err = router.SystemdListeners()
+	require.Error(t, err)
+}
@@ -37,12 +37,21 @@ func (r *RouteDefinition) AddTags(t ...string) RouteInfo {
 }
 
 func (r *RouteDefinition) AddParameter(name, in string, required bool, schema map[string]any) RouteInfo {
-	r.Parameters = append(r.Parameters, Parameter{
+	param := Parameter{
 		Name:     name,
 		In:       in,
 		Required: required,
 		Schema:   schema,
-	})
+	}
+	// Replace an existing definition, such as one derived from a path
+	// constraint, instead of emitting the parameter twice.
+	for i, existing := range r.Parameters {
+		if existing.Name == name && existing.In == in {
+			r.Parameters[i] = param
+			return r
+		}
+	}
+	r.Parameters = append(r.Parameters, param)
 	return r
 }
 
@@ -340,6 +340,17 @@ func ExtractSchemaFromType(t reflect.Type, opts ...ExtractSchemaFromTypeOptions)
 			prop.Required = false
 		}
 
+		// validate rules document constraints, and "required" outranks omitempty
+		if validateTag := field.Tag.Get(TAG_VALIDATE); validateTag != "" {
+			constraints, required := validationTagSchema(field.Type, validateTag)
+			if len(constraints) > 0 {
+				prop.Constraints = constraints
+			}
+			if required {
+				prop.Required = true
+			}
+		}
+
 		// Add to required slice only after final determination
 		if prop.Required {
 			required = append(required, fieldName)
@@ -14,6 +14,7 @@ const (
 	TAG_CRUD         = "crud"
 	TAG_BUN          = "bun"
 	TAG_JSON         = "json"
+	TAG_VALIDATE     = "validate"
 	TAG_KEY_RESOURCE = "resource"
 )
 
@@ -37,20 +37,35 @@ type RouteDefinition struct {
 	Method   HTTPMethod     `json:"method"`
 	Path     string         `json:"path"`
 	Name     string         `json:"name"`
-	Handlers []NamedHandler `json:"-"` // Runtime only, not exported to JSON
+	Host     string         `json:"host,omitempty"`    // Host group pattern, empty for the default host
+	Version  string         `json:"version,omitempty"` // API version group, empty for unversioned routes
+	Handlers []NamedHandler `json:"-"`                 // Runtime only, not exported to JSON
 
 	// metadata e.g. OpenAPI
-	Summary     string       `json:"summary,omitempty"`
-	Description string       `json:"description,omitempty"`
-	Tags        []string     `json:"tags,omitempty"`
-	Parameters  []Parameter  `json:"parameters,omitempty"`
-	RequestBody *RequestBody `json:"request_body,omitempty"`
-	Responses   []Response   `json:"responses,omitempty"`
-	Security    []string     `json:"security,omitempty"`
+	Summary     string             `json:"summary,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
+	Parameters  []Parameter        `json:"parameters,omitempty"`
+	RequestBody *RequestBody       `json:"request_body,omitempty"`
+	Responses   []Response         `json:"responses,omitempty"`
+	Security    []string           `json:"security,omitempty"`
+	Deprecation *Deprecation       `json:"deprecation,omitempty"`
+	CORS        *CORSPolicy        `json:"-"` // Set with SetCORSPolicy
+	RateLimit   *RateLimitQuota    `json:"rate_limit,omitempty"`
+	Cache       *CachePolicy       `json:"cache,omitempty"`
+	Idempotency *IdempotencyPolicy `json:"idempotency,omitempty"`
+	Conditional *ConditionalPolicy `json:"-"` // Set with SetConditional
 	onSetName   func(*RouteDefinition, string) error
 	publicName  string
 	nameMode    routeNameMode
 	middlewares []namedMiddleware
+	// paramConstraints are the compiled "<...>" constraints declared in Path.
+	paramConstraints routeParamConstraints
+	routeScope
+	// mount is where the adapter dispatches the route from.
+	mount *routeMount
+	// inheritedFrom is the route a newer path version serves this copy of.
+	inheritedFrom *RouteDefinition
 }
 
 // Parameter unifies the parameter definitions
@@ -107,6 +122,7 @@ type PropertyInfo struct {
 	TransformPath []string                `json:"transformPath,omitempty"` // Transformation steps
 	GoPackage     string                  `json:"goPackage,omitempty"`     // Package path
 	CustomTagData map[string]any          `json:"customTagData,omitempty"` // Custom tag handler results
+	Constraints   map[string]any          `json:"constraints,omitempty"`   // OpenAPI keywords from validate tags
 	RelationName  string                  `json:"-"`                       // Populated for relation-backed fields
 	RelatedSchema string                  `json:"-"`                       // Target schema/component name
 }
@@ -589,6 +605,12 @@ func convertPropertyInfo(prop PropertyInfo) map[string]any {
 		property["items"] = convertPropertyInfo(*prop.Items)
 	}
 
+	for key, value := range prop.Constraints {
+		if _, exists := property[key]; !exists {
+			property[key] = value
+		}
+	}
+
 	return property
 }
 
@@ -0,0 +1,31 @@
+// Package openapivalidate provides go-router middleware that validates
+// requests, and optionally responses, against an OpenAPI 3 document. The
+// document can be the one go-router generates for the app, so the spec served
+// at /openapi.json is also the contract the service enforces.
+//
+// Requests are matched to an operation by method and path. Parameters, the
+// body and its content type, and the presence of the credentials named by the
+// operation's security requirements are checked before the handler runs.
+// Failures are go-errors values: 400 with VALIDATION_ERROR and one FieldError
+// per problem, 415 for an undeclared content type, and 401 for missing
+// credentials. Requests that match no documented operation pass through.
+//
+// Usage:
+//
+//	renderer := router.NewOpenAPIRenderer(router.OpenAPIRenderer{
+//		Info: &router.OpenAPIInfo{Title: "Users", Version: "1.0.0"},
+//	})
+//
+//	api := app.Router().Group("/api")
+//	api.Use(openapivalidate.New(openapivalidate.Config{
+//		// Called on the first request, once every route is registered.
+//		Document: func() map[string]any {
+//			return renderer.AppenRouteInfo(app.Router().Routes()).GenerateOpenAPI()
+//		},
+//		// Flag handlers that answer with undeclared statuses or bodies.
+//		ValidateResponses: os.Getenv("APP_ENV") != "production",
+//	}))
+//
+// Response validation buffers each response, so it is meant for tests and
+// staging. Streamed responses cannot be validated.
+package openapivalidate
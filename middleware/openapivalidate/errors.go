@@ -0,0 +1,148 @@
+package openapivalidate
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	goerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-router"
+)
+
+// errorSource is reported in the metadata of request errors.
+const errorSource = "openapi"
+
+// kin-openapi reports an undeclared request content type with this reason
+// and no wrapped error.
+const invalidContentTypeReason = "header Content-Type has unexpected value"
+
+// requestError turns the errors of openapi3filter.ValidateRequest into a
+// go-errors value: 415 for an undeclared content type, 401 when only the
+// security requirements failed, and a 400 validation error otherwise.
+func requestError(err error) error {
+	var p problems
+	p.collect(err, "")
+
+	switch {
+	case p.contentType != "":
+		return goerrors.New("unsupported content type "+strconv.Quote(p.contentType), goerrors.CategoryBadInput).
+			WithCode(http.StatusUnsupportedMediaType).
+			WithTextCode("UNSUPPORTED_MEDIA_TYPE").
+			WithMetadata(map[string]any{"source": errorSource, "content_type": p.contentType})
+	case p.unauthorized && len(p.fields) == 0:
+		return router.NewUnauthorizedError("missing credentials", map[string]any{"source": errorSource})
+	default:
+		return router.NewValidationError("request does not match the API specification", p.fields,
+			map[string]any{"source": errorSource})
+	}
+}
+
+type problems struct {
+	fields       []goerrors.FieldError
+	contentType  string
+	unauthorized bool
+}
+
+func (p *problems) collect(err error, field string) {
+	var (
+		multi     openapi3.MultiError
+		request   *openapi3filter.RequestError
+		security  *openapi3filter.SecurityRequirementsError
+		schema    *openapi3.SchemaError
+		parse     *openapi3filter.ParseError
+		errsMulti interface{ Unwrap() []error }
+	)
+
+	switch {
+	case isMultiError(err, &multi):
+		for _, inner := range multi {
+			p.collect(inner, field)
+		}
+	case errors.As(err, &security):
+		p.unauthorized = true
+	case errors.As(err, &request):
+		p.collectRequest(request)
+	case errors.As(err, &schema):
+		p.add(joinField(field, schema.JSONPointer()), schema.Reason)
+	case errors.As(err, &parse):
+		p.add(field, parse.Error())
+	case errors.As(err, &errsMulti):
+		for _, inner := range errsMulti.Unwrap() {
+			p.collect(inner, field)
+		}
+	default:
+		p.add(field, err.Error())
+	}
+}
+
+func (p *problems) collectRequest(err *openapi3filter.RequestError) {
+	switch {
+	case err.Parameter != nil:
+		if err.Err == nil {
+			p.add(err.Parameter.Name, err.Reason)
+			return
+		}
+		p.collect(err.Err, err.Parameter.Name)
+	case err.RequestBody != nil:
+		if strings.HasPrefix(err.Reason, invalidContentTypeReason) {
+			p.contentType = err.Input.Request.Header.Get("Content-Type")
+			if p.contentType == "" {
+				p.contentType = "none"
+			}
+			return
+		}
+		var parse *openapi3filter.ParseError
+		if errors.As(err.Err, &parse) && parse.Kind == openapi3filter.KindUnsupportedFormat {
+			p.contentType = err.Input.Request.Header.Get("Content-Type")
+			return
+		}
+		if err.Err == nil || errors.Is(err.Err, openapi3filter.ErrInvalidRequired) {
+			p.add("body", err.Error())
+			return
+		}
+		p.collect(err.Err, "")
+	case err.Reason == "authorization failed":
+		p.unauthorized = true
+	default:
+		p.add("", err.Error())
+	}
+}
+
+func (p *problems) add(field, message string) {
+	if field == "" {
+		field = "body"
+	}
+	p.fields = append(p.fields, goerrors.FieldError{Field: field, Message: message})
+}
+
+// isMultiError reports whether err itself is an openapi3.MultiError. Unlike
+// errors.As it does not look inside, so a MultiError nested in a
+// RequestError keeps its parameter name.
+func isMultiError(err error, target *openapi3.MultiError) bool {
+	multi, ok := err.(openapi3.MultiError)
+	if ok {
+		*target = multi
+	}
+	return ok
+}
+
+// joinField renders a JSON pointer the way router.Validate names fields:
+// items/1/sku becomes items[1].sku.
+func joinField(field string, pointer []string) string {
+	var b strings.Builder
+	b.WriteString(field)
+	for _, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
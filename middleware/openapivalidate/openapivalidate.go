@@ -0,0 +1,321 @@
+package openapivalidate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	goerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-router"
+)
+
+type Config struct {
+	Skip func(c router.Context) bool
+
+	// Spec is an already loaded document. It takes precedence over Document
+	// and SpecFile.
+	Spec *openapi3.T
+	// Document returns the document to enforce, e.g. the output of
+	// OpenAPIRenderer.GenerateOpenAPI. It is called once, on the first
+	// request, so routes registered after the middleware are included.
+	Document func() map[string]any
+	// SpecFile is the path of a JSON or YAML document.
+	SpecFile string
+
+	// BasePath is removed from request paths before they are matched against
+	// the document paths, for specs whose paths are relative to a server URL.
+	BasePath string
+
+	// Authenticate checks the credentials named by an operation's security
+	// requirements. CredentialsPresent, the default, only checks that they
+	// were sent.
+	Authenticate openapi3filter.AuthenticationFunc
+
+	// ValidateResponses buffers responses and checks their status, headers
+	// and body against the operation's declared responses.
+	ValidateResponses bool
+	// MaxResponseSize caps buffered responses.
+	MaxResponseSize int64
+	// OnResponseError handles a response that does not match the document.
+	// The default discards it and returns a 500 error; return nil to send
+	// the response anyway, e.g. after logging err.
+	OnResponseError func(c router.Context, err error) error
+}
+
+var ConfigDefault = Config{
+	Skip:            nil,
+	Authenticate:    CredentialsPresent,
+	MaxResponseSize: router.DefaultMaxCapturedBodySize,
+	OnResponseError: defaultResponseError,
+}
+
+func New(config ...Config) router.MiddlewareFunc {
+	cfg := configDefault(config...)
+	v := &validator{cfg: cfg}
+
+	return func(_ router.HandlerFunc) router.HandlerFunc {
+		return func(ctx router.Context) error {
+			if cfg.Skip != nil && cfg.Skip(ctx) {
+				return ctx.Next()
+			}
+
+			paths, err := v.load()
+			if err != nil {
+				return router.NewInternalError(err, "openapi document could not be loaded")
+			}
+
+			route, params := paths.find(ctx.Method(), strings.TrimPrefix(ctx.Path(), cfg.BasePath))
+			if route == nil {
+				return ctx.Next()
+			}
+
+			httpCtx, ok := router.AsHTTPContext(ctx)
+			if !ok || httpCtx.Request() == nil {
+				return router.NewInternalError(errors.New("context does not implement router.HTTPContext"), "openapi validation is not supported by this adapter")
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    httpCtx.Request(),
+				PathParams: params,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError:          true,
+					SkipSettingDefaults: true,
+					AuthenticationFunc:  cfg.Authenticate,
+				},
+			}
+			if err := openapi3filter.ValidateRequest(ctx.Context(), input); err != nil {
+				return requestError(err)
+			}
+
+			if !cfg.ValidateResponses {
+				return ctx.Next()
+			}
+
+			captured, err := router.CaptureNext(ctx, cfg.MaxResponseSize)
+			if err != nil {
+				return err
+			}
+			res := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 captured.StatusCode,
+				Header:                 captured.Headers,
+				Body:                   io.NopCloser(bytes.NewReader(captured.Body)),
+				Options: &openapi3filter.Options{
+					IncludeResponseStatus: true,
+					MultiError:            true,
+				},
+			}
+			if err := openapi3filter.ValidateResponse(ctx.Context(), res); err != nil {
+				err = fmt.Errorf("%s %s responded %d: %w", ctx.Method(), route.Path, captured.StatusCode, err)
+				if handled := cfg.OnResponseError(ctx, err); handled != nil {
+					return handled
+				}
+			}
+			return router.ReplayCapturedResponse(ctx, captured)
+		}
+	}
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return ConfigDefault
+	}
+
+	cfg := config[0]
+
+	if cfg.Authenticate == nil {
+		cfg.Authenticate = ConfigDefault.Authenticate
+	}
+
+	if cfg.MaxResponseSize <= 0 {
+		cfg.MaxResponseSize = ConfigDefault.MaxResponseSize
+	}
+
+	if cfg.OnResponseError == nil {
+		cfg.OnResponseError = ConfigDefault.OnResponseError
+	}
+
+	cfg.BasePath = strings.TrimSuffix(cfg.BasePath, "/")
+
+	return cfg
+}
+
+// CredentialsPresent is an openapi3filter.AuthenticationFunc that accepts a
+// security scheme when its credentials are present in the request: the API
+// key header, query parameter or cookie, an Authorization header using the
+// HTTP scheme, a bearer token for OAuth2 and OpenID Connect, or a client
+// certificate for mutual TLS. Verifying them is left to the handlers.
+func CredentialsPresent(_ context.Context, input *openapi3filter.AuthenticationInput) error {
+	scheme := input.SecurityScheme
+	req := input.RequestValidationInput.Request
+	if scheme == nil || req == nil {
+		return input.NewError(nil)
+	}
+
+	present := false
+	switch scheme.Type {
+	case "apiKey":
+		switch scheme.In {
+		case openapi3.ParameterInHeader:
+			present = req.Header.Get(scheme.Name) != ""
+		case openapi3.ParameterInQuery:
+			present = req.URL.Query().Get(scheme.Name) != ""
+		case openapi3.ParameterInCookie:
+			cookie, err := req.Cookie(scheme.Name)
+			present = err == nil && cookie.Value != ""
+		}
+	case "http":
+		present = hasAuthorization(req, scheme.Scheme)
+	case "oauth2", "openIdConnect":
+		present = hasAuthorization(req, "bearer")
+	case "mutualTLS":
+		present = req.TLS != nil && len(req.TLS.PeerCertificates) > 0
+	}
+	if !present {
+		return input.NewError(nil)
+	}
+	return nil
+}
+
+func hasAuthorization(req *http.Request, scheme string) bool {
+	kind, credentials, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	return ok && strings.EqualFold(kind, scheme) && strings.TrimSpace(credentials) != ""
+}
+
+func defaultResponseError(_ router.Context, err error) error {
+	return goerrors.Wrap(err, goerrors.CategoryInternal, "response does not match the API specification").
+		WithCode(http.StatusInternalServerError).
+		WithTextCode("RESPONSE_VALIDATION_ERROR")
+}
+
+type validator struct {
+	cfg   Config
+	once  sync.Once
+	paths *pathTable
+	err   error
+}
+
+func (v *validator) load() (*pathTable, error) {
+	v.once.Do(func() {
+		var spec *openapi3.T
+		spec, v.err = v.loadSpec()
+		if v.err == nil {
+			v.paths = newPathTable(spec)
+		}
+	})
+	return v.paths, v.err
+}
+
+func (v *validator) loadSpec() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	switch {
+	case v.cfg.Spec != nil:
+		return v.cfg.Spec, nil
+	case v.cfg.Document != nil:
+		data, err := json.Marshal(v.cfg.Document())
+		if err != nil {
+			return nil, fmt.Errorf("openapivalidate: encode document: %w", err)
+		}
+		return loader.LoadFromData(data)
+	case v.cfg.SpecFile != "":
+		return loader.LoadFromFile(v.cfg.SpecFile)
+	default:
+		return nil, errors.New("openapivalidate: no Spec, Document or SpecFile configured")
+	}
+}
+
+// pathTable matches request paths against the document's path templates.
+// Literal segments win over parameters, so /users/me is preferred over
+// /users/{id}.
+type pathTable struct {
+	spec    *openapi3.T
+	entries []pathEntry
+}
+
+type pathEntry struct {
+	path     string
+	item     *openapi3.PathItem
+	segments []string
+}
+
+func newPathTable(spec *openapi3.T) *pathTable {
+	table := &pathTable{spec: spec}
+	if spec.Paths == nil {
+		return table
+	}
+	for path, item := range spec.Paths.Map() {
+		table.entries = append(table.entries, pathEntry{
+			path:     path,
+			item:     item,
+			segments: splitPath(path),
+		})
+	}
+	sort.Slice(table.entries, func(i, j int) bool {
+		a, b := table.entries[i].segments, table.entries[j].segments
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if pa, pb := isParamSegment(a[k]), isParamSegment(b[k]); pa != pb {
+				return pb
+			}
+		}
+		return table.entries[i].path < table.entries[j].path
+	})
+	return table
+}
+
+func (t *pathTable) find(method, path string) (*routers.Route, map[string]string) {
+	segments := splitPath(path)
+	for _, entry := range t.entries {
+		operation := entry.item.GetOperation(strings.ToUpper(method))
+		if operation == nil || len(entry.segments) != len(segments) {
+			continue
+		}
+		params, ok := matchSegments(entry.segments, segments)
+		if !ok {
+			continue
+		}
+		return &routers.Route{
+			Spec:      t.spec,
+			Path:      entry.path,
+			PathItem:  entry.item,
+			Method:    strings.ToUpper(method),
+			Operation: operation,
+		}, params
+	}
+	return nil, nil
+}
+
+func matchSegments(pattern, segments []string) (map[string]string, bool) {
+	params := map[string]string{}
+	for i, segment := range pattern {
+		if isParamSegment(segment) {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParamSegment(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
+}
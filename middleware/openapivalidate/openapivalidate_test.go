@@ -0,0 +1,202 @@
+package openapivalidate_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/middleware/openapivalidate"
+	"github.com/goliatone/go-router/routertest"
+)
+
+type createUser struct {
+	Notify bool   `query:"notify"`
+	Name   string `json:"name" validate:"required,min=2"`
+	Age    int    `json:"age"`
+}
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// registerUsers documents the routes through TypedRoute and serves them behind the
+// validator, using the document generated from the router itself.
+func registerUsers[T any](r router.Router[T], cfg openapivalidate.Config) {
+	cfg.Document = func() map[string]any {
+		return router.NewOpenAPIRenderer().AppenRouteInfo(r.Routes()).GenerateOpenAPI()
+	}
+	api := r.Group("/api")
+	api.Use(openapivalidate.New(cfg))
+
+	router.TypedRoute(api, router.POST, "/users", func(_ router.Context, req createUser) (user, error) {
+		return user{ID: 1, Name: req.Name}, nil
+	}, router.WithTypedStatus(http.StatusCreated))
+	router.TypedRoute(api, router.GET, "/users/:id", func(_ router.Context, req struct {
+		ID int `path:"id"`
+	}) (user, error) {
+		return user{ID: req.ID, Name: "Ada"}, nil
+	})
+	api.Get("/health", func(c router.Context) error {
+		return c.SendString("ok")
+	})
+}
+
+func TestNew_ValidatesRequestsAgainstGeneratedDocument(t *testing.T) {
+	fiberApp := router.NewFiberAdapter()
+	registerUsers(fiberApp.Router(), openapivalidate.Config{})
+	httpApp := router.NewHTTPServer()
+	registerUsers(httpApp.Router(), openapivalidate.Config{})
+	muxApp := router.NewServeMuxServer()
+	registerUsers(muxApp.Router(), openapivalidate.Config{})
+
+	for name, client := range map[string]*routertest.Client{
+		"fiber":      routertest.NewClient(fiberApp),
+		"httprouter": routertest.NewClient(httpApp),
+		"servemux":   routertest.NewClient(muxApp),
+	} {
+		t.Run(name, func(t *testing.T) {
+			client.Post("/api/users?notify=true").JSON(map[string]any{"name": "Ada", "age": 36}).Do(t).
+				AssertStatus(http.StatusCreated).
+				AssertJSONPath("name", "Ada")
+
+			res := client.Post("/api/users").JSON(map[string]any{"name": "A", "age": "old"}).Do(t).
+				AssertStatus(http.StatusBadRequest)
+			assert.Contains(t, res.String(), "VALIDATION_ERROR", "the validator rejects before Typed binds")
+			assert.Contains(t, res.String(), `"field":"name"`)
+			assert.Contains(t, res.String(), `"field":"age"`)
+
+			res = client.Post("/api/users?notify=maybe").JSON(map[string]any{"name": "Ada"}).Do(t).
+				AssertStatus(http.StatusBadRequest)
+			assert.Contains(t, res.String(), `"field":"notify"`)
+
+			client.Get("/api/users/abc").Do(t).AssertStatus(http.StatusBadRequest)
+			client.Get("/api/users/7").Do(t).AssertStatus(http.StatusOK).AssertJSONPath("id", 7)
+
+			client.Post("/api/users").Body(strings.NewReader("name=Ada"), "text/plain").Do(t).
+				AssertStatus(http.StatusUnsupportedMediaType)
+
+			client.Get("/api/health").Do(t).AssertStatus(http.StatusOK).AssertBody("ok")
+		})
+	}
+}
+
+const securedSpec = `
+openapi: 3.0.3
+info: {title: Reports, version: "1.0"}
+servers:
+  - url: https://reports.example.com/v1
+paths:
+  /reports/{id}:
+    get:
+      security: [{apiKey: []}, {bearer: []}]
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string, pattern: "^[0-9a-f-]{36}$"}}
+      responses:
+        "200":
+          description: A report
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: string}
+components:
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+    bearer: {type: http, scheme: bearer}
+`
+
+func TestNew_SpecFileSecurityAndBasePath(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(securedSpec), 0o600))
+
+	app := router.NewHTTPServer()
+	v1 := app.Router().Group("/v1")
+	v1.Use(openapivalidate.New(openapivalidate.Config{SpecFile: specFile, BasePath: "/v1"}))
+	v1.Get("/reports/:id", func(c router.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+	})
+
+	id := "/v1/reports/6f1c2f43-8d4f-4ad4-9f7a-3f0e2a1b9c10"
+	client := routertest.NewClient(app)
+	client.Get(id).Do(t).AssertStatus(http.StatusUnauthorized)
+	client.Get(id).Header("Authorization", "Basic abc").Do(t).AssertStatus(http.StatusUnauthorized)
+	client.Get(id).Header("X-API-Key", "k").Do(t).AssertStatus(http.StatusOK)
+	client.Get(id).Header("Authorization", "Bearer t").Do(t).AssertStatus(http.StatusOK)
+	client.Get("/v1/reports/nope").Header("X-API-Key", "k").Do(t).AssertStatus(http.StatusBadRequest)
+}
+
+func TestNew_ValidateResponses(t *testing.T) {
+	var flagged []error
+	register := func(r router.Router[*httprouter.Router], onError func(router.Context, error) error) {
+		api := r.Group("/api")
+		api.Use(openapivalidate.New(openapivalidate.Config{
+			Document: func() map[string]any {
+				return router.NewOpenAPIRenderer().AppenRouteInfo(r.Routes()).GenerateOpenAPI()
+			},
+			ValidateResponses: true,
+			OnResponseError:   onError,
+		}))
+		api.Use(func(next router.HandlerFunc) router.HandlerFunc {
+			return func(c router.Context) error {
+				c.SetHeader("X-Downstream", "yes")
+				return c.Next()
+			}
+		})
+		api.Get("/users/:id", func(c router.Context) error {
+			switch c.Param("id") {
+			case "1":
+				return c.JSON(http.StatusOK, map[string]any{"id": 1, "name": "Ada"})
+			case "2":
+				return c.JSON(http.StatusOK, map[string]any{"id": "two"})
+			default:
+				return c.JSON(http.StatusTeapot, map[string]any{})
+			}
+		}).AddResponse(http.StatusOK, "A user", map[string]any{
+			"application/json": map[string]any{"schema": map[string]any{
+				"type":       "object",
+				"required":   []string{"id"},
+				"properties": map[string]any{"id": map[string]any{"type": "integer"}},
+			}},
+		})
+	}
+
+	strictApp := router.NewHTTPServer()
+	register(strictApp.Router(), nil)
+	strict := routertest.NewClient(strictApp)
+	strict.Get("/api/users/1").Do(t).
+		AssertStatus(http.StatusOK).
+		AssertHeader("X-Downstream", "yes").
+		AssertJSONPath("name", "Ada")
+	res := strict.Get("/api/users/2").Do(t).AssertStatus(http.StatusInternalServerError)
+	assert.Contains(t, res.String(), "RESPONSE_VALIDATION_ERROR")
+	strict.Get("/api/users/3").Do(t).AssertStatus(http.StatusInternalServerError)
+
+	lenientApp := router.NewHTTPServer()
+	register(lenientApp.Router(), func(_ router.Context, err error) error {
+		flagged = append(flagged, err)
+		return nil
+	})
+	routertest.NewClient(lenientApp).Get("/api/users/2").Do(t).
+		AssertStatus(http.StatusOK).
+		AssertJSONPath("id", "two")
+	require.Len(t, flagged, 1)
+	assert.Contains(t, flagged[0].Error(), "GET /api/users/{id} responded 200")
+}
+
+func TestNew_ReportsMissingDocument(t *testing.T) {
+	app := router.NewHTTPServer()
+	app.Router().Use(openapivalidate.New(openapivalidate.Config{SpecFile: filepath.Join(t.TempDir(), "missing.yaml")}))
+	app.Router().Get("/api/users", func(c router.Context) error { return c.SendString("ok") })
+
+	routertest.NewClient(app).Get("/api/users").Do(t).AssertStatus(http.StatusInternalServerError)
+}
@@ -363,6 +363,21 @@ func (m *MockContext) BindQuery(i any) error {
 	return args.Error(0)
 }
 
+func (m *MockContext) BindParams(i any) error {
+	args := m.Called(i)
+	return args.Error(0)
+}
+
+func (m *MockContext) BindHeaders(i any) error {
+	args := m.Called(i)
+	return args.Error(0)
+}
+
+func (m *MockContext) BindForm(i any) error {
+	args := m.Called(i)
+	return args.Error(0)
+}
+
 func (m *MockContext) CookieParser(i any) error {
 	args := m.Called(i)
 	return args.Error(0)
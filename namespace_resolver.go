@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net/url"
 	"path"
 	"strings"
 )
@@ -150,6 +151,30 @@ func (r NamespaceResolver[NS]) Relative(ns NS, routeKey string) (string, error)
 	return relative, nil
 }
 
+// URL resolves namespace + route key like Resolve and fills the path
+// parameters of the result the way URLBuilder does, so route tables can hold
+// patterns such as "/users/:id<int>". Params that are not path parameters
+// are added to the query string, after query.
+func (r NamespaceResolver[NS]) URL(ns NS, routeKey string, params map[string]any, query url.Values) (string, error) {
+	pattern, err := r.Resolve(ns, routeKey)
+	if err != nil {
+		return "", err
+	}
+	constraints, err := compileRouteParamConstraints(pattern)
+	if err != nil {
+		return "", err
+	}
+	built, err := buildRouteURL(&RouteDefinition{
+		Name:             string(ns) + ":" + routeKey,
+		Path:             pattern,
+		paramConstraints: constraints,
+	}, params, query)
+	if err != nil {
+		return "", err
+	}
+	return built.pathAndQuery(), nil
+}
+
 // MustRelative returns Relative(ns, routeKey) and panics on error.
 func (r NamespaceResolver[NS]) MustRelative(ns NS, routeKey string) string {
 	relative, err := r.Relative(ns, routeKey)
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"maps"
 	"net/http"
+	"path"
+	"slices"
 	"strings"
 	"sync"
 
@@ -17,6 +19,7 @@ type openAPIConfig struct {
 	openapiPath     string
 	title           string
 	includeDocPaths bool
+	version         string
 }
 
 type OpenAPIOption func(*openAPIConfig)
@@ -45,6 +48,16 @@ func WithOpenAPIEndpointsInSpec(include bool) OpenAPIOption {
 	}
 }
 
+// WithOpenAPIVersion serves the document of a single API version: its
+// declared and inherited routes plus unversioned ones. Unless set otherwise
+// the document is served under /openapi/<version> and /meta/docs/<version>/,
+// so ServeOpenAPI can be called once per version.
+func WithOpenAPIVersion(version string) OpenAPIOption {
+	return func(cfg *openAPIConfig) {
+		cfg.version = strings.Trim(strings.TrimSpace(version), "/")
+	}
+}
+
 // Default paths: /meta/docs and /openapi.json
 func defaultOpenAPIConfig() *openAPIConfig {
 	return &openAPIConfig{
@@ -288,9 +301,18 @@ type OpenApiMetaGenerator interface {
 
 func ServeOpenAPI[T any](router Router[T], renderer OpenApiMetaGenerator, opts ...OpenAPIOption) {
 	cfg := defaultOpenAPIConfig()
+	defaults := *cfg
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.version != "" {
+		if cfg.openapiPath == defaults.openapiPath {
+			cfg.openapiPath = path.Join(cfg.openapiPath, cfg.version)
+		}
+		if cfg.docsPath == defaults.docsPath {
+			cfg.docsPath = path.Join(cfg.docsPath, cfg.version) + "/"
+		}
+	}
 
 	// Guard against concurrent access to the renderer when it cannot be cloned.
 	var rendererMu sync.Mutex
@@ -314,6 +336,13 @@ func ServeOpenAPI[T any](router Router[T], renderer OpenApiMetaGenerator, opts .
 			if !cfg.includeDocPaths {
 				routes = filterOpenAPISelfRoutes(routes)
 			}
+			if cfg.version != "" {
+				routes = routesForVersion(routes, cfg.version)
+				cloned.Version = cfg.version
+				if cloned.Info != nil {
+					cloned.Info.Version = cfg.version
+				}
+			}
 			// Ensure base renderer state is accounted for before appending router metadata.
 			cloned.AppenRouteInfo(nil)
 			cloned.AppenRouteInfo(routes)
@@ -489,6 +518,17 @@ func (o *OpenAPIRenderer) addRouteToPath(rt RouteDefinition) {
 		"tags":        rt.Tags,
 		"responses":   make(map[string]any),
 	}
+	if rt.deprecation() != nil {
+		op["deprecated"] = true
+	}
+	if q := rt.RateLimit; q != nil {
+		op["x-rate-limit"] = map[string]any{
+			"limit":     q.Limit,
+			"window":    ceilSeconds(q.Window),
+			"burst":     q.Burst,
+			"algorithm": string(q.Algorithm),
+		}
+	}
 
 	// Parameters
 	var params []any
@@ -501,6 +541,17 @@ func (o *OpenAPIRenderer) addRouteToPath(rt RouteDefinition) {
 			"description": p.Description,
 		})
 	}
+	if rt.Idempotency != nil && !slices.ContainsFunc(rt.Parameters, func(p Parameter) bool {
+		return p.In == "header" && strings.EqualFold(p.Name, HeaderIdempotencyKey)
+	}) {
+		params = append(params, map[string]any{
+			"name":        HeaderIdempotencyKey,
+			"in":          "header",
+			"required":    rt.Idempotency.Required,
+			"schema":      map[string]any{"type": "string", "maxLength": 255},
+			"description": "Unique key of the request. Retries with the same key and payload replay the first response.",
+		})
+	}
 	if len(params) > 0 {
 		op["parameters"] = params
 	}
@@ -522,6 +573,15 @@ func (o *OpenAPIRenderer) addRouteToPath(rt RouteDefinition) {
 		}
 	}
 
+	// Host groups are served from their own server
+	var server map[string]any
+	if pattern, err := compileHostPattern(rt.Host); err == nil {
+		server = hostServer(pattern)
+	}
+	if server != nil {
+		op["servers"] = []any{server}
+	}
+
 	// Get or create path item
 	pathItem, exists := o.Paths[fullPath]
 	if !exists {
@@ -534,14 +594,60 @@ func (o *OpenAPIRenderer) addRouteToPath(rt RouteDefinition) {
 		pathItemMap = make(map[string]any)
 	}
 
-	// Add operation to path
+	// Add operation to path. An operation declared on several hosts is
+	// documented once, listing the servers of each host.
 	methodLower := strings.ToLower(string(rt.Method))
+	if existing, ok := pathItemMap[methodLower].(map[string]any); ok && (server != nil || existing["servers"] != nil) {
+		existing["servers"] = o.mergeOperationServers(existing["servers"], server)
+		o.Paths[fullPath] = pathItemMap
+		return
+	}
 	pathItemMap[methodLower] = op
 
 	// Update paths
 	o.Paths[fullPath] = pathItemMap
 }
 
+// mergeOperationServers adds server, or the document servers for the default
+// host, to the servers of an operation.
+func (o *OpenAPIRenderer) mergeOperationServers(current any, server map[string]any) []any {
+	servers, ok := current.([]any)
+	if !ok {
+		// The operation was documented for the default host.
+		servers = o.documentServers()
+	}
+	added := []any{server}
+	if server == nil {
+		added = o.documentServers()
+	}
+	for _, candidate := range added {
+		url := candidate.(map[string]any)["url"]
+		if !slices.ContainsFunc(servers, func(s any) bool {
+			m, ok := s.(map[string]any)
+			return ok && m["url"] == url
+		}) {
+			servers = append(servers, candidate)
+		}
+	}
+	return servers
+}
+
+// documentServers returns the document level servers, which serve the routes
+// of the default host.
+func (o *OpenAPIRenderer) documentServers() []any {
+	if len(o.Servers) == 0 {
+		return []any{map[string]any{"url": "/"}}
+	}
+	servers := make([]any, 0, len(o.Servers))
+	for _, server := range o.Servers {
+		servers = append(servers, map[string]any{
+			"url":         server.Url,
+			"description": server.Description,
+		})
+	}
+	return servers
+}
+
 func joinPaths(parts ...string) string {
 	cleanParts := make([]string, 0)
 	for _, p := range parts {
@@ -603,14 +709,20 @@ func normalizePathParams(path string) string {
 			j++
 		}
 
-		// Skip Fiber's inline type or regex declarations like :id<int> or :id([0-9]+)
-		if j < len(path) && (path[j] == '<' || path[j] == '(') {
-			closing := byte('>')
-			if path[j] == '(' {
-				closing = ')'
+		// Skip inline constraint or regex declarations like :id<int> or :id([0-9]+)
+		if j < len(path) && path[j] == '<' {
+			// Constraint arguments may contain '>', so consume through the
+			// last one in the segment.
+			end := strings.IndexByte(path[j:], '/')
+			if end < 0 {
+				end = len(path) - j
+			}
+			if closing := strings.LastIndexByte(path[j:j+end], '>'); closing >= 0 {
+				j += closing + 1
 			}
+		} else if j < len(path) && path[j] == '(' {
 			j++
-			for j < len(path) && path[j] != closing {
+			for j < len(path) && path[j] != ')' {
 				j++
 			}
 			if j < len(path) {
@@ -0,0 +1,510 @@
+package router
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	goerrors "github.com/goliatone/go-errors"
+)
+
+// ParamConstraint is a compiled path parameter constraint, such as the "int"
+// in ":id<int>".
+type ParamConstraint struct {
+	// Match reports whether a path value satisfies the constraint.
+	Match func(value string) bool
+	// Schema is merged into the parameter's OpenAPI schema.
+	Schema map[string]any
+}
+
+// ParamConstraintFactory compiles a constraint from the text between its
+// parentheses, e.g. "5" for "min(5)". arg is empty for bare constraints.
+type ParamConstraintFactory func(arg string) (ParamConstraint, error)
+
+var paramConstraints = struct {
+	sync.RWMutex
+	factories map[string]ParamConstraintFactory
+}{
+	factories: map[string]ParamConstraintFactory{
+		"int":        intConstraint,
+		"uint":       uintConstraint,
+		"bool":       boolConstraint,
+		"float":      floatConstraint,
+		"alpha":      patternConstraint(`^[A-Za-z]+$`, nil),
+		"slug":       patternConstraint(`^[a-z0-9]+(?:-[a-z0-9]+)*$`, nil),
+		"uuid":       patternConstraint(uuidPattern, map[string]any{"format": "uuid"}),
+		"guid":       patternConstraint(uuidPattern, map[string]any{"format": "uuid"}),
+		"hashid":     patternConstraint(hashIDPattern, nil),
+		"regex":      regexConstraint,
+		"min":        boundConstraint("min"),
+		"max":        boundConstraint("max"),
+		"range":      rangeConstraint,
+		"len":        lengthConstraint("len"),
+		"minLen":     lengthConstraint("minLen"),
+		"maxLen":     lengthConstraint("maxLen"),
+		"betweenLen": betweenLenConstraint,
+		"datetime":   datetimeConstraint,
+		"enum":       enumConstraint,
+	},
+}
+
+const (
+	uuidPattern = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+	// hashIDPattern accepts the UUIDs produced by goliatone/hashid and their
+	// 22 character short ID encoding.
+	hashIDPattern = `^(?:[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[2-9A-HJ-NP-Za-km-z]{22})$`
+)
+
+// RegisterParamConstraint adds or replaces a named path parameter constraint.
+// Routes compile their constraints when they are registered, so custom
+// constraints must be registered before the routes that use them.
+//
+// Example:
+//
+//	router.RegisterParamConstraint("sku", func(string) (router.ParamConstraint, error) {
+//		return router.ParamConstraint{
+//			Match:  func(v string) bool { return strings.HasPrefix(v, "SKU-") },
+//			Schema: map[string]any{"type": "string", "pattern": "^SKU-"},
+//		}, nil
+//	})
+func RegisterParamConstraint(name string, factory ParamConstraintFactory) {
+	if name == "" || factory == nil {
+		return
+	}
+	paramConstraints.Lock()
+	defer paramConstraints.Unlock()
+	paramConstraints.factories[name] = factory
+}
+
+func lookupParamConstraint(name string) (ParamConstraintFactory, bool) {
+	paramConstraints.RLock()
+	defer paramConstraints.RUnlock()
+	factory, ok := paramConstraints.factories[name]
+	return factory, ok
+}
+
+// routeParamConstraint holds the compiled constraints of one path parameter.
+type routeParamConstraint struct {
+	name        string
+	constraints []ParamConstraint
+}
+
+// routeParamConstraints are the constraints declared in a route path. Adapters
+// register the stripped path natively and check these at match time.
+type routeParamConstraints []routeParamConstraint
+
+// match reports whether every constrained parameter satisfies its constraints.
+func (rc routeParamConstraints) match(param func(name string) string) bool {
+	for _, pc := range rc {
+		value := param(pc.name)
+		for _, constraint := range pc.constraints {
+			if !constraint.Match(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parameters describes the constrained parameters for OpenAPI output.
+func (rc routeParamConstraints) parameters() []Parameter {
+	if len(rc) == 0 {
+		return nil
+	}
+	params := make([]Parameter, 0, len(rc))
+	for _, pc := range rc {
+		schema := map[string]any{}
+		for _, constraint := range pc.constraints {
+			for key, value := range constraint.Schema {
+				// The first constraint that declares a type wins, so
+				// "<int;enum(1,2)>" stays an integer.
+				if _, exists := schema[key]; key == "type" && exists {
+					continue
+				}
+				schema[key] = value
+			}
+		}
+		if _, ok := schema["type"]; !ok {
+			schema["type"] = "string"
+		}
+		params = append(params, Parameter{
+			Name:     pc.name,
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	return params
+}
+
+// compileRouteParamConstraints compiles the constraints declared in path.
+func compileRouteParamConstraints(path string) (routeParamConstraints, error) {
+	if !strings.Contains(path, "<") {
+		return nil, nil
+	}
+
+	var compiled routeParamConstraints
+	for _, segment := range splitPathSegments(path) {
+		name, raw, ok := parseParamSegment(segment)
+		if !ok || raw == "" {
+			continue
+		}
+		constraints, err := compileParamConstraints(raw)
+		if err != nil {
+			return nil, fmt.Errorf("param %q: %w", name, err)
+		}
+		compiled = append(compiled, routeParamConstraint{name: name, constraints: constraints})
+	}
+	return compiled, nil
+}
+
+// mustCompileRouteParamConstraints panics on unknown or malformed constraints,
+// matching how the underlying routers treat invalid patterns.
+func mustCompileRouteParamConstraints(method HTTPMethod, path string) routeParamConstraints {
+	compiled, err := compileRouteParamConstraints(path)
+	if err != nil {
+		panic(newParamConstraintError(method, path, err))
+	}
+	return compiled
+}
+
+// compileParamConstraints compiles a ";" separated constraint list such as
+// "int;min(1)".
+func compileParamConstraints(raw string) ([]ParamConstraint, error) {
+	specs, err := splitParamConstraints(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := make([]ParamConstraint, 0, len(specs))
+	for _, spec := range specs {
+		name, arg := spec, ""
+		if idx := strings.IndexByte(spec, '('); idx >= 0 {
+			if !strings.HasSuffix(spec, ")") {
+				return nil, fmt.Errorf("constraint %q: missing closing parenthesis", spec)
+			}
+			name, arg = spec[:idx], spec[idx+1:len(spec)-1]
+		}
+		factory, ok := lookupParamConstraint(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown constraint %q", name)
+		}
+		constraint, err := factory(arg)
+		if err != nil {
+			return nil, fmt.Errorf("constraint %q: %w", spec, err)
+		}
+		if constraint.Match == nil {
+			return nil, fmt.Errorf("constraint %q: factory returned no matcher", spec)
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints, nil
+}
+
+// splitParamConstraints splits on ";" outside parentheses so regex arguments
+// may contain separators.
+func splitParamConstraints(raw string) ([]string, error) {
+	var specs []string
+	depth, start := 0, 0
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("constraint %q: unbalanced parenthesis", raw)
+			}
+		case ';':
+			if depth == 0 {
+				specs = append(specs, strings.TrimSpace(raw[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("constraint %q: unbalanced parenthesis", raw)
+	}
+	specs = append(specs, strings.TrimSpace(raw[start:]))
+	for _, spec := range specs {
+		if spec == "" {
+			return nil, fmt.Errorf("constraint %q: empty entry", raw)
+		}
+	}
+	return specs, nil
+}
+
+// stripParamConstraints removes "<...>" declarations from param segments so
+// the path can be registered with routers that do not understand them.
+func stripParamConstraints(path string) string {
+	if !strings.Contains(path, "<") {
+		return path
+	}
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if name, constraint, ok := parseParamSegment(part); ok && constraint != "" {
+			parts[i] = ":" + name
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func newParamConstraintError(method HTTPMethod, path string, err error) error {
+	message := fmt.Sprintf("route %s %s: invalid path constraint: %v", method, path, err)
+	return goerrors.Wrap(err, goerrors.CategoryBadInput, message).
+		WithCode(http.StatusInternalServerError).
+		WithTextCode("ROUTE_PARAM_CONSTRAINT").
+		WithMetadata(map[string]any{
+			"method": method,
+			"path":   path,
+		})
+}
+
+func noArgs(name string, arg string) error {
+	if arg != "" {
+		return fmt.Errorf("%s takes no arguments", name)
+	}
+	return nil
+}
+
+func intConstraint(arg string) (ParamConstraint, error) {
+	if err := noArgs("int", arg); err != nil {
+		return ParamConstraint{}, err
+	}
+	return ParamConstraint{
+		Match: func(v string) bool {
+			_, err := strconv.ParseInt(v, 10, 64)
+			return err == nil
+		},
+		Schema: map[string]any{"type": "integer", "format": "int64"},
+	}, nil
+}
+
+func uintConstraint(arg string) (ParamConstraint, error) {
+	if err := noArgs("uint", arg); err != nil {
+		return ParamConstraint{}, err
+	}
+	return ParamConstraint{
+		Match: func(v string) bool {
+			_, err := strconv.ParseUint(v, 10, 64)
+			return err == nil
+		},
+		Schema: map[string]any{"type": "integer", "format": "int64", "minimum": 0},
+	}, nil
+}
+
+func boolConstraint(arg string) (ParamConstraint, error) {
+	if err := noArgs("bool", arg); err != nil {
+		return ParamConstraint{}, err
+	}
+	return ParamConstraint{
+		Match: func(v string) bool {
+			_, err := strconv.ParseBool(v)
+			return err == nil
+		},
+		Schema: map[string]any{"type": "boolean"},
+	}, nil
+}
+
+func floatConstraint(arg string) (ParamConstraint, error) {
+	if err := noArgs("float", arg); err != nil {
+		return ParamConstraint{}, err
+	}
+	return ParamConstraint{
+		Match: func(v string) bool {
+			_, err := strconv.ParseFloat(v, 64)
+			return err == nil
+		},
+		Schema: map[string]any{"type": "number"},
+	}, nil
+}
+
+func patternConstraint(pattern string, schema map[string]any) ParamConstraintFactory {
+	re := regexp.MustCompile(pattern)
+	return func(arg string) (ParamConstraint, error) {
+		if arg != "" {
+			return ParamConstraint{}, fmt.Errorf("takes no arguments")
+		}
+		s := map[string]any{"type": "string", "pattern": pattern}
+		maps.Copy(s, schema)
+		return ParamConstraint{Match: re.MatchString, Schema: s}, nil
+	}
+}
+
+func regexConstraint(arg string) (ParamConstraint, error) {
+	if arg == "" {
+		return ParamConstraint{}, fmt.Errorf("regex requires an expression")
+	}
+	pattern := "^(?:" + arg + ")$"
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ParamConstraint{}, err
+	}
+	return ParamConstraint{
+		Match:  re.MatchString,
+		Schema: map[string]any{"type": "string", "pattern": pattern},
+	}, nil
+}
+
+func parseIntArgs(arg string, count int) ([]int64, error) {
+	parts := strings.Split(arg, ",")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d integer argument(s), got %q", count, arg)
+	}
+	values := make([]int64, count)
+	for i, part := range parts {
+		value, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer argument %q", part)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// boundConstraint compares the parameter as an integer, like Fiber's min and
+// max constraints.
+func boundConstraint(kind string) ParamConstraintFactory {
+	return func(arg string) (ParamConstraint, error) {
+		args, err := parseIntArgs(arg, 1)
+		if err != nil {
+			return ParamConstraint{}, err
+		}
+		bound := args[0]
+		schemaKey := "minimum"
+		if kind == "max" {
+			schemaKey = "maximum"
+		}
+		return ParamConstraint{
+			Match: func(v string) bool {
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return false
+				}
+				if kind == "max" {
+					return n <= bound
+				}
+				return n >= bound
+			},
+			Schema: map[string]any{"type": "integer", schemaKey: bound},
+		}, nil
+	}
+}
+
+func rangeConstraint(arg string) (ParamConstraint, error) {
+	args, err := parseIntArgs(arg, 2)
+	if err != nil {
+		return ParamConstraint{}, err
+	}
+	lo, hi := args[0], args[1]
+	return ParamConstraint{
+		Match: func(v string) bool {
+			n, err := strconv.ParseInt(v, 10, 64)
+			return err == nil && n >= lo && n <= hi
+		},
+		Schema: map[string]any{"type": "integer", "minimum": lo, "maximum": hi},
+	}, nil
+}
+
+func lengthConstraint(kind string) ParamConstraintFactory {
+	return func(arg string) (ParamConstraint, error) {
+		args, err := parseIntArgs(arg, 1)
+		if err != nil {
+			return ParamConstraint{}, err
+		}
+		n := int(args[0])
+		schema := map[string]any{"type": "string"}
+		switch kind {
+		case "minLen":
+			schema["minLength"] = n
+		case "maxLen":
+			schema["maxLength"] = n
+		default:
+			schema["minLength"], schema["maxLength"] = n, n
+		}
+		return ParamConstraint{
+			Match: func(v string) bool {
+				count := utf8.RuneCountInString(v)
+				switch kind {
+				case "minLen":
+					return count >= n
+				case "maxLen":
+					return count <= n
+				default:
+					return count == n
+				}
+			},
+			Schema: schema,
+		}, nil
+	}
+}
+
+func betweenLenConstraint(arg string) (ParamConstraint, error) {
+	args, err := parseIntArgs(arg, 2)
+	if err != nil {
+		return ParamConstraint{}, err
+	}
+	lo, hi := int(args[0]), int(args[1])
+	return ParamConstraint{
+		Match: func(v string) bool {
+			count := utf8.RuneCountInString(v)
+			return count >= lo && count <= hi
+		},
+		Schema: map[string]any{"type": "string", "minLength": lo, "maxLength": hi},
+	}, nil
+}
+
+// datetimeConstraint parses the value with a Go time layout, e.g.
+// "datetime(2006-01-02)".
+func datetimeConstraint(arg string) (ParamConstraint, error) {
+	if arg == "" {
+		return ParamConstraint{}, fmt.Errorf("datetime requires a layout")
+	}
+	schema := map[string]any{"type": "string"}
+	switch arg {
+	case time.DateOnly:
+		schema["format"] = "date"
+	case time.RFC3339:
+		schema["format"] = "date-time"
+	}
+	return ParamConstraint{
+		Match: func(v string) bool {
+			_, err := time.Parse(arg, v)
+			return err == nil
+		},
+		Schema: schema,
+	}, nil
+}
+
+// enumConstraint accepts one of a comma separated list of values, e.g.
+// "enum(draft,published)".
+func enumConstraint(arg string) (ParamConstraint, error) {
+	values := make([]string, 0)
+	for value := range strings.SplitSeq(arg, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return ParamConstraint{}, fmt.Errorf("enum requires at least one value")
+	}
+	allowed := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		allowed[value] = struct{}{}
+	}
+	return ParamConstraint{
+		Match: func(v string) bool {
+			_, ok := allowed[v]
+			return ok
+		},
+		Schema: map[string]any{"type": "string", "enum": values},
+	}, nil
+}
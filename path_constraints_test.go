@@ -0,0 +1,160 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParamConstraints_Match(t *testing.T) {
+	cases := []struct {
+		constraint string
+		accept     []string
+		reject     []string
+	}{
+		{"int", []string{"42", "-7"}, []string{"4.2", "abc", ""}},
+		{"uint", []string{"0", "42"}, []string{"-1", "x"}},
+		{"uuid", []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567", "not-a-uuid"}},
+		{"hashid", []string{"123e4567-e89b-12d3-a456-426614174000", "KwSysDpxcBU9FNhGkn2dCf"}, []string{"short", "KwSysDpxcBU9FNhGkn2dC0"}},
+		{"slug", []string{"hello-world", "v2"}, []string{"Hello", "a--b", "-a"}},
+		{"alpha", []string{"abc", "ABC"}, []string{"ab1"}},
+		{"regex(\\d{2,3};x)", []string{"12;x", "123;x"}, []string{"1;x", "12"}},
+		{"min(5)", []string{"5", "10"}, []string{"4", "five"}},
+		{"max(5)", []string{"5", "-1"}, []string{"6"}},
+		{"int;range(1,3)", []string{"1", "3"}, []string{"0", "4"}},
+		{"maxLen(3)", []string{"abc", "é"}, []string{"abcd"}},
+		{"enum(draft,published)", []string{"draft", "published"}, []string{"archived", "draft,published"}},
+		{"datetime(2006-01-02)", []string{"2026-10-16"}, []string{"16/10/2026"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.constraint, func(t *testing.T) {
+			constraints, err := compileRouteParamConstraints("/items/:v<" + tc.constraint + ">")
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			for _, value := range tc.accept {
+				if !constraints.match(func(string) string { return value }) {
+					t.Errorf("expected %q to match", value)
+				}
+			}
+			for _, value := range tc.reject {
+				if constraints.match(func(string) string { return value }) {
+					t.Errorf("expected %q to be rejected", value)
+				}
+			}
+		})
+	}
+}
+
+func TestParamConstraints_InvalidDeclarations(t *testing.T) {
+	for _, path := range []string{
+		"/items/:id<nope>",
+		"/items/:id<min(x)>",
+		"/items/:id<regex([)>",
+		"/items/:id<int;>",
+		"/items/:id<int(3)>",
+	} {
+		if _, err := compileRouteParamConstraints(path); err == nil {
+			t.Errorf("expected %s to fail", path)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registration with an unknown constraint to panic")
+		}
+	}()
+	NewHTTPServer().Router().Get("/items/:id<nope>", func(c Context) error { return nil })
+}
+
+func TestRegisterParamConstraint(t *testing.T) {
+	RegisterParamConstraint("sku", func(string) (ParamConstraint, error) {
+		return ParamConstraint{
+			Match:  func(v string) bool { return strings.HasPrefix(v, "SKU-") },
+			Schema: map[string]any{"pattern": "^SKU-"},
+		}, nil
+	})
+	t.Cleanup(func() {
+		paramConstraints.Lock()
+		delete(paramConstraints.factories, "sku")
+		paramConstraints.Unlock()
+	})
+
+	app := NewHTTPServer()
+	app.Router().Get("/products/:sku<sku>", func(c Context) error { return c.SendString(c.Param("sku")) })
+
+	for path, want := range map[string]int{"/products/SKU-1": http.StatusOK, "/products/1": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		app.WrappedRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestParamConstraints_FiberFallsThroughToLaterRoutes(t *testing.T) {
+	app := NewFiberAdapter()
+	r := app.Router()
+	r.Get("/users/:id<int>", func(c Context) error { return c.SendString("user " + c.Param("id")) })
+	r.Get("/users/new", func(c Context) error { return c.SendString("new") })
+	app.Init()
+
+	for path, want := range map[string]string{"/users/7": "user 7", "/users/new": "new"} {
+		res, err := app.WrappedRouter().Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != fiber.StatusOK || string(body) != want {
+			t.Errorf("GET %s = %d %q, want %q", path, res.StatusCode, body, want)
+		}
+	}
+}
+
+func TestParamConstraints_OpenAPIParameters(t *testing.T) {
+	app := NewHTTPServer().(*HTTPServer)
+	r := app.Router()
+	r.Get("/orders/:id<int;min(1)>/:state<enum(open,closed)>", func(c Context) error {
+		return c.SendString("ok")
+	}).AddParameter("state", "path", true, map[string]any{"type": "string", "enum": []string{"open", "closed"}, "description": "override"})
+
+	renderer := NewOpenAPIRenderer()
+	ServeOpenAPI(r, renderer)
+
+	rec := httptest.NewRecorder()
+	app.WrappedRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var payload struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name   string         `json:"name"`
+				In     string         `json:"in"`
+				Schema map[string]any `json:"schema"`
+			} `json:"parameters"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode openapi document: %v", err)
+	}
+	op, ok := payload.Paths["/orders/{id}/{state}"]["get"]
+	if !ok {
+		t.Fatalf("expected /orders/{id}/{state} in paths, got %v", payload.Paths)
+	}
+	if len(op.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %+v", op.Parameters)
+	}
+	id := op.Parameters[0]
+	if id.Name != "id" || id.In != "path" || id.Schema["type"] != "integer" || id.Schema["minimum"] != float64(1) {
+		t.Fatalf("unexpected id parameter: %+v", id)
+	}
+	if op.Parameters[1].Schema["description"] != "override" {
+		t.Fatalf("expected explicit parameter to replace the derived one: %+v", op.Parameters[1])
+	}
+}
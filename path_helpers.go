@@ -65,8 +65,10 @@ func PathParam(name string) string {
 	return ":" + name
 }
 
-// ConstrainedPathParam returns a parameter segment with a constraint.
-// Note: constraint syntax is Fiber-specific; httprouter treats it as part of the param name.
+// ConstrainedPathParam returns a parameter segment with a constraint, e.g.
+// ConstrainedPathParam("id", "int;min(1)") returns ":id<int;min(1)>".
+// Constraints are resolved through RegisterParamConstraint and enforced at
+// match time by every adapter; see RegisterParamConstraint for custom ones.
 func ConstrainedPathParam(name, constraint string) string {
 	if constraint == "" {
 		return ":" + name
@@ -0,0 +1,331 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm selects how a quota counts requests.
+type RateLimitAlgorithm string
+
+const (
+	// RateLimitTokenBucket refills Limit tokens evenly over Window and lets
+	// clients spend up to Burst of them at once.
+	RateLimitTokenBucket RateLimitAlgorithm = "token_bucket"
+	// RateLimitSlidingWindow allows Limit requests in any Window, weighting
+	// the previous window by how much of it still overlaps.
+	RateLimitSlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// RateLimitQuota is the number of requests a client may make in a window.
+type RateLimitQuota struct {
+	// Limit zero or less leaves requests unlimited.
+	Limit int `json:"limit"`
+	// Window defaults to one minute.
+	Window time.Duration `json:"window"`
+	// Burst is the token bucket size. Defaults to Limit.
+	Burst int `json:"burst,omitempty"`
+	// Algorithm defaults to RateLimitTokenBucket.
+	Algorithm RateLimitAlgorithm `json:"algorithm,omitempty"`
+}
+
+func (q RateLimitQuota) withDefaults() RateLimitQuota {
+	if q.Window <= 0 {
+		q.Window = time.Minute
+	}
+	if q.Algorithm == "" {
+		q.Algorithm = RateLimitTokenBucket
+	}
+	if q.Burst <= 0 || q.Algorithm != RateLimitTokenBucket {
+		q.Burst = q.Limit
+	}
+	return q
+}
+
+// RateLimitResult is the state of a quota after a request was counted.
+type RateLimitResult struct {
+	Allowed bool
+	// Limit is the Limit of the quota, also for token buckets with a larger
+	// Burst, as RateLimit-Limit and RateLimit-Policy report the same quota.
+	Limit int
+	// Remaining is how many requests may still be made, at most Limit.
+	Remaining int
+	// Reset is when the quota is fully available again.
+	Reset time.Duration
+	// RetryAfter is when a rejected request may be retried.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore counts requests per key. Implement it over a shared backend,
+// such as Redis, to enforce quotas across instances. Take must count the
+// request and report the result atomically.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, quota RateLimitQuota, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	Skip func(Context) bool
+	// Quota applies to routes without a quota of their own, see SetRateLimit.
+	// The zero quota only limits routes that declare one.
+	Quota RateLimitQuota
+	// KeyFunc returns the client a request counts against. Defaults to
+	// RateLimitByIP.
+	KeyFunc func(Context) string
+	// Store defaults to a new InMemoryRateLimitStore.
+	Store RateLimitStore
+	// OnLimited answers rejected requests. Defaults to returning a
+	// NewTooManyRequestsError for the error handler to render.
+	OnLimited func(Context, RateLimitResult) error
+	// Logger logs store failures, which let the request through.
+	Logger Logger
+}
+
+// SetRateLimit sets the quota of a single route, in place of the quota of the
+// RateLimit middleware. Requests to the route are counted apart from other
+// routes, and OpenAPI documents show the quota as x-rate-limit.
+func SetRateLimit(ri RouteInfo, quota RateLimitQuota) RouteInfo {
+	if route, ok := ri.(*RouteDefinition); ok {
+		quota = quota.withDefaults()
+		route.RateLimit = &quota
+	}
+	return ri
+}
+
+// RateLimit returns a middleware that rejects requests over their quota with
+// 429 Too Many Requests. Responses carry the RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers, and
+// rejections Retry-After:
+//
+//	app.Router().Use(router.RateLimit(router.RateLimitConfig{
+//		Quota:   router.RateLimitQuota{Limit: 100, Window: time.Minute},
+//		KeyFunc: router.RateLimitByHeader("X-API-Key"),
+//	}))
+func RateLimit(config ...RateLimitConfig) MiddlewareFunc {
+	var cfg RateLimitConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	cfg.Quota = cfg.Quota.withDefaults()
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = RateLimitByIP
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewInMemoryRateLimitStore()
+	}
+	if cfg.OnLimited == nil {
+		cfg.OnLimited = func(_ Context, result RateLimitResult) error {
+			return NewTooManyRequestsError("rate limit exceeded", map[string]any{
+				"limit":       result.Limit,
+				"retry_after": ceilSeconds(result.RetryAfter),
+			})
+		}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+			quota := cfg.Quota
+			key := cfg.KeyFunc(c)
+			if route, ok := routeFromContext(c.Context()); ok && route.RateLimit != nil {
+				quota = *route.RateLimit
+				key = string(route.Method) + " " + route.Path + "|" + key
+			}
+			if quota.Limit <= 0 {
+				return next(c)
+			}
+
+			result, err := cfg.Store.Take(c.Context(), key, quota, time.Now())
+			if err != nil {
+				if cfg.Logger != nil {
+					cfg.Logger.Warn("rate limit store failed, allowing request: %v", err)
+				}
+				return next(c)
+			}
+			c.SetHeader("RateLimit-Limit", strconv.Itoa(quota.Limit))
+			c.SetHeader("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			c.SetHeader("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			c.SetHeader("RateLimit-Policy", fmt.Sprintf("%d;w=%d", quota.Limit, ceilSeconds(quota.Window)))
+			if !result.Allowed {
+				c.SetHeader("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				return cfg.OnLimited(c, result)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RateLimitByIP counts requests by client IP.
+func RateLimitByIP(c Context) string {
+	return "ip:" + c.IP()
+}
+
+// RateLimitByHeader counts requests by the value of a header, such as an API
+// key, and by client IP when the header is missing.
+func RateLimitByHeader(name string) func(Context) string {
+	return func(c Context) string {
+		if value := c.Header(name); value != "" {
+			return "header:" + value
+		}
+		return RateLimitByIP(c)
+	}
+}
+
+// RateLimitByLocal counts requests by the value an authentication middleware
+// stored in the locals under key, such as the user or service ID, and by
+// client IP for anonymous requests.
+func RateLimitByLocal(key any) func(Context) string {
+	return func(c Context) string {
+		if value := c.Locals(key); value != nil {
+			if s := fmt.Sprint(value); s != "" {
+				return "local:" + s
+			}
+		}
+		return RateLimitByIP(c)
+	}
+}
+
+// RateLimitByRoute counts requests of each route separately, keyed further by
+// keyFunc. Requests outside a named route count by their path.
+func RateLimitByRoute(keyFunc func(Context) string) func(Context) string {
+	return func(c Context) string {
+		name := c.RouteName()
+		if name == "" {
+			name = c.Method() + " " + c.Path()
+		}
+		return "route:" + name + "|" + keyFunc(c)
+	}
+}
+
+// InMemoryRateLimitStore keeps quotas in process memory. Idle keys are
+// dropped once their requests no longer count.
+type InMemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	nextSweep time.Time
+}
+
+type rateLimitEntry struct {
+	// tokens and last hold the token bucket.
+	tokens float64
+	last   time.Time
+	// windowStart, current and previous hold the sliding window counts.
+	windowStart time.Time
+	current     int
+	previous    int
+	expires     time.Time
+}
+
+// NewInMemoryRateLimitStore returns an empty in-memory store.
+func NewInMemoryRateLimitStore() *InMemoryRateLimitStore {
+	return &InMemoryRateLimitStore{entries: make(map[string]*rateLimitEntry)}
+}
+
+var _ RateLimitStore = (*InMemoryRateLimitStore)(nil)
+
+// Take counts a request for key. See RateLimitStore. Quotas without a Limit
+// allow the request without counting it.
+func (s *InMemoryRateLimitStore) Take(_ context.Context, key string, quota RateLimitQuota, now time.Time) (RateLimitResult, error) {
+	if quota.Limit <= 0 {
+		return RateLimitResult{Allowed: true}, nil
+	}
+	quota = quota.withDefaults()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{tokens: float64(quota.Burst), last: now, windowStart: now}
+		s.entries[key] = entry
+	}
+	var result RateLimitResult
+	if quota.Algorithm == RateLimitSlidingWindow {
+		result = entry.takeSlidingWindow(quota, now)
+	} else {
+		result = entry.takeTokenBucket(quota, now)
+	}
+	// Sliding windows weigh the counts of a window through the next one.
+	entry.expires = now.Add(result.Reset + quota.Window)
+	return result, nil
+}
+
+// sweep drops the entries no longer counting requests, once a minute.
+func (s *InMemoryRateLimitStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(time.Minute)
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (e *rateLimitEntry) takeTokenBucket(quota RateLimitQuota, now time.Time) RateLimitResult {
+	perToken := quota.Window / time.Duration(quota.Limit)
+	capacity := float64(quota.Burst)
+	e.tokens = math.Min(capacity, e.tokens+float64(now.Sub(e.last))/float64(perToken))
+	e.last = now
+
+	result := RateLimitResult{Limit: quota.Limit}
+	if e.tokens >= 1 {
+		e.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - e.tokens) * float64(perToken))
+	}
+	result.Remaining = min(int(e.tokens), quota.Limit)
+	result.Reset = time.Duration((capacity - e.tokens) * float64(perToken))
+	return result
+}
+
+func (e *rateLimitEntry) takeSlidingWindow(quota RateLimitQuota, now time.Time) RateLimitResult {
+	if elapsed := now.Sub(e.windowStart); elapsed >= quota.Window {
+		windows := elapsed / quota.Window
+		e.previous = e.current
+		if windows > 1 {
+			e.previous = 0
+		}
+		e.current = 0
+		e.windowStart = e.windowStart.Add(windows * quota.Window)
+	}
+	elapsed := now.Sub(e.windowStart)
+	overlap := 1 - float64(elapsed)/float64(quota.Window)
+	count := float64(e.previous)*overlap + float64(e.current)
+
+	result := RateLimitResult{Limit: quota.Limit, Reset: quota.Window - elapsed}
+	if count+1 <= float64(quota.Limit) {
+		e.current++
+		count++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = e.retryAfter(quota, elapsed)
+	}
+	result.Remaining = max(0, quota.Limit-int(math.Ceil(count)))
+	return result
+}
+
+// retryAfter is when the weighted count leaves room for one more request.
+func (e *rateLimitEntry) retryAfter(quota RateLimitQuota, elapsed time.Duration) time.Duration {
+	room := float64(quota.Limit - 1)
+	if e.current <= quota.Limit-1 {
+		// The previous window has to decay until previous*overlap fits.
+		overlap := (room - float64(e.current)) / float64(e.previous)
+		return time.Duration((1-overlap)*float64(quota.Window)) - elapsed
+	}
+	// The current window becomes the previous one first.
+	overlap := room / float64(e.current)
+	return quota.Window - elapsed + time.Duration((1-overlap)*float64(quota.Window))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
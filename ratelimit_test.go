@@ -0,0 +1,128 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func TestInMemoryRateLimitStore_Algorithms(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	store := router.NewInMemoryRateLimitStore()
+
+	bucket := router.RateLimitQuota{Limit: 2, Window: time.Second}
+	for i := range 2 {
+		result, err := store.Take(ctx, "bucket", bucket, start)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+	result, _ := store.Take(ctx, "bucket", bucket, start)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, time.Second, result.Reset)
+	result, _ = store.Take(ctx, "bucket", bucket, start.Add(500*time.Millisecond))
+	assert.True(t, result.Allowed, "a token is refilled every half second")
+
+	window := router.RateLimitQuota{Limit: 2, Window: time.Minute, Algorithm: router.RateLimitSlidingWindow}
+	for range 2 {
+		result, _ = store.Take(ctx, "window", window, start)
+		assert.True(t, result.Allowed)
+	}
+	result, _ = store.Take(ctx, "window", window, start)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 90*time.Second, result.RetryAfter)
+	result, _ = store.Take(ctx, "window", window, start.Add(time.Minute))
+	assert.False(t, result.Allowed, "the previous window still counts in full")
+	result, _ = store.Take(ctx, "window", window, start.Add(90*time.Second))
+	assert.True(t, result.Allowed, "half of the previous window overlaps")
+	assert.Equal(t, 0, result.Remaining)
+
+	for _, limit := range []int{0, -1} {
+		for _, algorithm := range []router.RateLimitAlgorithm{router.RateLimitTokenBucket, router.RateLimitSlidingWindow} {
+			result, err := store.Take(ctx, "unlimited", router.RateLimitQuota{Limit: limit, Algorithm: algorithm}, start)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed, "quotas without a limit allow every request")
+		}
+	}
+}
+
+func TestRateLimit_HeadersQuotasAndOpenAPI(t *testing.T) {
+	setup := routertest.Setup{
+		Fiber:      rateLimitRoutes,
+		HTTPRouter: rateLimitRoutes,
+		ServeMux:   rateLimitRoutes,
+		FiberApp:   fiberAPIApp,
+	}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		for _, remaining := range []string{"1", "0"} {
+			client.Get("/ping").Do(t).
+				AssertStatus(http.StatusOK).
+				AssertHeader("RateLimit-Limit", "2").
+				AssertHeader("RateLimit-Remaining", remaining).
+				AssertHeader("RateLimit-Policy", "2;w=60")
+		}
+		client.Get("/ping").Do(t).
+			AssertStatus(http.StatusTooManyRequests).
+			AssertHeader("Retry-After", "30").
+			AssertBodyContains("TOO_MANY_REQUESTS")
+
+		for _, remaining := range []string{"2", "2", "1", "0"} {
+			res := client.Get("/burst").Do(t).
+				AssertStatus(http.StatusOK).
+				AssertHeader("RateLimit-Remaining", remaining)
+			limit, _, _ := strings.Cut(res.Header.Get("RateLimit-Policy"), ";")
+			assert.Equal(t, res.Header.Get("RateLimit-Limit"), limit, "both headers report the quota limit")
+			assert.Equal(t, "2", limit)
+		}
+		client.Get("/burst").Do(t).AssertStatus(http.StatusTooManyRequests)
+
+		client.Get("/search").Do(t).
+			AssertStatus(http.StatusOK).
+			AssertHeader("RateLimit-Policy", "1;w=3600")
+		client.Get("/search").Do(t).AssertStatus(http.StatusTooManyRequests)
+	})
+
+	app := router.NewHTTPServer()
+	rateLimitRoutes(app.Router())
+	doc := router.NewOpenAPIRenderer().AppenRouteInfo(app.Router().Routes()).GenerateOpenAPI()
+	paths := doc["paths"].(map[string]any)
+	assert.Equal(t, map[string]any{"limit": 1, "window": 3600, "burst": 1, "algorithm": "sliding_window"},
+		paths["/search"].(map[string]any)["get"].(map[string]any)["x-rate-limit"])
+	assert.NotContains(t, paths["/ping"].(map[string]any)["get"], "x-rate-limit")
+}
+
+func rateLimitRoutes[T any](r router.Router[T]) {
+	r.Use(router.RateLimit(router.RateLimitConfig{Quota: router.RateLimitQuota{Limit: 2, Window: time.Minute}}))
+	r.Get("/ping", ping)
+	router.SetRateLimit(r.Get("/burst", ping), router.RateLimitQuota{Limit: 2, Window: time.Minute, Burst: 4})
+	router.SetRateLimit(r.Get("/search", ping), router.RateLimitQuota{
+		Limit:     1,
+		Window:    time.Hour,
+		Algorithm: router.RateLimitSlidingWindow,
+	})
+}
+
+// fiberAPIApp returns a Fiber app whose error handler renders router errors
+// with their status on every path, as the other adapters do.
+func fiberAPIApp(*fiber.App) *fiber.App {
+	return fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ErrorHandler:          router.DefaultFiberErrorHandler(router.FiberErrorHandlerConfig{APIPrefix: "/"}),
+	})
+}
+
+// newFiberAPIAdapter returns a Fiber adapter built on fiberAPIApp.
+func newFiberAPIAdapter() router.Server[*fiber.App] {
+	return router.NewFiberAdapter(fiberAPIApp)
+}
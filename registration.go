@@ -90,6 +90,15 @@ type RoutingCapabilities struct {
 	RouteNamePolicy bool `json:"route_name_policy"`
 	OwnershipChecks bool `json:"ownership_checks"`
 	Manifest        bool `json:"manifest"`
+	// PathConflictMode is the effective treatment of static and parameter
+	// siblings at the same depth.
+	PathConflictMode PathConflictMode `json:"path_conflict_mode,omitempty"`
+	// CatchAllSiblings reports whether a catch-all route may coexist with
+	// narrower routes under the same prefix.
+	CatchAllSiblings bool `json:"catch_all_siblings"`
+	// OrderIndependent reports whether dispatch selects the most specific
+	// route regardless of declaration order.
+	OrderIndependent bool `json:"order_independent"`
 }
 
 type RoutingCapabilityProvider interface {
@@ -161,7 +170,7 @@ func AnalyzeRouteShadowsWithSemantics(routes []RouteDefinition, semantics RouteM
 	for routeIndex, route := range routes {
 		for earlierIndex := range routeIndex {
 			earlier := routes[earlierIndex]
-			if earlier.Method != route.Method || !routePatternContainsWithSemantics(earlier.Path, route.Path, semantics) {
+			if earlier.Method != route.Method || routeVariantsOf(&earlier, &route) || !routePatternContainsWithSemantics(earlier.Path, route.Path, semantics) {
 				continue
 			}
 			kind := RouteShadowSameRequestSet
@@ -295,11 +304,5 @@ func newRegistrationError(operation string, method HTTPMethod, path string, stat
 }
 
 func newRouteNotFoundError(method HTTPMethod, path string) error {
-	return &RegistrationError{
-		Operation: "replace route",
-		Method:    method,
-		Path:      path,
-		State:     RegistrationCollecting,
-		Err:       fmt.Errorf("%w: %s %s", ErrRouteNotFound, method, path),
-	}
+	return newRouteMutationNotFoundError("replace route", method, path, RegistrationCollecting)
 }
@@ -37,6 +37,15 @@ func CaptureResponse(base Context, maxBodySize int64, handler HandlerFunc) (*Cap
 	return capture.CapturedResponse()
 }
 
+// CaptureNext runs the rest of c's handler chain and returns the response it
+// wrote instead of sending it. Middleware can inspect the result and send it
+// with ReplayCapturedResponse.
+func CaptureNext(c Context, maxBodySize int64) (*CapturedResponse, error) {
+	return CaptureResponse(c, maxBodySize, func(capture Context) error {
+		return capture.Next()
+	})
+}
+
 func ReplayCapturedResponse(ctx Context, captured *CapturedResponse) error {
 	if ctx == nil || captured == nil {
 		return nil
@@ -94,6 +103,26 @@ type contextDelegate struct {
 	Context
 }
 
+// chainStepper is implemented by adapter contexts that run a handler chain.
+// It lets wrapping contexts call the next handler with themselves.
+type chainStepper interface {
+	nextHandler() (HandlerFunc, bool)
+}
+
+// Next runs the next handler of the base chain against the capture context,
+// so downstream writes are captured as well.
+func (c *responseCaptureContext) Next() error {
+	stepper, ok := c.contextDelegate.Context.(chainStepper)
+	if !ok {
+		return c.contextDelegate.Context.Next()
+	}
+	handler, ok := stepper.nextHandler()
+	if !ok {
+		return nil
+	}
+	return handler(c)
+}
+
 func (c *responseCaptureContext) Context() context.Context {
 	return c.contextDelegate.Context.Context()
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
 )
 
 func TestCaptureResponseCapturesAndReplaysNonStream(t *testing.T) {
@@ -288,3 +289,37 @@ func TestCaptureResponseRejectsStreams(t *testing.T) {
 		t.Fatalf("expected ErrResponseCaptureStream, got %v", err)
 	}
 }
+
+func TestCaptureNextCapturesTheRestOfTheChain(t *testing.T) {
+	setup := routertest.Setup{Fiber: captureNextRoutes, HTTPRouter: captureNextRoutes}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		client.Get("/hello").Do(t).
+			AssertStatus(http.StatusOK).
+			AssertHeader("X-Tagged", "yes").
+			AssertBody("HELLO")
+	})
+}
+
+func captureNextRoutes[T any](r router.Router[T]) {
+	upper := func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx router.Context) error {
+			captured, err := router.CaptureNext(ctx, 1024)
+			if err != nil {
+				return err
+			}
+			captured.Body = []byte(strings.ToUpper(string(captured.Body)))
+			return router.ReplayCapturedResponse(ctx, captured)
+		}
+	}
+	tag := func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx router.Context) error {
+			ctx.SetHeader("X-Tagged", "yes")
+			return ctx.Next()
+		}
+	}
+	hello := func(ctx router.Context) error {
+		return ctx.SendString("hello")
+	}
+
+	r.Get("/hello", hello, upper, tag)
+}
@@ -0,0 +1,362 @@
+//go:build unix
+
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// restartListenersEnv names the listeners a restarting parent passed, in
+	// descriptor order from 3.
+	restartListenersEnv = "GO_ROUTER_LISTENERS"
+	// restartReadyEnv is the descriptor the child reports readiness on.
+	restartReadyEnv = "GO_ROUTER_READY_FD"
+)
+
+// RestartConfig configures a GracefulRestart.
+type RestartConfig struct {
+	// Server is shut down once the new process is ready, for example the
+	// adapter or an http.Server. Required for Restart.
+	Server Shutdowner
+	// Signals trigger a restart in Run. Defaults to SIGHUP and SIGUSR2.
+	Signals []os.Signal
+	// Path is the binary to start. Defaults to os.Args[0], looked up at
+	// restart time, so a binary replaced on disk is the one started.
+	Path string
+	// Args are the arguments of the new process. Defaults to os.Args[1:].
+	Args []string
+	// Env is added to the environment of the new process.
+	Env []string
+	// Stdout and Stderr receive the output of the new process. They default
+	// to those of the current process.
+	Stdout io.Writer
+	Stderr io.Writer
+	// ReadyTimeout bounds the wait for the new process to call Ready.
+	// Defaults to 30 seconds.
+	ReadyTimeout time.Duration
+	// ShutdownTimeout bounds the shutdown of Server. Zero waits until the
+	// context passed to Run or Restart ends.
+	ShutdownTimeout time.Duration
+	// Logger logs restarts when set.
+	Logger Logger
+}
+
+// GracefulRestart replaces the running binary without closing its listening
+// sockets. On a restart it starts the new binary with the sockets, waits
+// until the new process reports it is ready and then shuts the old one down,
+// so established connections drain while new ones reach the new process.
+//
+// Both processes run the same setup. Listeners come from Listener, so the new
+// process takes them over instead of binding again, and Ready is called once
+// the server is about to accept requests:
+//
+//	restart := router.NewGracefulRestart(router.RestartConfig{Server: app})
+//	ln, err := restart.Listener("http", func() (net.Listener, error) {
+//		return net.Listen("tcp", ":8080")
+//	})
+//	app.(router.LifecycleHooks).OnListen(func(router.ListenInfo) error { return restart.Ready() })
+//	go app.(router.ListenerServer).ServeListener(ln)
+//
+//	err = restart.Run(ctx) // returns once a new process took over
+//
+// Connections already open, including WebSockets, stay with the old process
+// until its Shutdown closes them.
+type GracefulRestart struct {
+	config RestartConfig
+
+	mu        sync.Mutex
+	names     []string
+	listeners []net.Listener
+	inherited map[string]net.Listener
+
+	inheritOnce sync.Once
+	inheritErr  error
+	readyOnce   sync.Once
+	readyErr    error
+}
+
+// NewGracefulRestart returns a GracefulRestart. In a process started by a
+// restart, it takes over the listeners of the parent.
+func NewGracefulRestart(config ...RestartConfig) *GracefulRestart {
+	cfg := RestartConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
+	}
+	if cfg.Path == "" {
+		cfg.Path = os.Args[0]
+	}
+	if cfg.Args == nil {
+		cfg.Args = slices.Clone(os.Args[1:])
+	}
+	if cfg.ReadyTimeout <= 0 {
+		cfg.ReadyTimeout = 30 * time.Second
+	}
+	if cfg.Stdout == nil {
+		cfg.Stdout = os.Stdout
+	}
+	if cfg.Stderr == nil {
+		cfg.Stderr = os.Stderr
+	}
+	return &GracefulRestart{config: cfg}
+}
+
+// Listener returns the listener named name that the parent passed, or the
+// one listen creates when there is none. Either way it is passed on by the
+// next restart.
+func (r *GracefulRestart) Listener(name string, listen func() (net.Listener, error)) (net.Listener, error) {
+	if err := r.inherit(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name == "" || strings.Contains(name, ",") {
+		return nil, fmt.Errorf("invalid restart listener name %q", name)
+	}
+	if slices.Contains(r.names, name) {
+		return nil, fmt.Errorf("restart listener %q already registered", name)
+	}
+	ln, ok := r.inherited[name]
+	if ok {
+		delete(r.inherited, name)
+	} else {
+		var err error
+		if ln, err = listen(); err != nil {
+			return nil, err
+		}
+	}
+	r.names = append(r.names, name)
+	r.listeners = append(r.listeners, ln)
+	return ln, nil
+}
+
+// inherit opens the listeners passed by the parent.
+func (r *GracefulRestart) inherit() error {
+	r.inheritOnce.Do(func() {
+		value, ok := os.LookupEnv(restartListenersEnv)
+		if !ok {
+			return
+		}
+		_ = os.Unsetenv(restartListenersEnv)
+		r.inherited = map[string]net.Listener{}
+		if value == "" {
+			return
+		}
+		for i, name := range strings.Split(value, ",") {
+			file := os.NewFile(uintptr(systemdFirstFD+i), name)
+			ln, err := net.FileListener(file)
+			_ = file.Close()
+			if err != nil {
+				r.inheritErr = fmt.Errorf("inherited listener %q: %w", name, err)
+				return
+			}
+			r.inherited[name] = ln
+		}
+	})
+	return r.inheritErr
+}
+
+// Ready tells the parent that the process serves requests, so the parent
+// shuts down. It does nothing when the process was not started by a restart
+// and may be called more than once.
+func (r *GracefulRestart) Ready() error {
+	r.readyOnce.Do(func() {
+		value, ok := os.LookupEnv(restartReadyEnv)
+		if !ok {
+			return
+		}
+		_ = os.Unsetenv(restartReadyEnv)
+		fd, err := strconv.Atoi(value)
+		if err != nil {
+			r.readyErr = fmt.Errorf("invalid %s %q", restartReadyEnv, value)
+			return
+		}
+		pipe := os.NewFile(uintptr(fd), "ready")
+		_, err = pipe.Write([]byte{1})
+		r.readyErr = errors.Join(err, pipe.Close())
+	})
+	return r.readyErr
+}
+
+// Run restarts on the first configured signal and returns once the new
+// process took over and the server shut down. A failed restart is logged and
+// the process keeps serving until the next signal. Run returns the context
+// error when ctx ends first.
+func (r *GracefulRestart) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, r.config.Signals...)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-signals:
+			r.logInfo("restarting on %s", sig)
+			err := r.Restart(ctx)
+			if err == nil {
+				return nil
+			}
+			if r.config.Logger != nil {
+				r.config.Logger.Error("restart failed: %v", err)
+			}
+		}
+	}
+}
+
+// Restart starts the new process, waits until it is ready and shuts the
+// server down. When the new process does not become ready, it is killed and
+// the server keeps serving.
+func (r *GracefulRestart) Restart(ctx context.Context) error {
+	if r.config.Server == nil {
+		return errors.New("restart requires a server")
+	}
+	child, ready, err := r.startChild()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+
+	readyC := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := ready.Read(buf); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("new process exited before it was ready")
+			}
+			readyC <- err
+			return
+		}
+		readyC <- nil
+	}()
+
+	timer := time.NewTimer(r.config.ReadyTimeout)
+	defer timer.Stop()
+	select {
+	case err = <-readyC:
+	case <-timer.C:
+		err = fmt.Errorf("new process not ready after %s", r.config.ReadyTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = child.Process.Kill()
+		_ = child.Wait()
+		return err
+	}
+	r.logInfo("new process %d is ready, shutting down", child.Process.Pid)
+	// The new process outlives this one; release it instead of waiting.
+	_ = child.Process.Release()
+
+	// Closing the handed over listeners must not remove socket files the new
+	// process serves.
+	r.mu.Lock()
+	for _, ln := range r.listeners {
+		if unix, ok := ln.(*net.UnixListener); ok {
+			unix.SetUnlinkOnClose(false)
+		}
+	}
+	r.mu.Unlock()
+
+	shutdownCtx := ctx
+	if r.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(ctx, r.config.ShutdownTimeout)
+		defer cancel()
+	}
+	return r.config.Server.Shutdown(shutdownCtx)
+}
+
+// startChild starts the new process with the listeners and the write end of
+// a readiness pipe, and returns the read end.
+func (r *GracefulRestart) startChild() (*exec.Cmd, *os.File, error) {
+	path, err := exec.LookPath(r.config.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.mu.Lock()
+	names := slices.Clone(r.names)
+	files := make([]*os.File, 0, len(r.listeners)+1)
+	for i, ln := range r.listeners {
+		filer, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			r.mu.Unlock()
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("restart listener %q (%T) has no file descriptor", names[i], ln)
+		}
+		file, err := filer.File()
+		if err != nil {
+			r.mu.Unlock()
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("restart listener %q: %w", names[i], err)
+		}
+		files = append(files, file)
+	}
+	r.mu.Unlock()
+	defer closeFiles(files)
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer readyW.Close()
+	files = append(files, readyW)
+
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, restartListenersEnv+"=") || strings.HasPrefix(kv, restartReadyEnv+"=")
+	})
+	env = append(env, r.config.Env...)
+	env = append(env,
+		restartListenersEnv+"="+strings.Join(names, ","),
+		restartReadyEnv+"="+strconv.Itoa(systemdFirstFD+len(names)),
+	)
+
+	cmd := exec.Command(path, r.config.Args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = r.config.Stdout
+	cmd.Stderr = r.config.Stderr
+	cmd.ExtraFiles = files
+	err = cmd.Start()
+	// A descriptor passed to a process is put in blocking mode, and the
+	// listeners share that mode with their copies. Restore it, or the
+	// accept loop of the server would block and Shutdown hang.
+	for _, file := range files[:len(names)] {
+		_ = syscall.SetNonblock(int(file.Fd()), true)
+	}
+	if err != nil {
+		_ = readyR.Close()
+		return nil, nil, fmt.Errorf("start new process: %w", err)
+	}
+	return cmd, readyR, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		_ = file.Close()
+	}
+}
+
+func (r *GracefulRestart) logInfo(format string, args ...any) {
+	if r.config.Logger != nil {
+		r.config.Logger.Info(format, args...)
+	}
+}
@@ -0,0 +1,134 @@
+//go:build unix
+
+package router_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+)
+
+const restartHelperEnv = "GO_ROUTER_RESTART_HELPER"
+
+// TestGracefulRestartHelperProcess is the new process started by the restart
+// tests. It serves the inherited listener until /stop is requested.
+func TestGracefulRestartHelperProcess(t *testing.T) {
+	if os.Getenv(restartHelperEnv) != "1" {
+		t.Skip("helper process for the restart tests")
+	}
+	restart := router.NewGracefulRestart()
+	ln, err := restart.Listener("http", func() (net.Listener, error) {
+		return nil, errors.New("listener not inherited")
+	})
+	require.NoError(t, err)
+
+	app := router.NewHTTPServer()
+	stop := make(chan struct{})
+	app.Router().Get("/who", func(c router.Context) error {
+		return c.SendString(fmt.Sprintf("child %d", os.Getpid()))
+	})
+	app.Router().Get("/stop", func(c router.Context) error {
+		close(stop)
+		return c.SendString("bye")
+	})
+	app.(router.LifecycleHooks).OnListen(func(router.ListenInfo) error { return restart.Ready() })
+	go app.(router.ListenerServer).ServeListener(ln)
+
+	select {
+	case <-stop:
+	case <-time.After(10 * time.Second):
+	}
+	_ = app.Shutdown(context.Background())
+}
+
+func restartTestClient() *http.Client {
+	return &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+}
+
+func TestGracefulRestart_HandsListenersToNewProcess(t *testing.T) {
+	for _, tc := range lifecycleAdapters() {
+		t.Run(tc.name, func(t *testing.T) {
+			app := tc.server()
+			restart := router.NewGracefulRestart(router.RestartConfig{
+				Server:       app,
+				Path:         os.Args[0],
+				Args:         []string{"-test.run=^TestGracefulRestartHelperProcess$"},
+				Env:          []string{restartHelperEnv + "=1"},
+				ReadyTimeout: 10 * time.Second,
+				Stdout:       io.Discard,
+				Stderr:       io.Discard,
+			})
+			ln, err := restart.Listener("http", func() (net.Listener, error) {
+				return net.Listen("tcp", "127.0.0.1:0")
+			})
+			require.NoError(t, err)
+			base := "http://" + ln.Addr().String()
+
+			served := make(chan error, 1)
+			go func() { served <- app.(router.ListenerServer).ServeListener(ln) }()
+			client := restartTestClient()
+			require.Eventually(t, func() bool {
+				res, err := client.Get(base + "/ping")
+				if err == nil {
+					res.Body.Close()
+				}
+				return err == nil
+			}, time.Second, 10*time.Millisecond)
+
+			require.NoError(t, restart.Restart(context.Background()))
+			select {
+			case err := <-served:
+				if err != nil {
+					assert.ErrorIs(t, err, http.ErrServerClosed)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("the old server did not shut down")
+			}
+
+			who := getBody(t, client, base+"/who")
+			assert.True(t, strings.HasPrefix(who, "child "), who)
+			assert.NotEqual(t, fmt.Sprintf("child %d", os.Getpid()), who)
+			assert.Equal(t, "bye", getBody(t, client, base+"/stop"))
+		})
+	}
+}
+
+func TestGracefulRestart_KeepsServingWhenNewProcessFails(t *testing.T) {
+	app := router.NewHTTPServer()
+	app.Router().Get("/who", func(c router.Context) error { return c.SendString("parent") })
+
+	restart := router.NewGracefulRestart(router.RestartConfig{
+		Server: app,
+		Path:   os.Args[0],
+		// Runs no test, so the new process exits without calling Ready.
+		Args:   []string{"-test.run=^$"},
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	})
+	ln, err := restart.Listener("http", func() (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	})
+	require.NoError(t, err)
+	go app.(router.ListenerServer).ServeListener(ln)
+	defer app.Shutdown(context.Background())
+
+	err = restart.Restart(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exited before it was ready")
+	assert.Equal(t, "parent", getBody(t, restartTestClient(), "http://"+ln.Addr().String()+"/who"))
+
+	_, err = restart.Listener("http", nil)
+	assert.Error(t, err, "listener names are unique")
+}
@@ -3,9 +3,11 @@ package router
 import "sort"
 
 type RouteManifestEntry struct {
-	Method HTTPMethod `json:"method"`
-	Path   string     `json:"path"`
-	Name   string     `json:"name"`
+	Method  HTTPMethod `json:"method"`
+	Path    string     `json:"path"`
+	Name    string     `json:"name"`
+	Host    string     `json:"host,omitempty"`
+	Version string     `json:"version,omitempty"`
 }
 
 type RouteManifestChange struct {
@@ -38,9 +40,11 @@ func buildRouteManifest(routes []RouteDefinition, includeInternalNames bool) []R
 			name = route.Name
 		}
 		manifest[i] = RouteManifestEntry{
-			Method: route.Method,
-			Path:   route.Path,
-			Name:   name,
+			Method:  route.Method,
+			Path:    route.Path,
+			Name:    name,
+			Host:    route.Host,
+			Version: route.Version,
 		}
 	}
 	sortRouteManifestEntries(manifest)
@@ -176,6 +180,12 @@ func sortRouteManifestEntries(entries []RouteManifestEntry) {
 		if entries[i].Method != entries[j].Method {
 			return entries[i].Method < entries[j].Method
 		}
+		if entries[i].Host != entries[j].Host {
+			return entries[i].Host < entries[j].Host
+		}
+		if entries[i].Version != entries[j].Version {
+			return entries[i].Version < entries[j].Version
+		}
 		return entries[i].Name < entries[j].Name
 	})
 }
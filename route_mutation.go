@@ -0,0 +1,342 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// RouteRemover removes an exact route declaration, while collecting or after
+// the router started serving. The error wraps ErrRouteNotFound when method
+// and path name no route.
+type RouteRemover interface {
+	TryRemove(method HTTPMethod, path string) error
+}
+
+// RouteTransactor is implemented by routers that apply several route changes
+// as one step. See RouteMutation.
+type RouteTransactor interface {
+	BeginRouteMutation() *RouteMutation
+}
+
+// ErrRouteMutationDone is returned when a RouteMutation is committed twice or
+// after it was discarded.
+var ErrRouteMutationDone = errors.New("route mutation already finished")
+
+// RouteMutation stages additions, replacements and removals of routes and
+// applies them together. Paths are relative to the router that began the
+// mutation, and added routes take its middleware, as with Handle:
+//
+//	m := r.(router.RouteTransactor).BeginRouteMutation()
+//	m.Remove(router.GET, "/reports/legacy")
+//	m.Add(router.GET, "/reports/:id", showReport).SetName("reports.show")
+//	diff, err := m.Commit()
+//
+// Commit validates the resulting plan with ValidateRouteDefinitionsWithOptions
+// and changes nothing when a route conflicts or is missing. Once the router is
+// serving, the new route table is swapped in at once: requests already
+// dispatched finish on the routes they started with, and later requests see
+// every change or none.
+type RouteMutation struct {
+	mu     sync.Mutex
+	br     *BaseRouter
+	target routeMutationTarget
+	ops    []routeMutationOp
+	done   bool
+}
+
+// routeMutationTarget is implemented by the adapters.
+type routeMutationTarget interface {
+	// mutationPath returns the full path of path in the mutating router.
+	mutationPath(path string) string
+	routeValidationOptions() RouteValidationOptions
+	// mountMutation mounts an added route and records it as mounted.
+	// Callers hold the root lock.
+	mountMutation(route *RouteDefinition) error
+}
+
+type routeMutationKind int
+
+const (
+	routeMutationAdd routeMutationKind = iota
+	routeMutationReplace
+	routeMutationRemove
+)
+
+type routeMutationOp struct {
+	kind        routeMutationKind
+	method      HTTPMethod
+	path        string
+	handler     HandlerFunc
+	options     RouteMutationOptions
+	middlewares []MiddlewareFunc
+	// route is the declaration an add stages.
+	route *RouteDefinition
+}
+
+func newRouteMutation(br *BaseRouter, target routeMutationTarget) *RouteMutation {
+	return &RouteMutation{br: br, target: target}
+}
+
+// Add stages a new route. The returned RouteInfo describes the route until
+// Commit; names set on it are registered by Commit.
+func (m *RouteMutation) Add(method HTTPMethod, path string, handler HandlerFunc, middlewares ...MiddlewareFunc) RouteInfo {
+	fullPath := m.target.mutationPath(path)
+	route := m.br.newRoute(method, fullPath, handler, "", m.br.buildNamedMiddlewares(middlewares))
+	m.stage(routeMutationOp{kind: routeMutationAdd, method: method, path: fullPath, route: route})
+	return route
+}
+
+// Replace stages a new handler for an existing route. The route keeps its
+// name, metadata and middleware, followed by middlewares.
+func (m *RouteMutation) Replace(method HTTPMethod, path string, handler HandlerFunc, middlewares ...MiddlewareFunc) {
+	m.ReplaceWithOptions(method, path, handler, RouteMutationOptions{}, middlewares...)
+}
+
+// ReplaceWithOptions is Replace with the middleware control of
+// RouteMutator.TryReplaceWithOptions.
+func (m *RouteMutation) ReplaceWithOptions(method HTTPMethod, path string, handler HandlerFunc, options RouteMutationOptions, middlewares ...MiddlewareFunc) {
+	m.stage(routeMutationOp{
+		kind:        routeMutationReplace,
+		method:      method,
+		path:        m.target.mutationPath(path),
+		handler:     handler,
+		options:     options,
+		middlewares: middlewares,
+	})
+}
+
+// Remove stages the removal of an existing route.
+func (m *RouteMutation) Remove(method HTTPMethod, path string) {
+	m.stage(routeMutationOp{kind: routeMutationRemove, method: method, path: m.target.mutationPath(path)})
+}
+
+// Discard drops the staged changes.
+func (m *RouteMutation) Discard() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.done = true
+	m.ops = nil
+}
+
+func (m *RouteMutation) stage(op routeMutationOp) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ops = append(m.ops, op)
+}
+
+// Commit applies the staged changes in order and returns how they changed the
+// route manifest. Errors from OnRouteMounted hooks are returned with the diff
+// of the applied changes.
+//
+//nolint:gocyclo,funlen // Planning, validation and the swap share one critical section.
+func (m *RouteMutation) Commit() (diff RouteManifestDiff, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done {
+		return RouteManifestDiff{}, ErrRouteMutationDone
+	}
+	m.done = true
+
+	root := m.br.root
+	// Runs after the unlock below.
+	defer func() { err = errors.Join(err, root.announceRoutes()) }()
+	root.registrationMu.Lock()
+	defer root.registrationMu.Unlock()
+	state := root.registrationState()
+
+	// Plan the new declarations. origin maps a replacement to the live route
+	// it replaces.
+	plan := slices.Clone(root.routes)
+	origin := make(map[*RouteDefinition]*RouteDefinition)
+	for _, op := range m.ops {
+		i := slices.IndexFunc(plan, func(route *RouteDefinition) bool {
+			return m.br.isSameRoute(route, op.method, op.path)
+		})
+		switch {
+		case op.kind == routeMutationAdd && i >= 0:
+			conflict := &routeConflict{existing: plan[i], reason: "duplicate route", index: -1}
+			err := newRouteConflictError(op.method, op.path, conflict, HTTPRouterConflictPanic, m.target.routeValidationOptions().PathConflictMode)
+			return RouteManifestDiff{}, newRegistrationError("add route", op.method, op.path, state, err)
+		case op.kind == routeMutationAdd:
+			plan = append(plan, op.route)
+		case i < 0:
+			operation := "replace route"
+			if op.kind == routeMutationRemove {
+				operation = "remove route"
+			}
+			return RouteManifestDiff{}, newRouteMutationNotFoundError(operation, op.method, op.path, state)
+		case op.kind == routeMutationReplace:
+			current := plan[i]
+			next := m.replacement(current, op)
+			if live, ok := origin[current]; ok {
+				origin[next] = live
+			} else if slices.Contains(root.routes, current) {
+				origin[next] = current
+			}
+			plan[i] = next
+		default:
+			plan = slices.Delete(plan, i, i+1)
+		}
+	}
+
+	var added, removed []*RouteDefinition
+	replaced := make(map[*RouteDefinition]*RouteDefinition)
+	for _, route := range plan {
+		if live := origin[route]; live != nil {
+			replaced[live] = route
+		} else if !slices.Contains(root.routes, route) {
+			added = append(added, route)
+		}
+	}
+	for _, route := range root.routes {
+		if _, ok := replaced[route]; !ok && !slices.Contains(plan, route) {
+			removed = append(removed, route)
+		}
+	}
+
+	if errs := validateAddedRoutes(plan, added, m.target.routeValidationOptions()); len(errs) > 0 {
+		return RouteManifestDiff{}, newRegistrationError("commit route mutation", "", "", state, errors.Join(errs...))
+	}
+
+	// Edit a copy of the route table, so a failed mount leaves the live one.
+	before := cloneRouteDefinitions(root.routes)
+	mounted := slices.Clone(root.mountedRoutes)
+	deferred := slices.Clone(root.deferredRoutes)
+	announced := len(root.announced)
+	root.beginTableEdit()
+	for _, route := range removed {
+		root.detachRoute(route)
+		for _, alias := range root.inheritedRoutes(route) {
+			root.detachRoute(alias)
+		}
+	}
+	aliases := make(map[*RouteDefinition]*RouteDefinition)
+	for route, next := range replaced {
+		root.swapRoute(route, next)
+		for _, alias := range root.inheritedRoutes(route) {
+			inherited := *alias
+			inherited.Handlers = next.Handlers
+			inherited.middlewares = next.middlewares
+			inherited.inheritedFrom = next
+			root.swapRoute(alias, &inherited)
+			aliases[alias] = &inherited
+		}
+	}
+	maps.Copy(replaced, aliases)
+	for _, route := range added {
+		if err := m.target.mountMutation(route); err != nil {
+			root.discardTable()
+			root.mountedRoutes, root.deferredRoutes = mounted, deferred
+			root.announced = root.announced[:announced]
+			return RouteManifestDiff{}, newRegistrationError("add route", route.Method, route.Path, state, err)
+		}
+	}
+
+	root.routes = plan
+	root.mountedRoutes = slices.DeleteFunc(root.mountedRoutes, func(route *RouteDefinition) bool {
+		return slices.Contains(removed, route) || slices.Contains(removed, route.inheritedFrom)
+	})
+	root.deferredRoutes = slices.DeleteFunc(root.deferredRoutes, func(route *RouteDefinition) bool {
+		return slices.Contains(removed, route)
+	})
+	for i, route := range root.mountedRoutes {
+		if next, ok := replaced[route]; ok {
+			root.mountedRoutes[i] = next
+		}
+	}
+	for i, route := range root.deferredRoutes {
+		if next, ok := replaced[route]; ok {
+			root.deferredRoutes[i] = next
+		}
+	}
+	m.updateNames(added, removed, replaced)
+	root.publishTable()
+	root.revision++
+
+	return DiffRouteManifests(BuildRouteManifest(before), BuildRouteManifest(cloneRouteDefinitions(plan))), nil
+}
+
+// replacement returns a copy of route serving op.handler.
+func (m *RouteMutation) replacement(route *RouteDefinition, op routeMutationOp) *RouteDefinition {
+	next := *route
+	allMw := slices.Clone(route.middlewares)
+	if op.options.ReplaceMiddleware {
+		allMw = slices.Clone(m.br.middlewares)
+	}
+	for _, mw := range op.middlewares {
+		allMw = append(allMw, namedMiddleware{Name: funcName(mw), Mw: mw})
+	}
+	next.middlewares = allMw
+	next.Handlers = chainHandlers(op.handler, route.Name, allMw)
+	return &next
+}
+
+// updateNames moves the public names of a committed mutation. Callers hold
+// the root lock and have installed the new plan.
+func (m *RouteMutation) updateNames(added, removed []*RouteDefinition, replaced map[*RouteDefinition]*RouteDefinition) {
+	root := m.br.root
+	for _, binding := range root.namedRoutes {
+		if next, ok := replaced[binding.Route]; ok {
+			binding.Route = next
+		}
+	}
+	for _, route := range removed {
+		m.br.removePublicRouteBinding(route)
+		m.br.clearNamedRouteConflict(route)
+	}
+	for _, route := range added {
+		if name := route.effectivePublicName(); name != "" {
+			if err := m.br.registerNamedRoute(route, name, routeNameModePublic); err != nil && m.br.logger != nil {
+				m.br.logger.Warn("route mutation name skipped: %v", err)
+			}
+		}
+	}
+}
+
+// inheritedRoutes returns the mounted copies newer path versions serve of
+// route.
+func (root *routerRoot) inheritedRoutes(route *RouteDefinition) []*RouteDefinition {
+	var out []*RouteDefinition
+	for _, mounted := range root.mountedRoutes {
+		if mounted.inheritedFrom == route {
+			out = append(out, mounted)
+		}
+	}
+	return out
+}
+
+// validateAddedRoutes checks each added route against the rest of plan. Only
+// pairs with an added route are checked, so conflicts the router already
+// accepted do not block unrelated changes.
+func validateAddedRoutes(plan, added []*RouteDefinition, opts RouteValidationOptions) []error {
+	var errs []error
+	for i, route := range added {
+		for _, other := range plan {
+			if other == route || slices.Contains(added[i:], other) {
+				continue
+			}
+			errs = append(errs, ValidateRouteDefinitionsWithOptions([]*RouteDefinition{other, route}, opts)...)
+		}
+	}
+	return errs
+}
+
+// tryRemoveRoute implements RouteRemover with a single-change mutation.
+func tryRemoveRoute(m *RouteMutation, method HTTPMethod, path string) error {
+	m.Remove(method, path)
+	_, err := m.Commit()
+	return err
+}
+
+func newRouteMutationNotFoundError(operation string, method HTTPMethod, path string, state RegistrationState) error {
+	return &RegistrationError{
+		Operation: operation,
+		Method:    method,
+		Path:      path,
+		State:     state,
+		Err:       fmt.Errorf("%w: %s %s", ErrRouteNotFound, method, path),
+	}
+}
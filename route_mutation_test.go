@@ -0,0 +1,167 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func registerMutationRoutes[T any](r router.Router[T]) {
+	r.Get("/reports", func(c router.Context) error { return c.SendString("reports") }).SetName("reports")
+	r.Get("/reports/legacy", func(c router.Context) error { return c.SendString("legacy") }).SetName("reports.legacy")
+	r.Get("/status", func(c router.Context) error { return c.SendString("ok") })
+}
+
+func TestRouteMutation_SwapsServingRouteTable(t *testing.T) {
+	fiberApp := router.NewFiberAdapter()
+	registerMutationRoutes(fiberApp.Router())
+	httpApp := router.NewHTTPServer()
+	registerMutationRoutes(httpApp.Router())
+	muxApp := router.NewServeMuxServer()
+	registerMutationRoutes(muxApp.Router())
+
+	for name, tc := range map[string]struct {
+		client *routertest.Client
+		router any
+	}{
+		"fiber":      {routertest.NewClient(fiberApp), fiberApp.Router()},
+		"httprouter": {routertest.NewClient(httpApp), httpApp.Router()},
+		"servemux":   {routertest.NewClient(muxApp), muxApp.Router()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := tc.client
+			client.Get("/reports/legacy").Do(t).AssertStatus(http.StatusOK)
+			inspector := tc.router.(router.RegistrationInspector)
+			before := inspector.RegistrationSnapshot()
+			require.Equal(t, router.RegistrationSealed, before.State)
+
+			require.NoError(t, tc.router.(router.RouteRemover).TryRemove(router.GET, "/reports/legacy"))
+			client.Get("/reports/legacy").Do(t).AssertStatus(http.StatusNotFound)
+			err := tc.router.(router.RouteRemover).TryRemove(router.GET, "/reports/legacy")
+			assert.ErrorIs(t, err, router.ErrRouteNotFound)
+
+			m := tc.router.(router.RouteTransactor).BeginRouteMutation()
+			m.Add(router.GET, "/plugins/:id", func(c router.Context) error {
+				return c.SendString("plugin " + c.Param("id"))
+			}).SetName("plugins.show")
+			m.Add(router.GET, "/reports/legacy", func(c router.Context) error { return c.SendString("legacy again") })
+			m.Replace(router.GET, "/reports", func(c router.Context) error { return c.SendString("reports v2") })
+			m.Remove(router.GET, "/status")
+			diff, err := m.Commit()
+			require.NoError(t, err)
+
+			client.Get("/plugins/7").Do(t).AssertStatus(http.StatusOK).AssertBody("plugin 7")
+			client.Get("/reports/legacy").Do(t).AssertStatus(http.StatusOK).AssertBody("legacy again")
+			client.Get("/reports").Do(t).AssertStatus(http.StatusOK).AssertBody("reports v2")
+			client.Get("/status").Do(t).AssertStatus(http.StatusNotFound)
+
+			assert.ElementsMatch(t, []router.RouteManifestEntry{
+				{Method: router.GET, Path: "/plugins/:id", Name: "plugins.show"},
+				{Method: router.GET, Path: "/reports/legacy"},
+			}, diff.Added)
+			assert.Equal(t, []router.RouteManifestEntry{{Method: router.GET, Path: "/status"}}, diff.Removed)
+
+			after := inspector.RegistrationSnapshot()
+			assert.Greater(t, after.Revision, before.Revision)
+			assert.Len(t, after.DeclaredRoutes, 3)
+			paths := make([]string, 0, len(after.MountedRoutes))
+			for _, route := range after.MountedRoutes {
+				paths = append(paths, route.Path)
+			}
+			assert.NotContains(t, paths, "/status")
+			assert.Contains(t, paths, "/plugins/:id")
+
+			u, err := tc.router.(router.URLBuilder).URLFor("plugins.show", map[string]any{"id": 3}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "/plugins/3", u)
+			_, err = tc.router.(router.URLBuilder).URLFor("reports.legacy", nil, nil)
+			assert.Error(t, err, "removed routes lose their names")
+		})
+	}
+}
+
+func TestRouteMutation_FailedCommitChangesNothing(t *testing.T) {
+	app := router.NewHTTPServer()
+	r := app.Router()
+	registerMutationRoutes(r)
+	client := routertest.NewClient(app)
+	client.Get("/status").Do(t).AssertStatus(http.StatusOK)
+	revision := r.(router.RegistrationInspector).RegistrationSnapshot().Revision
+
+	m := r.(router.RouteTransactor).BeginRouteMutation()
+	m.Remove(router.GET, "/status")
+	m.Add(router.GET, "/reports/:id", func(c router.Context) error { return nil })
+	_, err := m.Commit()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ROUTE_CONFLICT")
+
+	m = r.(router.RouteTransactor).BeginRouteMutation()
+	m.Remove(router.GET, "/status")
+	m.Replace(router.GET, "/missing", func(c router.Context) error { return nil })
+	_, err = m.Commit()
+	assert.ErrorIs(t, err, router.ErrRouteNotFound)
+	_, err = m.Commit()
+	assert.ErrorIs(t, err, router.ErrRouteMutationDone)
+
+	client.Get("/status").Do(t).AssertStatus(http.StatusOK)
+	assert.Equal(t, revision, r.(router.RegistrationInspector).RegistrationSnapshot().Revision)
+}
+
+func TestRouteMutation_InFlightRequestsFinish(t *testing.T) {
+	app := router.NewHTTPServer()
+	r := app.Router()
+	started, release := make(chan struct{}), make(chan struct{})
+	r.Get("/slow", func(c router.Context) error {
+		close(started)
+		<-release
+		return c.SendString("done")
+	})
+	handler := app.WrappedRouter()
+
+	res := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-started
+	require.NoError(t, r.(router.RouteRemover).TryRemove(router.GET, "/slow"))
+	close(release)
+	<-finished
+	assert.Equal(t, "done", res.Body.String())
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestRouteMutation_WhileCollecting(t *testing.T) {
+	app := router.NewFiberAdapter()
+	r := app.Router()
+	registerMutationRoutes(r)
+	api := r.Group("/api")
+	api.Get("/users", func(c router.Context) error { return c.SendString("users") })
+
+	remover := api.(router.RouteRemover)
+	require.NoError(t, remover.TryRemove(router.GET, "/users"))
+	var regErr *router.RegistrationError
+	err := remover.TryRemove(router.GET, "/users")
+	require.True(t, errors.As(err, &regErr))
+	assert.Equal(t, router.RegistrationCollecting, regErr.State)
+
+	m := api.(router.RouteTransactor).BeginRouteMutation()
+	m.Add(router.GET, "/users", func(c router.Context) error { return c.SendString("users v2") })
+	_, err = m.Commit()
+	require.NoError(t, err)
+
+	client := routertest.NewClient(app)
+	client.Get("/api/users").Do(t).AssertStatus(http.StatusOK).AssertBody("users v2")
+	assert.Len(t, router.BuildRouterManifest(r), 4)
+}
@@ -0,0 +1,291 @@
+package router
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// routeScope is what Host and Version groups add to the routes they declare,
+// besides the path prefix and middleware every group contributes.
+type routeScope struct {
+	host    *hostPattern
+	version *apiVersion
+	// versionPrefix is the group prefix in front of the version segment of
+	// path versioned routes.
+	versionPrefix string
+}
+
+// scoped reports whether the scope restricts routes to a host or version.
+func (s routeScope) scoped() bool {
+	return s.host != nil || s.version != nil
+}
+
+// routeRequest is what route resolution reads from a request before the
+// adapter context exists.
+type routeRequest struct {
+	hostname string
+	header   func(name string) string
+	param    func(name string) string
+}
+
+// routeMount is a method and path an adapter mounted once. The routes it
+// dispatches, which differ by host or version, are held by the route table so
+// a sealed router can change them.
+type routeMount struct {
+	root   *routerRoot
+	method HTTPMethod
+	path   string
+	// overlay marks a mount the adapter matches itself because its native
+	// router no longer accepts routes.
+	overlay bool
+}
+
+// routeTable is the dispatch state adapters read per request. A sealed router
+// never modifies the published table but swaps in an edited copy, so a request
+// sees all of a mutation or none of it.
+type routeTable struct {
+	routes map[*routeMount][]*RouteDefinition
+	// overlay lists the overlay mounts, most specific path first.
+	overlay []*routeMount
+}
+
+func (t *routeTable) clone() *routeTable {
+	return &routeTable{routes: maps.Clone(t.routes), overlay: t.overlay}
+}
+
+// draftTable returns the table registration edits, creating it on first use.
+// Until the router is sealed that is the live table; afterwards it is a copy
+// that publishTable swaps in. Callers hold the root lock.
+func (root *routerRoot) draftTable() *routeTable {
+	if root.draft != nil {
+		return root.draft
+	}
+	live := root.table.Load()
+	if live == nil {
+		live = &routeTable{routes: map[*routeMount][]*RouteDefinition{}}
+		root.table.Store(live)
+	}
+	if root.registrationState() != RegistrationSealed {
+		return live
+	}
+	root.draft = live.clone()
+	return root.draft
+}
+
+// beginTableEdit starts a draft even while collecting, so the edits of a
+// failed route mutation can be discarded.
+func (root *routerRoot) beginTableEdit() {
+	root.draft = root.draftTable().clone()
+}
+
+// publishTable swaps in the draft, if any.
+func (root *routerRoot) publishTable() {
+	if root.draft != nil {
+		root.table.Store(root.draft)
+		root.draft = nil
+	}
+}
+
+func (root *routerRoot) discardTable() {
+	root.draft = nil
+}
+
+// compareRouteVariants orders variants by host specificity, keeping the
+// routes of one host pattern next to each other.
+func compareRouteVariants(a, b *RouteDefinition) int {
+	if c := compareHostPatterns(a.host, b.host); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Host, b.Host)
+}
+
+// insertRouteVariant returns a copy of routes with route inserted after the
+// variants that sort before or with it.
+func insertRouteVariant(routes []*RouteDefinition, route *RouteDefinition) []*RouteDefinition {
+	at := len(routes)
+	for i, existing := range routes {
+		if compareRouteVariants(route, existing) < 0 {
+			at = i
+			break
+		}
+	}
+	return slices.Insert(slices.Clone(routes), at, route)
+}
+
+// attachRoute adds route to the route table. A route joins the mount of its
+// method and path when that mount dispatches nothing, as after a removal, or
+// when either side is scoped to a host or version. Otherwise mountNew mounts
+// the path again, which keeps the adapter's own handling of unscoped
+// duplicates. Callers hold the root lock.
+func (root *routerRoot) attachRoute(route *RouteDefinition, mountNew func(*routeMount) error) error {
+	t := root.draftTable()
+	key := string(route.Method) + " " + stripParamConstraints(route.Path)
+	mount := root.mounts[key]
+	if mount != nil {
+		current := t.routes[mount]
+		if len(current) > 0 && !route.scoped() && !slices.ContainsFunc(current, (*RouteDefinition).scoped) {
+			mount = nil
+		}
+	}
+	if mount == nil {
+		mount = &routeMount{root: root, method: route.Method, path: stripParamConstraints(route.Path)}
+		if err := mountNew(mount); err != nil {
+			return err
+		}
+		if root.mounts == nil {
+			root.mounts = make(map[string]*routeMount)
+		}
+		if root.mounts[key] == nil {
+			root.mounts[key] = mount
+		}
+	}
+
+	route.mount = mount
+	t.routes[mount] = insertRouteVariant(t.routes[mount], route)
+	if mount.overlay && !slices.Contains(t.overlay, mount) {
+		overlay := append(slices.Clone(t.overlay), mount)
+		slices.SortStableFunc(overlay, func(a, b *routeMount) int {
+			return compareRouteSpecificity(b.path, a.path)
+		})
+		t.overlay = overlay
+	}
+	return nil
+}
+
+// detachRoute removes route from the route table. Its mount stays, so the
+// path dispatches again once a route with the same method and path returns.
+func (root *routerRoot) detachRoute(route *RouteDefinition) {
+	if route.mount == nil {
+		return
+	}
+	t := root.draftTable()
+	t.routes[route.mount] = slices.DeleteFunc(slices.Clone(t.routes[route.mount]), func(existing *RouteDefinition) bool {
+		return existing == route
+	})
+}
+
+// swapRoute puts next in the place of route in the route table.
+func (root *routerRoot) swapRoute(route, next *RouteDefinition) {
+	next.mount = route.mount
+	if route.mount == nil {
+		return
+	}
+	t := root.draftTable()
+	routes := slices.Clone(t.routes[route.mount])
+	if i := slices.Index(routes, route); i >= 0 {
+		routes[i] = next
+	}
+	t.routes[route.mount] = routes
+}
+
+// resolve returns the route of m that serves req. See resolveRoute.
+func (m *routeMount) resolve(req routeRequest) (*RouteDefinition, map[string]string, bool) {
+	var candidates []*RouteDefinition
+	if t := m.root.table.Load(); t != nil {
+		candidates = t.routes[m]
+	}
+	return resolveRoute(candidates, req)
+}
+
+// resolveRoute returns the route that serves req among candidates, the routes
+// sharing a path on different hosts or versions. The most specific matching
+// host wins, then the version the request negotiates. The second value holds
+// the host parameters.
+func resolveRoute(candidates []*RouteDefinition, req routeRequest) (*RouteDefinition, map[string]string, bool) {
+	for start := 0; start < len(candidates); {
+		end := start + 1
+		for end < len(candidates) && candidates[end].Host == candidates[start].Host {
+			end++
+		}
+		tier := candidates[start:end]
+		start = end
+
+		params, ok := tier[0].host.match(req.hostname)
+		if !ok {
+			continue
+		}
+		if selected := selectVersion(tier, req); selected != nil {
+			return selected, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+// matchOverlay returns the overlay route that serves a request for method
+// and path, with its path and host parameters.
+func (root *routerRoot) matchOverlay(method, path string, req routeRequest) (*RouteDefinition, httprouter.Params, map[string]string, bool) {
+	t := root.table.Load()
+	if t == nil {
+		return nil, nil, nil, false
+	}
+	for _, mount := range t.overlay {
+		if string(mount.method) != method {
+			continue
+		}
+		params, ok := matchMountPath(mount.path, path)
+		if !ok {
+			continue
+		}
+		req.param = params.ByName
+		if route, hostParams, ok := resolveRoute(t.routes[mount], req); ok {
+			return route, params, hostParams, true
+		}
+	}
+	return nil, nil, nil, false
+}
+
+// matchMountPath matches path against a mounted path of static, ":name" and
+// terminal "*name" segments. Catch-all values keep their leading slash, as in
+// httprouter.
+func matchMountPath(pattern, path string) (httprouter.Params, bool) {
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(path, "/")
+	var params httprouter.Params
+	for i, part := range patternParts {
+		switch {
+		case strings.HasPrefix(part, "*"):
+			if i >= len(pathParts) {
+				return nil, false
+			}
+			value := "/" + strings.Join(pathParts[i:], "/")
+			return append(params, httprouter.Param{Key: cmp.Or(part[1:], "*"), Value: value}), true
+		case strings.HasPrefix(part, ":"):
+			name, optional := strings.CutSuffix(part[1:], "?")
+			if i >= len(pathParts) || pathParts[i] == "" {
+				if optional && i >= len(pathParts)-1 {
+					return params, i == len(patternParts)-1
+				}
+				return nil, false
+			}
+			params = append(params, httprouter.Param{Key: name, Value: pathParts[i]})
+		case i >= len(pathParts) || part != pathParts[i]:
+			return nil, false
+		}
+	}
+	return params, len(patternParts) == len(pathParts)
+}
+
+// routeVariantsOf reports whether a and b declare the same path for different
+// hosts or versions, which adapters dispatch per request rather than treat as
+// a conflict.
+func routeVariantsOf(a, b *RouteDefinition) bool {
+	return (a.Host != b.Host || a.Version != b.Version) && stripParamConstraints(a.Path) == stripParamConstraints(b.Path)
+}
+
+// isRouteVariant reports whether route declares fullPath for another host or
+// version than br.
+func (br *BaseRouter) isRouteVariant(route *RouteDefinition, fullPath string) bool {
+	return (route.Host != br.host.String() || route.Version != br.version.String()) &&
+		stripParamConstraints(route.Path) == stripParamConstraints(fullPath)
+}
+
+// isSameRoute reports whether route is the declaration of method and fullPath
+// in the host and version of br.
+func (br *BaseRouter) isSameRoute(route *RouteDefinition, method HTTPMethod, fullPath string) bool {
+	return route.Method == method && route.Path == fullPath &&
+		route.Host == br.host.String() && route.Version == br.version.String()
+}
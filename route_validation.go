@@ -66,7 +66,7 @@ func ValidateRouteDefinitionsWithOptions(routes []*RouteDefinition, opts RouteVa
 		for j := i + 1; j < len(routes); j++ {
 			left := routes[i]
 			right := routes[j]
-			if left.Method != right.Method {
+			if left.Method != right.Method || routeVariantsOf(left, right) {
 				continue
 			}
 
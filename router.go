@@ -23,6 +23,8 @@ type contextKey int
 const (
 	contextKeyRouteName contextKey = iota
 	contextKeyRouteParams
+	contextKeyBindConfig
+	contextKeyRoute
 )
 
 // HTTPMethod represents HTTP request methods
@@ -179,6 +181,12 @@ type Context interface {
 	// Body parsing
 	Bind(v any) error // TODO: Myabe rename to ParseBody
 
+	// Request binding, see binding.go for the supported tags and syntax
+	BindQuery(v any) error
+	BindParams(v any) error
+	BindHeaders(v any) error
+	BindForm(v any) error
+
 	// Context methods
 	Context() context.Context
 	SetContext(context.Context)
@@ -482,3 +490,14 @@ func RouteParamsFromContext(ctx context.Context) (map[string]string, bool) {
 	params, ok := ctx.Value(contextKeyRouteParams).(map[string]string)
 	return params, ok
 }
+
+// withRoute records the route serving a request, for middleware that reads
+// per-route settings.
+func withRoute(ctx context.Context, route *RouteDefinition) context.Context {
+	return context.WithValue(ctx, contextKeyRoute, route)
+}
+
+func routeFromContext(ctx context.Context) (*RouteDefinition, bool) {
+	route, ok := ctx.Value(contextKeyRoute).(*RouteDefinition)
+	return route, ok && route != nil
+}
@@ -7,12 +7,14 @@ import (
 	"io"
 	"io/fs"
 	"mime"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 type routerRoot struct {
@@ -28,6 +30,17 @@ type routerRoot struct {
 	deferredRoutes      []*RouteDefinition
 	deferredRegistered  bool
 	matchingSemantics   RouteMatchingSemantics
+	versions            []*apiVersion
+	baseURL             *url.URL
+	// trustForwardedHeaders lets RequestURLFor read X-Forwarded-* headers.
+	trustForwardedHeaders bool
+	// mounts indexes the first mount of each method and path.
+	mounts map[string]*routeMount
+	table  atomic.Pointer[routeTable]
+	draft  *routeTable
+	// routeHooks receive the routes in announced once the lock is released.
+	routeHooks []func(RouteDefinition) error
+	announced  []*RouteDefinition
 }
 
 func (root *routerRoot) registrationState() RegistrationState {
@@ -75,6 +88,7 @@ func (root *routerRoot) finishFinalization() {
 
 func (root *routerRoot) recordMounted(route *RouteDefinition) {
 	root.mountedRoutes = append(root.mountedRoutes, route)
+	root.announced = append(root.announced, route)
 }
 
 type routeNameMode int
@@ -102,6 +116,8 @@ type BaseRouter struct {
 	root              *routerRoot
 	views             Views
 	passLocalsToViews bool
+	// routeScope restricts the routes of Host and Version groups.
+	routeScope
 }
 
 type namedMiddleware struct {
@@ -168,22 +184,33 @@ func (br *BaseRouter) PrintRoutes() {
 }
 
 func (br *BaseRouter) addRoute(method HTTPMethod, fullPath string, finalHandler HandlerFunc, routeName string, allMw []namedMiddleware) *RouteDefinition {
+	r := br.newRoute(method, fullPath, finalHandler, routeName, allMw)
+	br.root.routes = append(br.root.routes, r)
+	return r
+}
+
+// newRoute builds the declaration of a route in br without adding it to the
+// plan.
+func (br *BaseRouter) newRoute(method HTTPMethod, fullPath string, finalHandler HandlerFunc, routeName string, allMw []namedMiddleware) *RouteDefinition {
 	chain := chainHandlers(finalHandler, routeName, allMw)
+	constraints := mustCompileRouteParamConstraints(method, fullPath)
 	r :=
 		&RouteDefinition{
-			Method:      method,
-			Path:        fullPath,
-			Name:        routeName,
-			Handlers:    chain,
-			middlewares: append([]namedMiddleware(nil), allMw...),
+			Method:           method,
+			Path:             fullPath,
+			Name:             routeName,
+			Handlers:         chain,
+			Host:             br.host.String(),
+			Version:          br.version.String(),
+			Parameters:       constraints.parameters(),
+			middlewares:      append([]namedMiddleware(nil), allMw...),
+			paramConstraints: constraints,
+			routeScope:       br.routeScope,
 		}
 
 	if routeName != "" {
 		br.applyInternalRouteName(r, routeName)
 	}
-
-	br.root.routes = append(br.root.routes, r)
-
 	return r
 }
 
@@ -421,6 +448,7 @@ type lateRoute struct {
 	name    string
 	mode    routeNameMode
 	mw      []MiddlewareFunc
+	scope   routeScope
 }
 
 func (br *BaseRouter) addLateRoute(method HTTPMethod, pathStr string, handler HandlerFunc, routeName string, m ...MiddlewareFunc) {
@@ -442,6 +470,7 @@ func (br *BaseRouter) addLateRouteWithMode(method HTTPMethod, pathStr string, ha
 		name:    routeName,
 		mode:    mode,
 		mw:      m,
+		scope:   br.routeScope,
 	}
 
 	br.root.lateRoutes = append(br.root.lateRoutes, d)
@@ -449,11 +478,17 @@ func (br *BaseRouter) addLateRouteWithMode(method HTTPMethod, pathStr string, ha
 
 type lateRouteRegistrar interface {
 	Handle(method HTTPMethod, path string, handler HandlerFunc, middlewares ...MiddlewareFunc) RouteInfo
+	// withScope returns a registrar for the host or version group a late
+	// route was declared in.
+	withScope(scope routeScope) lateRouteRegistrar
+	// mountFallback mounts a route a version inherits from an older one. The
+	// route is served but not listed among the declared routes.
+	mountFallback(route *RouteDefinition) error
 }
 
 func (br *BaseRouter) registerLateRoutes(reg lateRouteRegistrar) {
 	for _, route := range br.root.lateRoutes {
-		ri := reg.Handle(route.method, route.path, route.handler, route.mw...)
+		ri := reg.withScope(route.scope).Handle(route.method, route.path, route.handler, route.mw...)
 		if route.name != "" {
 			if route.mode == routeNameModeInternal {
 				if def, ok := ri.(*RouteDefinition); ok {
@@ -467,6 +502,7 @@ func (br *BaseRouter) registerLateRoutes(reg lateRouteRegistrar) {
 	if len(br.root.lateRoutes) > 0 {
 		br.root.lateRoutes = br.root.lateRoutes[:0]
 	}
+	br.registerVersionFallbacks(reg)
 }
 
 func (br *BaseRouter) WithLogger(logger Logger) *BaseRouter {
@@ -536,7 +572,8 @@ func (br *BaseRouter) GetRoute(name string) *RouteDefinition {
 
 func (br *BaseRouter) RouteNameFromPath(method string, pathPattern string) (string, bool) {
 	for _, route := range br.root.routes {
-		if route.Method == HTTPMethod(method) && route.Path == pathPattern {
+		// Adapters register paths without constraints, so compare both forms.
+		if route.Method == HTTPMethod(method) && (route.Path == pathPattern || stripParamConstraints(route.Path) == pathPattern) {
 			if route.Name != "" {
 				return route.Name, true
 			}
@@ -661,6 +698,9 @@ func (r *BaseRouter) makeStaticHandler(prefix, root string, config ...Static) (s
 					}
 					f = indexFile
 					filePath = indexPath
+					if stat, err = f.Stat(); err != nil {
+						return c.Status(500).SendString(err.Error())
+					}
 				} else {
 					r.logger.Info("[WARN] public did not find dir in fs")
 					return c.Status(404).SendString("Not Found")
@@ -684,6 +724,24 @@ func (r *BaseRouter) makeStaticHandler(prefix, root string, config ...Static) (s
 			c.SetHeader("Content-Disposition", "attachment; filename="+path.Base(filePath))
 		}
 
+		// Serve a precompressed sibling, such as app.js.br, when the client
+		// accepts its coding.
+		if cfg.Compress && !stat.IsDir() {
+			addVary(c, "Accept-Encoding")
+			for _, encoding := range acceptedEncodings(c.Header("Accept-Encoding"), CompressConfigDefault.Encodings) {
+				sibling, openErr := fileSystem.Open(filePath + staticEncodingExtensions[encoding])
+				if openErr != nil {
+					continue
+				}
+				if closeErr := f.Close(); closeErr != nil {
+					r.logger.Error("public failed to close file: %s", closeErr)
+				}
+				f = sibling
+				c.SetHeader("Content-Encoding", encoding)
+				break
+			}
+		}
+
 		// Read and send file
 		content, err := io.ReadAll(f)
 		if err != nil {
@@ -0,0 +1,67 @@
+package routertest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/goliatone/go-router"
+)
+
+// Setup registers the routes of a test on each built-in adapter. Assign the
+// same generic registrar to every field and Go infers its type argument from
+// the field:
+//
+//	func registerRoutes[T any](r router.Router[T]) { ... }
+//
+//	routertest.EachAdapter(t, routertest.Setup{
+//		Fiber:      registerRoutes,
+//		HTTPRouter: registerRoutes,
+//		ServeMux:   registerRoutes,
+//	}, func(t *testing.T, client *routertest.Client) { ... })
+//
+// A nil registrar skips its adapter.
+type Setup struct {
+	Fiber      func(router.Router[*fiber.App])
+	HTTPRouter func(router.Router[*httprouter.Router])
+	ServeMux   func(router.Router[*http.ServeMux])
+	// FiberApp configures the Fiber app, as an option of
+	// router.NewFiberAdapter. Defaults to router.DefaultFiberOptions.
+	FiberApp func(*fiber.App) *fiber.App
+	// Options configure every client.
+	Options []ClientOption
+}
+
+// EachAdapter runs fn in a subtest per adapter of setup, named fiber,
+// httprouter and servemux, with a client of a fresh server whose routes were
+// registered by setup.
+func EachAdapter(t *testing.T, setup Setup, fn func(t *testing.T, client *Client)) {
+	t.Helper()
+	if setup.Fiber != nil {
+		t.Run("fiber", func(t *testing.T) {
+			var opts []func(*fiber.App) *fiber.App
+			if setup.FiberApp != nil {
+				opts = append(opts, setup.FiberApp)
+			}
+			server := router.NewFiberAdapter(opts...)
+			setup.Fiber(server.Router())
+			fn(t, NewClient(server, setup.Options...))
+		})
+	}
+	if setup.HTTPRouter != nil {
+		t.Run("httprouter", func(t *testing.T) {
+			server := router.NewHTTPServer()
+			setup.HTTPRouter(server.Router())
+			fn(t, NewClient(server, setup.Options...))
+		})
+	}
+	if setup.ServeMux != nil {
+		t.Run("servemux", func(t *testing.T) {
+			server := router.NewServeMuxServer()
+			setup.ServeMux(server.Router())
+			fn(t, NewClient(server, setup.Options...))
+		})
+	}
+}
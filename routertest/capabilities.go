@@ -0,0 +1,233 @@
+package routertest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-router"
+)
+
+type capabilityProbe struct {
+	name string
+	run  func(t *testing.T, h *harness, s serverUnderTest) Capability
+}
+
+var capabilityProbes = []capabilityProbe{
+	{name: CapabilityHeadForGet, run: probeHeadForGet},
+	{name: CapabilityTrailingSlash, run: probeTrailingSlash},
+	{name: CapabilityNamedCatchAll, run: probeNamedCatchAll},
+	{name: CapabilityBindForm, run: probeBindForm},
+	{name: CapabilityBindXML, run: probeBindXML},
+	{name: CapabilityForwardedIP, run: probeForwardedIP},
+	{name: CapabilityMissHandler, run: probeMissHandler},
+	{name: CapabilityMethodNotAllowed, run: probeMethodNotAllowed},
+	{name: CapabilityRouteMutation, run: probeRouteMutation},
+	{name: CapabilityCatchAllSiblings, run: probeCatchAllSiblings},
+	{name: CapabilityPathConflictMode, run: probePathConflictMode},
+	{name: CapabilityOrderIndependence, run: probeOrderIndependence},
+}
+
+func probeHeadForGet(t *testing.T, h *harness, s serverUnderTest) Capability {
+	s.router.Get("/head", func(c router.Context) error { return c.SendString("body") })
+
+	res := h.send(t, h.transport(t, s), http.MethodHead, "/head", nil)
+	return Capability{
+		Supported: res.Status == http.StatusOK,
+		Detail:    fmt.Sprintf("HEAD on GET route: %d", res.Status),
+	}
+}
+
+func probeTrailingSlash(t *testing.T, h *harness, s serverUnderTest) Capability {
+	s.router.Get("/slash", func(c router.Context) error { return c.SendString("ok") })
+
+	res := h.send(t, h.transport(t, s), http.MethodGet, "/slash/", nil)
+	switch {
+	case res.Status == http.StatusOK:
+		return Capability{Supported: true, Detail: "lenient"}
+	case res.Status >= 300 && res.Status < 400:
+		return Capability{Supported: true, Detail: fmt.Sprintf("redirect %d", res.Status)}
+	default:
+		return Capability{Supported: false, Detail: "distinct"}
+	}
+}
+
+// probeNamedCatchAll checks "*name" segments. Fiber only supports the unnamed
+// "*" wildcard, while httprouter requires a name.
+func probeNamedCatchAll(t *testing.T, h *harness, s serverUnderTest) Capability {
+	s.router.Get("/files/*path", func(c router.Context) error { return c.SendString(c.Param("path")) })
+
+	res := h.send(t, h.transport(t, s), http.MethodGet, "/files/docs/intro.md", nil)
+	if res.Status != http.StatusOK {
+		return Capability{Detail: fmt.Sprintf("GET /files/docs/intro.md: %d", res.Status)}
+	}
+	if value := strings.TrimPrefix(res.Body, "/"); value != "docs/intro.md" {
+		return Capability{Detail: fmt.Sprintf("Param(path) = %q", res.Body)}
+	}
+	return Capability{Supported: true}
+}
+
+type bindProbePayload struct {
+	Name string `json:"name" form:"name" xml:"name" query:"name"`
+}
+
+func probeBind(t *testing.T, h *harness, s serverUnderTest, contentType, body string) Capability {
+	s.router.Post("/bind", func(c router.Context) error {
+		var p bindProbePayload
+		if err := c.Bind(&p); err != nil {
+			return c.Status(http.StatusUnprocessableEntity).SendString(err.Error())
+		}
+		return c.SendString(p.Name)
+	})
+
+	res := h.send(t, h.transport(t, s), http.MethodPost, "/bind", strings.NewReader(body), "Content-Type", contentType)
+	if res.Status == http.StatusOK && res.Body == "widget" {
+		return Capability{Supported: true}
+	}
+	return Capability{Detail: fmt.Sprintf("%d %s", res.Status, firstLine(res.Body))}
+}
+
+func probeBindForm(t *testing.T, h *harness, s serverUnderTest) Capability {
+	return probeBind(t, h, s, "application/x-www-form-urlencoded", url.Values{"name": {"widget"}}.Encode())
+}
+
+func probeBindXML(t *testing.T, h *harness, s serverUnderTest) Capability {
+	return probeBind(t, h, s, "application/xml", "<bindProbePayload><name>widget</name></bindProbePayload>")
+}
+
+func probeForwardedIP(t *testing.T, h *harness, s serverUnderTest) Capability {
+	s.router.Get("/ip", func(c router.Context) error { return c.SendString(c.IP()) })
+
+	res := h.send(t, h.transport(t, s), http.MethodGet, "/ip", nil, "X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+	return Capability{Supported: res.Body == "203.0.113.9", Detail: "IP() = " + res.Body}
+}
+
+func probeMissHandler(t *testing.T, h *harness, s serverUnderTest) Capability {
+	registrar, ok := s.router.raw().(router.MissHandlerRegistrar)
+	if !ok {
+		return Capability{Detail: "MissHandlerRegistrar not implemented"}
+	}
+	registrar.HandleMiss(router.GET, func(c router.Context) error {
+		return c.Status(http.StatusTeapot).SendString("miss")
+	})
+	s.router.Get("/present", func(c router.Context) error { return c.SendString("ok") })
+
+	res := h.send(t, h.transport(t, s), http.MethodGet, "/absent", nil)
+	if res.Status != http.StatusTeapot {
+		t.Errorf("router implements MissHandlerRegistrar but miss returned %d", res.Status)
+		return Capability{Detail: fmt.Sprintf("miss returned %d", res.Status)}
+	}
+	return Capability{Supported: true}
+}
+
+func probeMethodNotAllowed(t *testing.T, h *harness, s serverUnderTest) Capability {
+	s.router.Get("/only-get", func(c router.Context) error { return c.SendString("ok") })
+
+	res := h.send(t, h.transport(t, s), http.MethodPost, "/only-get", nil)
+	if res.Status != http.StatusMethodNotAllowed {
+		return Capability{Detail: fmt.Sprintf("wrong method: %d", res.Status)}
+	}
+	return Capability{Supported: true, Detail: "Allow: " + res.Header.Get("Allow")}
+}
+
+func probeRouteMutation(t *testing.T, h *harness, s serverUnderTest) Capability {
+	mutator, ok := s.router.raw().(router.RouteMutator)
+	if !ok {
+		return Capability{Detail: "RouteMutator not implemented"}
+	}
+	s.router.Get("/mutable", func(c router.Context) error { return c.SendString("original") })
+	if _, err := mutator.TryReplaceWithOptions(router.GET, "/mutable", func(c router.Context) error {
+		return c.SendString("replaced")
+	}, router.RouteMutationOptions{}); err != nil {
+		t.Errorf("TryReplaceWithOptions: %v", err)
+		return Capability{Detail: err.Error()}
+	}
+
+	res := h.send(t, h.transport(t, s), http.MethodGet, "/mutable", nil)
+	if res.Body != "replaced" {
+		t.Errorf("replaced route served %q", res.Body)
+		return Capability{Detail: "replacement not served"}
+	}
+	return Capability{Supported: true}
+}
+
+func declaredCapabilities(s serverUnderTest) (router.RoutingCapabilities, bool) {
+	provider, ok := s.router.raw().(router.RoutingCapabilityProvider)
+	if !ok {
+		return router.RoutingCapabilities{}, false
+	}
+	return provider.RoutingCapabilities(), true
+}
+
+// probeCatchAllSiblings registers a catch-all next to a static sibling and
+// checks that both are reachable. A router that declares CatchAllSiblings must
+// pass it.
+func probeCatchAllSiblings(t *testing.T, h *harness, s serverUnderTest) Capability {
+	declared, hasDeclared := declaredCapabilities(s)
+	catchAll := "/c/*rest"
+	if named, ok := h.report.Capability(CapabilityNamedCatchAll); ok && !named.Supported {
+		catchAll = "/c/*"
+	}
+
+	registered := func() (ok bool) {
+		defer func() {
+			if recover() != nil {
+				ok = false
+			}
+		}()
+		s.router.Get("/c/search", func(c router.Context) error { return c.SendString("static") })
+		s.router.Get(catchAll, func(c router.Context) error { return c.SendString("catch-all") })
+		return true
+	}()
+
+	observed := false
+	if registered {
+		rt := h.transport(t, s)
+		observed = h.send(t, rt, http.MethodGet, "/c/search", nil).Body == "static" &&
+			h.send(t, rt, http.MethodGet, "/c/other/path", nil).Body == "catch-all"
+	}
+	if hasDeclared && declared.CatchAllSiblings && !observed {
+		t.Errorf("router declares CatchAllSiblings but a static sibling was not reachable")
+	}
+	return Capability{Supported: observed}
+}
+
+func probePathConflictMode(t *testing.T, _ *harness, s serverUnderTest) Capability {
+	declared, ok := declaredCapabilities(s)
+	if !ok || declared.PathConflictMode == "" {
+		return Capability{Detail: "undeclared"}
+	}
+	return Capability{
+		Supported: declared.PathConflictMode == router.PathConflictModePreferStatic,
+		Detail:    string(declared.PathConflictMode),
+	}
+}
+
+// probeOrderIndependence registers a parameter route before its static sibling.
+// It only runs when the router declares prefer_static, since strict routers
+// reject the pair as a conflict.
+func probeOrderIndependence(t *testing.T, h *harness, s serverUnderTest) Capability {
+	declared, ok := declaredCapabilities(s)
+	if !ok || declared.PathConflictMode != router.PathConflictModePreferStatic {
+		return Capability{Supported: ok && declared.OrderIndependent, Detail: "declared"}
+	}
+
+	s.router.Get("/o/:id", func(c router.Context) error { return c.SendString("param") })
+	s.router.Get("/o/new", func(c router.Context) error { return c.SendString("static") })
+
+	observed := h.send(t, h.transport(t, s), http.MethodGet, "/o/new", nil).Body == "static"
+	if declared.OrderIndependent && !observed {
+		t.Errorf("router declares OrderIndependent but a later static sibling was shadowed")
+	}
+	return Capability{Supported: observed, Detail: "observed"}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if len(line) > 60 {
+		return line[:60] + "..."
+	}
+	return line
+}
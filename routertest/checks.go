@@ -0,0 +1,690 @@
+package routertest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/goliatone/go-router"
+)
+
+type contractCheck struct {
+	name    string
+	network bool
+	run     func(t *testing.T, h *harness, s serverUnderTest)
+}
+
+var contractChecks = []contractCheck{
+	{name: "routing/static", run: checkRoutingStatic},
+	{name: "routing/params", run: checkRoutingParams},
+	{name: "routing/param_constraints", run: checkRoutingParamConstraints},
+	{name: "routing/methods", run: checkRoutingMethods},
+	{name: "routing/groups", run: checkRoutingGroups},
+	{name: "routing/named_routes", run: checkRoutingNamedRoutes},
+	{name: "routing/not_found", run: checkRoutingNotFound},
+	{name: "routing/static_files", run: checkRoutingStaticFiles},
+	{name: "context/request", run: checkContextRequest},
+	{name: "context/response", run: checkContextResponse},
+	{name: "context/cookies", run: checkContextCookies},
+	{name: "context/locals_and_store", run: checkContextLocalsAndStore},
+	{name: "context/go_context", run: checkContextGoContext},
+	{name: "context/middleware_order", run: checkContextMiddlewareOrder},
+	{name: "context/redirect", run: checkContextRedirect},
+	{name: "context/redirect_back", run: checkContextRedirectBack},
+	{name: "context/bind_json", run: checkContextBindJSON},
+	{name: "context/bind_sources", run: checkContextBindSources},
+	{name: "errors/router_error_status", run: checkErrorsRouterErrorStatus},
+	{name: "errors/plain_error", run: checkErrorsPlainError},
+	{name: "errors/middleware_short_circuit", run: checkErrorsMiddlewareShortCircuit},
+	{name: "websocket/route_registered", run: checkWebSocketRouteRegistered},
+	{name: "websocket/requires_upgrade", run: checkWebSocketRequiresUpgrade},
+	{name: "websocket/echo", network: true, run: checkWebSocketEcho},
+	{name: "registration/snapshot", run: checkRegistrationSnapshot},
+}
+
+func expectStatus(t *testing.T, res response, want int) {
+	t.Helper()
+	if res.Status != want {
+		t.Fatalf("status = %d, want %d (body %q)", res.Status, want, res.Body)
+	}
+}
+
+func expectBody(t *testing.T, res response, want string) {
+	t.Helper()
+	if res.Body != want {
+		t.Fatalf("body = %q, want %q", res.Body, want)
+	}
+}
+
+// expectJSON compares JSON bodies ignoring the trailing newline some encoders add.
+func expectJSON(t *testing.T, res response, want string) {
+	t.Helper()
+	if got := strings.TrimSpace(res.Body); got != want {
+		t.Fatalf("body = %s, want %s", got, want)
+	}
+}
+
+func checkRoutingStatic(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/ping", func(c router.Context) error { return c.SendString("pong") })
+	s.router.Get("/ping/deep/path", func(c router.Context) error { return c.SendString("deep") })
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodGet, "/ping", nil)
+	expectStatus(t, res, http.StatusOK)
+	expectBody(t, res, "pong")
+	expectBody(t, h.send(t, rt, http.MethodGet, "/ping/deep/path", nil), "deep")
+}
+
+func checkRoutingParams(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/users/:id/posts/:post", func(c router.Context) error {
+		return c.SendString(c.Param("id") + "|" + c.Param("post") + "|" + c.Param("missing", "default"))
+	})
+	s.router.Get("/numbers/:n", func(c router.Context) error {
+		return c.JSON(http.StatusOK, map[string]int{"n": c.ParamsInt("n", -1)})
+	})
+
+	rt := h.transport(t, s)
+	expectBody(t, h.send(t, rt, http.MethodGet, "/users/42/posts/hello-world", nil), "42|hello-world|default")
+	expectJSON(t, h.send(t, rt, http.MethodGet, "/numbers/17", nil), `{"n":17}`)
+	expectJSON(t, h.send(t, rt, http.MethodGet, "/numbers/abc", nil), `{"n":-1}`)
+}
+
+func checkRoutingParamConstraints(t *testing.T, h *harness, s serverUnderTest) {
+	handler := func(c router.Context) error { return c.SendString(c.Param("v")) }
+	s.router.Get("/orders/:v<int;min(1)>", handler)
+	s.router.Get("/states/:v<enum(draft,published)>", handler)
+	s.router.Get("/codes/:v<regex([A-Z]{3})>", handler)
+	s.router.Put("/slots/:v<uint>", handler)
+	registrar, hasMiss := s.router.raw().(router.MissHandlerRegistrar)
+	if hasMiss {
+		registrar.HandleMiss(router.PUT, func(c router.Context) error {
+			return c.Status(http.StatusTeapot).SendString("miss")
+		})
+	}
+
+	rt := h.transport(t, s)
+	expectBody(t, h.send(t, rt, http.MethodGet, "/orders/42", nil), "42")
+	expectBody(t, h.send(t, rt, http.MethodGet, "/states/draft", nil), "draft")
+	expectBody(t, h.send(t, rt, http.MethodGet, "/codes/ABC", nil), "ABC")
+	for _, path := range []string{"/orders/abc", "/orders/0", "/states/archived", "/codes/ABCD"} {
+		expectStatus(t, h.send(t, rt, http.MethodGet, path, nil), http.StatusNotFound)
+	}
+	expectBody(t, h.send(t, rt, http.MethodPut, "/slots/3", nil), "3")
+	if hasMiss {
+		expectStatus(t, h.send(t, rt, http.MethodPut, "/slots/-3", nil), http.StatusTeapot)
+	}
+
+	for _, route := range s.router.Routes() {
+		if route.Path != "/orders/:v<int;min(1)>" {
+			continue
+		}
+		if len(route.Parameters) != 1 || route.Parameters[0].In != "path" {
+			t.Fatalf("Parameters = %+v, want one path parameter", route.Parameters)
+		}
+		schema := route.Parameters[0].Schema
+		if schema["type"] != "integer" || schema["minimum"] != int64(1) {
+			t.Fatalf("schema = %v, want integer with minimum 1", schema)
+		}
+		return
+	}
+	t.Fatal("Routes() missing GET /orders/:v<int;min(1)>")
+}
+
+func checkRoutingMethods(t *testing.T, h *harness, s serverUnderTest) {
+	handler := func(c router.Context) error { return c.SendString(c.Method()) }
+	s.router.Get("/items", handler)
+	s.router.Post("/items", handler)
+	s.router.Put("/items", handler)
+	s.router.Patch("/items", handler)
+	s.router.Delete("/items", handler)
+
+	rt := h.transport(t, s)
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		res := h.send(t, rt, method, "/items", nil)
+		expectStatus(t, res, http.StatusOK)
+		expectBody(t, res, method)
+	}
+}
+
+func checkRoutingGroups(t *testing.T, h *harness, s serverUnderTest) {
+	api := s.router.group("/api")
+	api.use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c router.Context) error {
+			c.SetHeader("X-Group", "api")
+			return next(c)
+		}
+	})
+	api.group("/v1").Get("/status", func(c router.Context) error { return c.SendString("v1") })
+	s.router.Get("/outside", func(c router.Context) error { return c.SendString("outside") })
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodGet, "/api/v1/status", nil)
+	expectBody(t, res, "v1")
+	if got := res.Header.Get("X-Group"); got != "api" {
+		t.Fatalf("group middleware header = %q, want api", got)
+	}
+	res = h.send(t, rt, http.MethodGet, "/outside", nil)
+	expectBody(t, res, "outside")
+	if got := res.Header.Get("X-Group"); got != "" {
+		t.Fatalf("group middleware leaked to root route: X-Group=%q", got)
+	}
+
+	found := false
+	for _, route := range s.router.Routes() {
+		if route.Method == router.GET && route.Path == "/api/v1/status" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Routes() missing GET /api/v1/status: %+v", s.router.Routes())
+	}
+}
+
+func checkRoutingNamedRoutes(t *testing.T, h *harness, s serverUnderTest) {
+	var name string
+	var params map[string]string
+	s.router.Get("/orgs/:org/repos/:repo", func(c router.Context) error {
+		name = c.RouteName()
+		params = c.RouteParams()
+		return c.SendStatus(http.StatusNoContent)
+	}).SetName("orgs.repos.show")
+
+	rt := h.transport(t, s)
+	expectStatus(t, h.send(t, rt, http.MethodGet, "/orgs/acme/repos/widgets", nil), http.StatusNoContent)
+	if name != "orgs.repos.show" {
+		t.Fatalf("RouteName() = %q, want orgs.repos.show", name)
+	}
+	if params["org"] != "acme" || params["repo"] != "widgets" {
+		t.Fatalf("RouteParams() = %v", params)
+	}
+}
+
+func checkRoutingNotFound(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/exists", func(c router.Context) error { return c.SendString("ok") })
+
+	rt := h.transport(t, s)
+	expectStatus(t, h.send(t, rt, http.MethodGet, "/does-not-exist", nil), http.StatusNotFound)
+}
+
+func checkRoutingStaticFiles(t *testing.T, h *harness, s serverUnderTest) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "css", "app.css"), []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s.router.static("/assets", dir)
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodGet, "/assets/css/app.css", nil)
+	expectStatus(t, res, http.StatusOK)
+	expectBody(t, res, "body{}")
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+		t.Fatalf("Content-Type = %q, want text/css", ct)
+	}
+	expectStatus(t, h.send(t, rt, http.MethodGet, "/assets/css/missing.css", nil), http.StatusNotFound)
+}
+
+func checkContextRequest(t *testing.T, h *harness, s serverUnderTest) {
+	var got map[string]any
+	s.router.Post("/inspect/:id", func(c router.Context) error {
+		got = map[string]any{
+			"method":      c.Method(),
+			"path":        c.Path(),
+			"original":    c.OriginalURL(),
+			"query":       c.Query("q"),
+			"query_def":   c.Query("absent", "fallback"),
+			"query_int":   c.QueryInt("page", 0),
+			"query_multi": c.QueryValues("tag"),
+			"header":      c.Header("X-Custom"),
+			"body":        string(c.Body()),
+			"referer":     c.Referer(),
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodPost, "/inspect/7?q=search&page=3&tag=a&tag=b", strings.NewReader("payload"),
+		"X-Custom", "custom-value", "Referer", "http://example.com/from")
+	expectStatus(t, res, http.StatusOK)
+
+	want := map[string]any{
+		"method":    http.MethodPost,
+		"path":      "/inspect/7",
+		"original":  "/inspect/7?q=search&page=3&tag=a&tag=b",
+		"query":     "search",
+		"query_def": "fallback",
+		"query_int": 3,
+		"header":    "custom-value",
+		"body":      "payload",
+		"referer":   "http://example.com/from",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %#v, want %#v", key, got[key], value)
+		}
+	}
+	if tags, _ := got["query_multi"].([]string); strings.Join(tags, ",") != "a,b" {
+		t.Errorf("QueryValues(tag) = %#v, want [a b]", got["query_multi"])
+	}
+}
+
+func checkContextResponse(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/json", func(c router.Context) error {
+		return c.SetHeader("X-Response", "set").JSON(http.StatusCreated, map[string]string{"ok": "yes"})
+	})
+	s.router.Get("/status", func(c router.Context) error {
+		return c.Status(http.StatusAccepted).SendString("accepted")
+	})
+	s.router.Get("/empty", func(c router.Context) error { return c.NoContent(http.StatusNoContent) })
+	s.router.Get("/stream", func(c router.Context) error {
+		return c.SendStream(strings.NewReader("streamed"))
+	})
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodGet, "/json", nil)
+	expectStatus(t, res, http.StatusCreated)
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	if res.Header.Get("X-Response") != "set" {
+		t.Fatalf("SetHeader not applied: %v", res.Header)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(res.Body), &payload); err != nil || payload["ok"] != "yes" {
+		t.Fatalf("JSON body = %q (%v)", res.Body, err)
+	}
+
+	res = h.send(t, rt, http.MethodGet, "/status", nil)
+	expectStatus(t, res, http.StatusAccepted)
+	expectBody(t, res, "accepted")
+
+	res = h.send(t, rt, http.MethodGet, "/empty", nil)
+	expectStatus(t, res, http.StatusNoContent)
+	expectBody(t, res, "")
+
+	res = h.send(t, rt, http.MethodGet, "/stream", nil)
+	expectStatus(t, res, http.StatusOK)
+	expectBody(t, res, "streamed")
+}
+
+func checkContextCookies(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/cookies", func(c router.Context) error {
+		c.Cookie(&router.Cookie{Name: "session", Value: "abc", Path: "/", HTTPOnly: true})
+		return c.SendString(c.Cookies("incoming") + "|" + c.Cookies("absent", "none"))
+	})
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodGet, "/cookies", nil, "Cookie", "incoming=hello")
+	expectBody(t, res, "hello|none")
+
+	cookies := (&http.Response{Header: res.Header}).Cookies()
+	for _, cookie := range cookies {
+		if cookie.Name == "session" {
+			if cookie.Value != "abc" || !cookie.HttpOnly {
+				t.Fatalf("session cookie = %+v", cookie)
+			}
+			return
+		}
+	}
+	t.Fatalf("Set-Cookie missing session cookie: %v", res.Header.Values("Set-Cookie"))
+}
+
+func checkContextLocalsAndStore(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/locals", func(c router.Context) error {
+		return c.SendString(
+			c.Locals("user").(string) + "|" +
+				c.GetString("role", "") + "|" +
+				c.GetString("absent", "def"),
+		)
+	}, func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c router.Context) error {
+			c.Locals("user", "alice")
+			c.Set("role", "admin")
+			return next(c)
+		}
+	})
+
+	rt := h.transport(t, s)
+	expectBody(t, h.send(t, rt, http.MethodGet, "/locals", nil), "alice|admin|def")
+}
+
+type conformanceContextKey struct{}
+
+func checkContextGoContext(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/ctx", func(c router.Context) error {
+		value, _ := c.Context().Value(conformanceContextKey{}).(string)
+		return c.SendString(value)
+	}, func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c router.Context) error {
+			c.SetContext(context.WithValue(c.Context(), conformanceContextKey{}, "propagated"))
+			return next(c)
+		}
+	})
+
+	rt := h.transport(t, s)
+	expectBody(t, h.send(t, rt, http.MethodGet, "/ctx", nil), "propagated")
+}
+
+func checkContextMiddlewareOrder(t *testing.T, h *harness, s serverUnderTest) {
+	var order []string
+	trace := func(name string) router.MiddlewareFunc {
+		return func(next router.HandlerFunc) router.HandlerFunc {
+			return func(c router.Context) error {
+				order = append(order, name+":before")
+				err := next(c)
+				order = append(order, name+":after")
+				return err
+			}
+		}
+	}
+	s.router.use(trace("global"))
+	s.router.Get("/order", func(c router.Context) error {
+		order = append(order, "handler")
+		return c.SendString("ok")
+	}, trace("route"))
+
+	rt := h.transport(t, s)
+	expectBody(t, h.send(t, rt, http.MethodGet, "/order", nil), "ok")
+	want := "global:before,route:before,handler,route:after,global:after"
+	if got := strings.Join(order, ","); got != want {
+		t.Fatalf("middleware order = %s, want %s", got, want)
+	}
+}
+
+func checkContextRedirect(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/old", func(c router.Context) error { return c.Redirect("/new") })
+	s.router.Get("/moved", func(c router.Context) error { return c.Redirect("/permanent", http.StatusMovedPermanently) })
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodGet, "/old", nil)
+	expectStatus(t, res, http.StatusFound)
+	if loc := res.Header.Get("Location"); loc != "/new" {
+		t.Fatalf("Location = %q, want /new", loc)
+	}
+	res = h.send(t, rt, http.MethodGet, "/moved", nil)
+	expectStatus(t, res, http.StatusMovedPermanently)
+	if loc := res.Header.Get("Location"); loc != "/permanent" {
+		t.Fatalf("Location = %q, want /permanent", loc)
+	}
+}
+
+func checkContextRedirectBack(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Post("/submit", func(c router.Context) error { return c.RedirectBack("/fallback") })
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodPost, "/submit", nil, "Referer", "http://example.com/form?step=2")
+	expectStatus(t, res, http.StatusFound)
+	if loc := res.Header.Get("Location"); loc != "/form?step=2" {
+		t.Fatalf("same-origin RedirectBack Location = %q, want /form?step=2", loc)
+	}
+
+	res = h.send(t, rt, http.MethodPost, "/submit", nil, "Referer", "https://evil.example.net/phish")
+	if loc := res.Header.Get("Location"); loc != "/fallback" {
+		t.Fatalf("cross-origin RedirectBack Location = %q, want /fallback", loc)
+	}
+
+	res = h.send(t, rt, http.MethodPost, "/submit", nil)
+	if loc := res.Header.Get("Location"); loc != "/fallback" {
+		t.Fatalf("RedirectBack without Referer Location = %q, want /fallback", loc)
+	}
+}
+
+func checkContextBindJSON(t *testing.T, h *harness, s serverUnderTest) {
+	type payload struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+	s.router.Post("/bind", func(c router.Context) error {
+		var p payload
+		if err := c.Bind(&p); err != nil {
+			return c.Status(http.StatusBadRequest).SendString(err.Error())
+		}
+		return c.JSON(http.StatusOK, p)
+	})
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodPost, "/bind", strings.NewReader(`{"name":"widget","count":3,"tags":["a","b"]}`),
+		"Content-Type", "application/json")
+	expectStatus(t, res, http.StatusOK)
+	expectJSON(t, res, `{"name":"widget","count":3,"tags":["a","b"]}`)
+
+	res = h.send(t, rt, http.MethodPost, "/bind", strings.NewReader(`{"name":`), "Content-Type", "application/json")
+	expectStatus(t, res, http.StatusBadRequest)
+}
+
+func checkContextBindSources(t *testing.T, h *harness, s serverUnderTest) {
+	type request struct {
+		Org    string `path:"org" json:"org"`
+		IDs    []int  `query:"ids" json:"ids"`
+		Filter struct {
+			Name string `query:"name" json:"name"`
+		} `query:"filter" json:"filter"`
+		Trace string `header:"X-Trace-ID" json:"trace"`
+		Title string `form:"title" json:"title"`
+	}
+	s.router.Post("/api/orgs/:org/members", func(c router.Context) error {
+		var req request
+		for _, bind := range []func(any) error{c.BindParams, c.BindQuery, c.BindHeaders, c.BindForm} {
+			if err := bind(&req); err != nil {
+				return err
+			}
+		}
+		return c.JSON(http.StatusOK, req)
+	})
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodPost, "/api/orgs/acme/members?ids=1&ids=2&filter[name]=ada",
+		strings.NewReader(url.Values{"title": {"lead"}}.Encode()),
+		"Content-Type", "application/x-www-form-urlencoded", "X-Trace-ID", "t-1")
+	expectStatus(t, res, http.StatusOK)
+	expectJSON(t, res, `{"org":"acme","ids":[1,2],"filter":{"name":"ada"},"trace":"t-1","title":"lead"}`)
+
+	res = h.send(t, rt, http.MethodPost, "/api/orgs/acme/members?ids=x", nil)
+	expectStatus(t, res, http.StatusBadRequest)
+	if !strings.Contains(res.Body, "BIND_ERROR") {
+		t.Fatalf("body = %q, want a BIND_ERROR response", res.Body)
+	}
+}
+
+// Router errors map to their HTTP status under the default error handler API
+// prefix ("/api"); outside it adapters may fall back to framework defaults.
+func checkErrorsRouterErrorStatus(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/api/missing", func(c router.Context) error { return router.NewNotFoundError("widget not found") })
+	s.router.Get("/api/forbidden", func(c router.Context) error { return router.NewForbiddenError("nope") })
+	s.router.Get("/api/conflict", func(c router.Context) error { return router.NewConflictError("taken") })
+
+	rt := h.transport(t, s)
+	cases := map[string]int{
+		"/api/missing":   http.StatusNotFound,
+		"/api/forbidden": http.StatusForbidden,
+		"/api/conflict":  http.StatusConflict,
+	}
+	for path, want := range cases {
+		res := h.send(t, rt, http.MethodGet, path, nil)
+		if res.Status != want {
+			t.Errorf("GET %s status = %d, want %d", path, res.Status, want)
+		}
+		if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("GET %s Content-Type = %q, want application/json", path, ct)
+		}
+	}
+}
+
+func checkErrorsPlainError(t *testing.T, h *harness, s serverUnderTest) {
+	s.router.Get("/boom", func(c router.Context) error { return errors.New("boom") })
+	s.router.Get("/api/boom", func(c router.Context) error { return errors.New("boom") })
+
+	rt := h.transport(t, s)
+	expectStatus(t, h.send(t, rt, http.MethodGet, "/boom", nil), http.StatusInternalServerError)
+	expectStatus(t, h.send(t, rt, http.MethodGet, "/api/boom", nil), http.StatusInternalServerError)
+}
+
+func checkErrorsMiddlewareShortCircuit(t *testing.T, h *harness, s serverUnderTest) {
+	called := false
+	s.router.Get("/api/guarded", func(c router.Context) error {
+		called = true
+		return c.SendString("secret")
+	}, func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c router.Context) error {
+			return router.NewUnauthorizedError("login required")
+		}
+	})
+
+	rt := h.transport(t, s)
+	expectStatus(t, h.send(t, rt, http.MethodGet, "/api/guarded", nil), http.StatusUnauthorized)
+	if called {
+		t.Fatal("handler ran after middleware returned an error")
+	}
+}
+
+func registerEchoWebSocket(s serverUnderTest) {
+	s.router.WebSocket("/ws", router.WebSocketConfig{}, func(ws router.WebSocketContext) error {
+		for {
+			messageType, data, err := ws.ReadMessage()
+			if err != nil {
+				return nil
+			}
+			if err := ws.WriteMessage(messageType, data); err != nil {
+				return nil
+			}
+		}
+	})
+}
+
+func checkWebSocketRouteRegistered(t *testing.T, _ *harness, s serverUnderTest) {
+	registerEchoWebSocket(s)
+	for _, route := range s.router.Routes() {
+		if route.Method == router.GET && route.Path == "/ws" {
+			return
+		}
+	}
+	t.Fatalf("Routes() missing GET /ws: %+v", s.router.Routes())
+}
+
+func checkWebSocketRequiresUpgrade(t *testing.T, h *harness, s serverUnderTest) {
+	registerEchoWebSocket(s)
+
+	rt := h.transport(t, s)
+	res := h.send(t, rt, http.MethodGet, "/ws", nil)
+	if res.Status < 400 || res.Status >= 500 {
+		t.Fatalf("plain GET to WebSocket route status = %d, want 4xx", res.Status)
+	}
+}
+
+func checkWebSocketEcho(t *testing.T, _ *harness, s serverUnderTest) {
+	registerEchoWebSocket(s)
+
+	address := serveOnLoopback(t, s)
+	dialer := websocket.Dialer{HandshakeTimeout: 2 * time.Second}
+	conn, res, err := dialer.Dial("ws://"+address+"/ws", nil)
+	if err != nil {
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+		}
+		t.Fatalf("websocket dial failed (status %d): %v", status, err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if messageType != websocket.TextMessage || string(data) != "hello" {
+		t.Fatalf("echo = (%d, %q), want text hello", messageType, data)
+	}
+}
+
+// serveOnLoopback starts s on a free loopback port and shuts it down when the
+// test ends. Server[T] only accepts an address, so the port is reserved and
+// released before Serve binds it again.
+func serveOnLoopback(t *testing.T, s serverUnderTest) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve port: %v", err)
+	}
+	address := ln.Addr().String()
+	_ = ln.Close()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- s.serve(address) }()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", address, 100*time.Millisecond)
+		if err == nil {
+			_ = conn.Close()
+			break
+		}
+		select {
+		case err := <-serveErr:
+			t.Fatalf("serve %s: %v", address, err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start on %s: %v", address, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = s.close(ctx)
+	})
+	return address
+}
+
+func checkRegistrationSnapshot(t *testing.T, h *harness, s serverUnderTest) {
+	inspector, ok := s.router.raw().(router.RegistrationInspector)
+	if !ok {
+		t.Fatalf("router %T does not implement RegistrationInspector", s.router.raw())
+	}
+
+	s.router.Get("/snap", func(c router.Context) error { return c.SendString("ok") }).SetName("snap")
+	before := inspector.RegistrationSnapshot()
+	if before.State != router.RegistrationCollecting {
+		t.Fatalf("state before init = %s, want %s", before.State, router.RegistrationCollecting)
+	}
+	if before.Revision == 0 {
+		t.Fatal("revision did not advance after registering a route")
+	}
+
+	rt := h.transport(t, s)
+	expectBody(t, h.send(t, rt, http.MethodGet, "/snap", nil), "ok")
+
+	after := inspector.RegistrationSnapshot()
+	if after.State != router.RegistrationSealed {
+		t.Fatalf("state after init = %s, want %s", after.State, router.RegistrationSealed)
+	}
+	if after.Revision < before.Revision {
+		t.Fatalf("revision went backwards: %d -> %d", before.Revision, after.Revision)
+	}
+	found := false
+	for _, route := range after.MountedRoutes {
+		if route.Method == router.GET && route.Path == "/snap" && route.Name == "snap" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("MountedRoutes missing GET /snap (snap): %+v", after.MountedRoutes)
+	}
+}
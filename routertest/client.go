@@ -0,0 +1,190 @@
+package routertest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/flash"
+)
+
+// DefaultBaseURL is the origin used for in-process requests.
+const DefaultBaseURL = "http://example.com"
+
+// Client sends requests to a server in memory, without opening sockets.
+// Cookies set by responses are kept in a jar and sent with later requests, so
+// flows like flash-then-redirect work across calls.
+type Client struct {
+	mu        sync.Mutex
+	resolve   func() (RoundTripFunc, error)
+	transport RoundTripFunc
+	routes    func() []router.RouteDefinition
+	baseURL   *url.URL
+	jar       http.CookieJar
+	views     *ViewRecorder
+	flashName string
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithViewRecorder attaches a recorder installed as the server's view engine.
+// The client resets it before each request and exposes the recorded renders on
+// the response, enabling AssertTemplate.
+func WithViewRecorder(recorder *ViewRecorder) ClientOption {
+	return func(c *Client) {
+		c.views = recorder
+	}
+}
+
+// WithFlashCookieName overrides the flash cookie read by AssertFlash. Defaults
+// to the cookie name of flash.DefaultFlash.
+func WithFlashCookieName(name string) ClientOption {
+	return func(c *Client) {
+		c.flashName = name
+	}
+}
+
+// WithBaseURL sets the scheme and host used for requests with relative paths.
+func WithBaseURL(base string) ClientOption {
+	return func(c *Client) {
+		if u, err := url.Parse(base); err == nil && u.Host != "" {
+			c.baseURL = u
+		}
+	}
+}
+
+// NewClient returns a client that drives server through WrappedRouter. The
+// server is initialized on the first request, so routes may still be
+// registered after the client is created.
+func NewClient[T any](server router.Server[T], opts ...ClientOption) *Client {
+	c := newClient(opts...)
+	c.resolve = func() (RoundTripFunc, error) { return TransportFor(server) }
+	c.routes = func() []router.RouteDefinition { return server.Router().Routes() }
+	return c
+}
+
+// NewHandlerClient returns a client that sends every request to handler through
+// server.WrapHandler, bypassing routing. Path parameters are not populated.
+func NewHandlerClient[T any](server router.Server[T], handler router.HandlerFunc, opts ...ClientOption) *Client {
+	c := newClient(opts...)
+	c.resolve = func() (RoundTripFunc, error) { return wrappedHandlerTransport(server, handler) }
+	c.routes = func() []router.RouteDefinition { return server.Router().Routes() }
+	return c
+}
+
+func newClient(opts ...ClientOption) *Client {
+	base, _ := url.Parse(DefaultBaseURL)
+	jar, _ := cookiejar.New(nil)
+	c := &Client{
+		baseURL: base,
+		jar:     jar,
+	}
+	if flash.DefaultFlash != nil {
+		c.flashName = flash.DefaultFlash.CookieName()
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+func wrappedHandlerTransport[T any](server router.Server[T], handler router.HandlerFunc) (RoundTripFunc, error) {
+	switch h := server.WrapHandler(handler).(type) {
+	case func(http.ResponseWriter, *http.Request):
+		return transportForWrapped(http.HandlerFunc(h))
+	case http.Handler:
+		return transportForWrapped(h)
+	case func(http.ResponseWriter, *http.Request, httprouter.Params):
+		return transportForWrapped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h(w, r, nil)
+		}))
+	case func(*fiber.Ctx) error:
+		// Reuse the adapter's app config so views and the error handler apply.
+		var config fiber.Config
+		if app, ok := any(server.WrappedRouter()).(*fiber.App); ok {
+			config = app.Config()
+		}
+		config.DisableStartupMessage = true
+		app := fiber.New(config)
+		app.Use(h)
+		return transportForWrapped(app)
+	default:
+		return nil, fmt.Errorf("routertest: unsupported wrapped handler %T", h)
+	}
+}
+
+// Request starts a request builder for method and target. Target may be a path
+// with a query string or an absolute URL.
+func (c *Client) Request(method, target string) *RequestBuilder {
+	return &RequestBuilder{client: c, method: method, target: target, header: http.Header{}}
+}
+
+func (c *Client) Get(target string) *RequestBuilder    { return c.Request(http.MethodGet, target) }
+func (c *Client) Head(target string) *RequestBuilder   { return c.Request(http.MethodHead, target) }
+func (c *Client) Post(target string) *RequestBuilder   { return c.Request(http.MethodPost, target) }
+func (c *Client) Put(target string) *RequestBuilder    { return c.Request(http.MethodPut, target) }
+func (c *Client) Patch(target string) *RequestBuilder  { return c.Request(http.MethodPatch, target) }
+func (c *Client) Delete(target string) *RequestBuilder { return c.Request(http.MethodDelete, target) }
+
+// Cookies returns the cookies the jar would send to path.
+func (c *Client) Cookies(path string) []*http.Cookie {
+	return c.jar.Cookies(c.baseURL.ResolveReference(&url.URL{Path: path}))
+}
+
+// ClearCookies empties the cookie jar.
+func (c *Client) ClearCookies() {
+	c.jar, _ = cookiejar.New(nil)
+}
+
+// RouteURL resolves the path of a named route, substituting ":name" and
+// "*name" segments from params.
+func (c *Client) RouteURL(name string, params map[string]string) (string, error) {
+	for _, route := range c.routes() {
+		if route.Name == name {
+			return buildRoutePath(route.Path, params)
+		}
+	}
+	return "", fmt.Errorf("routertest: route %q not found", name)
+}
+
+func (c *Client) roundTrip(req *http.Request) (*http.Response, []Render, error) {
+	c.mu.Lock()
+	if c.transport == nil {
+		rt, err := c.resolve()
+		if err != nil {
+			c.mu.Unlock()
+			return nil, nil, err
+		}
+		c.transport = rt
+	}
+	rt := c.transport
+	c.mu.Unlock()
+
+	for _, cookie := range c.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+	if c.views != nil {
+		c.views.Reset()
+	}
+
+	res, err := rt(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.jar.SetCookies(req.URL, res.Cookies())
+
+	var renders []Render
+	if c.views != nil {
+		renders = c.views.Renders()
+	}
+	return res, renders, nil
+}
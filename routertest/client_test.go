@@ -0,0 +1,212 @@
+package routertest
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/flash"
+)
+
+type clientAdapter struct {
+	name   string
+	client func(views *ViewRecorder, register func(r routerUnderTest)) *Client
+}
+
+func clientAdapters() []clientAdapter {
+	return []clientAdapter{
+		{name: "fiber", client: func(views *ViewRecorder, register func(r routerUnderTest)) *Client {
+			server := router.NewFiberAdapter(func(*fiber.App) *fiber.App {
+				return fiber.New(fiber.Config{
+					Views:                 views,
+					DisableStartupMessage: true,
+					ErrorHandler:          router.DefaultFiberErrorHandler(router.DefaultFiberErrorHandlerConfig()),
+				})
+			})
+			register(typedRouter[*fiber.App]{server.Router()})
+			return NewClient(server, WithViewRecorder(views))
+		}},
+		{name: "httprouter", client: func(views *ViewRecorder, register func(r routerUnderTest)) *Client {
+			server := router.NewHTTPServer(router.DefaultHTTPRouterOptions, router.WithHTTPRouterViews(views))
+			register(typedRouter[*httprouter.Router]{server.Router()})
+			return NewClient(server, WithViewRecorder(views))
+		}},
+		{name: "servemux", client: func(views *ViewRecorder, register func(r routerUnderTest)) *Client {
+			server := router.NewServeMuxServerWithConfig(router.ServeMuxConfig{Views: views})
+			register(typedRouter[*http.ServeMux]{server.Router()})
+			return NewClient(server, WithViewRecorder(views))
+		}},
+	}
+}
+
+func registerClientRoutes(r routerUnderTest) {
+	r.Post("/api/users", func(c router.Context) error {
+		var payload struct {
+			Name string   `json:"name"`
+			Tags []string `json:"tags"`
+		}
+		if err := c.Bind(&payload); err != nil {
+			return router.NewBadRequestError(err.Error())
+		}
+		return c.SetHeader("Location", "/api/users/7").JSON(http.StatusCreated, map[string]any{
+			"data": map[string]any{"id": 7, "name": payload.Name, "tags": payload.Tags},
+			"meta": map[string]any{"auth": c.Header("Authorization"), "q": c.Query("q")},
+		})
+	})
+	r.Post("/form", func(c router.Context) error {
+		return c.SendString(c.FormValue("title") + "|" + c.FormValue("missing", "none"))
+	})
+	r.Post("/upload", func(c router.Context) error {
+		file, err := c.FormFile("doc")
+		if err != nil {
+			return router.NewBadRequestError(err.Error())
+		}
+		f, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		content, _ := io.ReadAll(f)
+		return c.SendString(c.FormValue("kind") + ":" + file.Filename + ":" + string(content))
+	})
+	r.Get("/session", func(c router.Context) error {
+		c.Cookie(&router.Cookie{Name: "session", Value: "s-1", Path: "/"})
+		return c.SendString("set")
+	})
+	r.Get("/whoami", func(c router.Context) error {
+		return c.SendString(c.Cookies("session") + "|" + c.Cookies("extra"))
+	})
+	r.Get("/page", func(c router.Context) error {
+		return c.Render("pages/home", router.ViewContext{"title": "Home"})
+	})
+	r.Get("/projects/:id", func(c router.Context) error {
+		return c.JSON(http.StatusOK, flash.Get(c))
+	}).SetName("projects.show")
+	r.Post("/projects/:id", func(c router.Context) error {
+		return flash.WithSuccess(c, router.ViewContext{"message": "saved"}).
+			RedirectToRoute("projects.show", router.ViewContext{"id": c.Param("id")})
+	})
+}
+
+func TestClient_AcrossAdapters(t *testing.T) {
+	for _, adapter := range clientAdapters() {
+		t.Run(adapter.name, func(t *testing.T) {
+			client := adapter.client(NewViewRecorder(nil), registerClientRoutes)
+
+			client.Post("/api/users").
+				Query("q", "search").
+				Header("Authorization", "Bearer token").
+				JSON(map[string]any{"name": "Ada", "tags": []string{"admin"}}).
+				Do(t).
+				AssertStatus(http.StatusCreated).
+				AssertHeader("Location", "/api/users/7").
+				AssertHeaderContains("Content-Type", "application/json").
+				AssertJSONPath("data.id", 7).
+				AssertJSONPath("data.tags.0", "admin").
+				AssertJSONPath("meta", map[string]string{"auth": "Bearer token", "q": "search"})
+
+			client.Post("/api/users").Body(nil, "application/json").Do(t).AssertStatus(http.StatusBadRequest)
+
+			client.Post("/form").
+				Form(url.Values{"title": {"Hello"}}).
+				Do(t).
+				AssertBody("Hello|none")
+
+			client.Post("/upload").
+				FormValue("kind", "report").
+				File("doc", "report.txt", []byte("contents")).
+				Do(t).
+				AssertStatus(http.StatusOK).
+				AssertBody("report:report.txt:contents")
+
+			client.Get("/session").Do(t).AssertCookie("session", "s-1")
+			client.Get("/whoami").Cookie("extra", "x").Do(t).AssertBody("s-1|x")
+			client.ClearCookies()
+			client.Get("/whoami").Do(t).AssertBody("|")
+
+			client.Get("/page").Do(t).AssertStatus(http.StatusOK).AssertTemplate("pages/home")
+
+			res := client.Post("/projects/42").Do(t).
+				AssertRedirectToRoute("projects.show", map[string]string{"id": "42"}).
+				AssertFlash("success", true).
+				AssertFlash("message", "saved")
+			res.Follow().
+				AssertStatus(http.StatusOK).
+				AssertJSONPath("message", "saved")
+		})
+	}
+}
+
+func TestClient_HandlerClient(t *testing.T) {
+	handler := func(c router.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"method": c.Method(), "q": c.Query("q")})
+	}
+
+	clients := map[string]*Client{
+		"fiber":      NewHandlerClient(router.NewFiberAdapter(), handler),
+		"httprouter": NewHandlerClient(router.NewHTTPServer(), handler),
+		"servemux":   NewHandlerClient(router.NewServeMuxServer(), handler),
+	}
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			client.Put("/anything?q=1").Do(t).
+				AssertStatus(http.StatusOK).
+				AssertJSONPath("method", http.MethodPut).
+				AssertJSONPath("q", "1")
+		})
+	}
+}
+
+func TestClient_SendDoesNotRequireTest(t *testing.T) {
+	server := router.NewHTTPServer()
+	server.Router().Get("/ok", func(c router.Context) error { return c.SendString("ok") })
+
+	res, err := NewClient(server).Get("/ok").Send()
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || res.String() != "ok" {
+		t.Fatalf("response = %d %q", res.StatusCode, res.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected failed assertion on unbound response to panic")
+		}
+	}()
+	res.AssertStatus(http.StatusTeapot)
+}
+
+func TestBuildRoutePath(t *testing.T) {
+	got, err := buildRoutePath("/files/:owner<int>/*path", map[string]string{"owner": "a b", "path": "x/y.txt"})
+	if err != nil || got != "/files/a%20b/x/y.txt" {
+		t.Fatalf("buildRoutePath = %q, %v", got, err)
+	}
+	if _, err := buildRoutePath("/users/:id", nil); err == nil {
+		t.Fatal("expected missing param error")
+	}
+}
+
+func registerEachAdapterRoutes[T any](r router.Router[T]) {
+	r.Get("/ping", func(c router.Context) error { return c.SendString("pong") })
+}
+
+func TestEachAdapter_RunsConfiguredAdapters(t *testing.T) {
+	var ran []string
+	setup := Setup{Fiber: registerEachAdapterRoutes, ServeMux: registerEachAdapterRoutes}
+	EachAdapter(t, setup, func(t *testing.T, client *Client) {
+		client.Get("/ping").Do(t).AssertStatus(http.StatusOK).AssertBody("pong")
+		ran = append(ran, t.Name())
+	})
+
+	want := []string{t.Name() + "/fiber", t.Name() + "/servemux"}
+	if strings.Join(ran, ",") != strings.Join(want, ",") {
+		t.Fatalf("ran %v, want %v", ran, want)
+	}
+}
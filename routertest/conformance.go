@@ -0,0 +1,243 @@
+package routertest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-router"
+)
+
+// Factory builds a fresh, unconfigured server. RunConformance calls it once per
+// check so registrations never leak between checks.
+type Factory[T any] func() router.Server[T]
+
+// Config customizes a conformance run.
+type Config struct {
+	// Name labels the adapter in reports. Defaults to the server's Go type.
+	Name string
+	// Skip lists check names or name prefixes to skip, e.g. "websocket" or
+	// "context/bind_json".
+	Skip []string
+	// Transport overrides the in-process transport derived from WrappedRouter.
+	Transport func(wrapped any) (RoundTripFunc, error)
+	// DisableNetwork skips checks and probes that need Serve on a loopback
+	// listener (the WebSocket echo check).
+	DisableNetwork bool
+}
+
+// Capability is the observed result of a capability probe.
+type Capability struct {
+	Name      string `json:"name"`
+	Supported bool   `json:"supported"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// Report summarizes the capability probes of one conformance run.
+type Report struct {
+	Adapter      string       `json:"adapter"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// Capability returns the probe result with the given name.
+func (r Report) Capability(name string) (Capability, bool) {
+	for _, capability := range r.Capabilities {
+		if capability.Name == name {
+			return capability, true
+		}
+	}
+	return Capability{}, false
+}
+
+// CheckNames lists the contract checks run by RunConformance, in execution
+// order. Use them with Config.Skip.
+var CheckNames = func() []string {
+	names := make([]string, 0, len(contractChecks))
+	for _, c := range contractChecks {
+		names = append(names, c.name)
+	}
+	return names
+}()
+
+// Capability probe names reported by RunConformance.
+const (
+	CapabilityHeadForGet        = "head_for_get"
+	CapabilityTrailingSlash     = "trailing_slash"
+	CapabilityNamedCatchAll     = "named_catch_all"
+	CapabilityBindForm          = "bind_form"
+	CapabilityBindXML           = "bind_xml"
+	CapabilityForwardedIP       = "forwarded_ip"
+	CapabilityMissHandler       = "miss_handler"
+	CapabilityMethodNotAllowed  = "method_not_allowed"
+	CapabilityRouteMutation     = "route_mutation"
+	CapabilityCatchAllSiblings  = "catch_all_siblings"
+	CapabilityPathConflictMode  = "path_conflict_mode"
+	CapabilityOrderIndependence = "order_independent"
+)
+
+// RunConformance runs the adapter contract against servers built by factory.
+// Contract violations fail t; capability probes never fail and are returned in
+// the report and logged as a capability matrix.
+func RunConformance[T any](t *testing.T, factory Factory[T], cfg ...Config) Report {
+	t.Helper()
+	if factory == nil {
+		t.Fatal("routertest: nil factory")
+	}
+
+	var config Config
+	if len(cfg) > 0 {
+		config = cfg[0]
+	}
+	if config.Name == "" {
+		config.Name = fmt.Sprintf("%T", factory())
+	}
+
+	h := &harness{config: config}
+	newServer := func() serverUnderTest {
+		return newServerUnderTest(factory())
+	}
+
+	for _, c := range contractChecks {
+		t.Run(c.name, func(t *testing.T) {
+			if h.skipped(c.name) {
+				t.Skipf("skipped by config")
+			}
+			if c.network && config.DisableNetwork {
+				t.Skip("network checks disabled")
+			}
+			c.run(t, h, newServer())
+		})
+	}
+
+	report := &h.report
+	report.Adapter = config.Name
+	for _, p := range capabilityProbes {
+		if h.skipped("capability/" + p.name) {
+			continue
+		}
+		t.Run("capability/"+p.name, func(t *testing.T) {
+			result := p.run(t, h, newServer())
+			result.Name = p.name
+			report.Capabilities = append(report.Capabilities, result)
+		})
+	}
+
+	t.Log("\n" + FormatCapabilityMatrix(*report))
+	return *report
+}
+
+// serverUnderTest erases the adapter type parameter so checks can be plain
+// functions rather than generic closures.
+type serverUnderTest struct {
+	server  any
+	router  routerUnderTest
+	wrapped func() any
+	serve   func(address string) error
+	close   func(ctx context.Context) error
+}
+
+// routerUnderTest is the subset of router.Router[T] that does not depend on T.
+type routerUnderTest interface {
+	Handle(method router.HTTPMethod, path string, handler router.HandlerFunc, middlewares ...router.MiddlewareFunc) router.RouteInfo
+	Get(path string, handler router.HandlerFunc, mw ...router.MiddlewareFunc) router.RouteInfo
+	Post(path string, handler router.HandlerFunc, mw ...router.MiddlewareFunc) router.RouteInfo
+	Put(path string, handler router.HandlerFunc, mw ...router.MiddlewareFunc) router.RouteInfo
+	Patch(path string, handler router.HandlerFunc, mw ...router.MiddlewareFunc) router.RouteInfo
+	Delete(path string, handler router.HandlerFunc, mw ...router.MiddlewareFunc) router.RouteInfo
+	WebSocket(path string, config router.WebSocketConfig, handler func(router.WebSocketContext) error) router.RouteInfo
+	Routes() []router.RouteDefinition
+
+	group(prefix string) routerUnderTest
+	use(m ...router.MiddlewareFunc)
+	static(prefix, root string)
+	raw() any
+}
+
+type typedRouter[T any] struct {
+	router.Router[T]
+}
+
+func (r typedRouter[T]) group(prefix string) routerUnderTest {
+	return typedRouter[T]{r.Group(prefix)}
+}
+
+func (r typedRouter[T]) use(m ...router.MiddlewareFunc) { r.Use(m...) }
+
+func (r typedRouter[T]) static(prefix, root string) { r.Static(prefix, root) }
+
+func (r typedRouter[T]) raw() any { return r.Router }
+
+func newServerUnderTest[T any](server router.Server[T]) serverUnderTest {
+	return serverUnderTest{
+		server:  server,
+		router:  typedRouter[T]{server.Router()},
+		wrapped: func() any { return server.WrappedRouter() },
+		serve:   server.Serve,
+		close:   server.Shutdown,
+	}
+}
+
+type harness struct {
+	config Config
+	// report accumulates probe results so later probes can adapt to earlier ones.
+	report Report
+}
+
+func (h *harness) skipped(name string) bool {
+	return slices.ContainsFunc(h.config.Skip, func(skip string) bool {
+		return name == skip || strings.HasPrefix(name, strings.TrimSuffix(skip, "/")+"/")
+	})
+}
+
+// response is a fully read HTTP response.
+type response struct {
+	Status int
+	Header http.Header
+	Body   string
+}
+
+func (h *harness) transport(t *testing.T, s serverUnderTest) RoundTripFunc {
+	t.Helper()
+	wrapped := s.wrapped()
+	var (
+		rt  RoundTripFunc
+		err error
+	)
+	if h.config.Transport != nil {
+		rt, err = h.config.Transport(wrapped)
+	} else {
+		rt, err = transportForWrapped(wrapped)
+	}
+	if err != nil {
+		t.Fatalf("transport: %v", err)
+	}
+	return rt
+}
+
+func (h *harness) do(t *testing.T, rt RoundTripFunc, req *http.Request) response {
+	t.Helper()
+	res, err := rt(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("%s %s: read body: %v", req.Method, req.URL, err)
+	}
+	return response{Status: res.StatusCode, Header: res.Header, Body: string(body)}
+}
+
+func (h *harness) send(t *testing.T, rt RoundTripFunc, method, target string, body io.Reader, headers ...string) response {
+	t.Helper()
+	req := httptest.NewRequest(method, target, body)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return h.do(t, rt, req)
+}
@@ -0,0 +1,93 @@
+package routertest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/goliatone/go-router"
+)
+
+func TestConformance_BuiltInAdapters(t *testing.T) {
+	var reports []Report
+
+	t.Run("fiber", func(t *testing.T) {
+		reports = append(reports, RunConformance(t, func() router.Server[*fiber.App] {
+			return router.NewFiberAdapter()
+		}, Config{Name: "fiber"}))
+	})
+	t.Run("httprouter", func(t *testing.T) {
+		reports = append(reports, RunConformance(t, func() router.Server[*httprouter.Router] {
+			return router.NewHTTPServer()
+		}, Config{Name: "httprouter"}))
+	})
+	t.Run("servemux", func(t *testing.T) {
+		reports = append(reports, RunConformance(t, func() router.Server[*http.ServeMux] {
+			return router.NewServeMuxServer()
+		}, Config{Name: "servemux"}))
+	})
+
+	if len(reports) != 3 {
+		t.Fatalf("reports = %d, want 3", len(reports))
+	}
+	t.Log("\n" + FormatCapabilityMatrix(reports...))
+
+	expect := func(report Report, name string, supported bool) {
+		t.Helper()
+		capability, ok := report.Capability(name)
+		if !ok {
+			t.Fatalf("%s: missing capability %s", report.Adapter, name)
+		}
+		if capability.Supported != supported {
+			t.Errorf("%s: %s supported = %v, want %v (%s)", report.Adapter, name, capability.Supported, supported, capability.Detail)
+		}
+	}
+
+	fiberReport, httpReport, muxReport := reports[0], reports[1], reports[2]
+	expect(fiberReport, CapabilityNamedCatchAll, false)
+	expect(fiberReport, CapabilityBindForm, true)
+	expect(httpReport, CapabilityNamedCatchAll, true)
+	expect(httpReport, CapabilityBindForm, true)
+	expect(httpReport, CapabilityMethodNotAllowed, true)
+	expect(muxReport, CapabilityHeadForGet, true)
+	expect(muxReport, CapabilityCatchAllSiblings, true)
+	expect(muxReport, CapabilityOrderIndependence, true)
+	expect(muxReport, CapabilityMethodNotAllowed, true)
+	for _, report := range reports {
+		expect(report, CapabilityRouteMutation, true)
+		expect(report, CapabilityMissHandler, true)
+	}
+}
+
+func TestConformance_SkipAndMatrix(t *testing.T) {
+	report := RunConformance(t, func() router.Server[*http.ServeMux] {
+		return router.NewServeMuxServer()
+	}, Config{
+		Skip:           []string{"websocket", "capability/" + CapabilityBindXML},
+		DisableNetwork: true,
+	})
+
+	if report.Adapter != "*router.ServeMuxServer" {
+		t.Fatalf("adapter = %q", report.Adapter)
+	}
+	if _, ok := report.Capability(CapabilityBindXML); ok {
+		t.Fatal("skipped capability was probed")
+	}
+
+	matrix := FormatCapabilityMatrix(report, Report{Adapter: "other"})
+	if !strings.Contains(matrix, "| capability | *router.ServeMuxServer | other |") {
+		t.Fatalf("matrix header:\n%s", matrix)
+	}
+	if !strings.Contains(matrix, "| head_for_get | yes") || !strings.Contains(matrix, " - |") {
+		t.Fatalf("matrix rows:\n%s", matrix)
+	}
+}
+
+func TestTransportFor_UnsupportedWrappedRouter(t *testing.T) {
+	if _, err := transportForWrapped(struct{}{}); err == nil {
+		t.Fatal("expected error for unsupported wrapped router")
+	}
+}
@@ -0,0 +1,17 @@
+// Package routertest provides test helpers for go-router adapters and the
+// applications built on them.
+//
+// RunConformance checks a router.Server[T] implementation against the
+// documented adapter contract: routing, Context methods, error handling,
+// WebSocket upgrades, and registration snapshots. Behaviors that differ
+// legitimately between adapters (HEAD handling, trailing slashes, bind formats,
+// proxy-aware IP resolution, miss handlers) are probed rather than enforced and
+// reported as a capability matrix.
+//
+//	func TestMyAdapterConformance(t *testing.T) {
+//		report := routertest.RunConformance(t, func() router.Server[*MyApp] {
+//			return NewMyAdapter()
+//		})
+//		t.Log("\n" + routertest.FormatCapabilityMatrix(report))
+//	}
+package routertest
@@ -0,0 +1,56 @@
+package routertest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatCapabilityMatrix renders reports as a Markdown table with one row per
+// capability and one column per adapter.
+func FormatCapabilityMatrix(reports ...Report) string {
+	var names []string
+	seen := map[string]bool{}
+	for _, report := range reports {
+		for _, capability := range report.Capabilities {
+			if !seen[capability.Name] {
+				seen[capability.Name] = true
+				names = append(names, capability.Name)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("| capability |")
+	for _, report := range reports {
+		fmt.Fprintf(&b, " %s |", report.Adapter)
+	}
+	b.WriteString("\n|---|")
+	for range reports {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "| %s |", name)
+		for _, report := range reports {
+			capability, ok := report.Capability(name)
+			fmt.Fprintf(&b, " %s |", formatCapabilityCell(capability, ok))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func formatCapabilityCell(capability Capability, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	mark := "no"
+	if capability.Supported {
+		mark = "yes"
+	}
+	if capability.Detail == "" {
+		return mark
+	}
+	return mark + " (" + strings.ReplaceAll(capability.Detail, "|", `\|`) + ")"
+}
@@ -0,0 +1,217 @@
+package routertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// RequestBuilder assembles a request fluently. Body setters are mutually
+// exclusive; the last one wins, except that form values become multipart
+// fields once a file is attached.
+type RequestBuilder struct {
+	client  *Client
+	ctx     context.Context
+	method  string
+	target  string
+	header  http.Header
+	query   url.Values
+	cookies []*http.Cookie
+
+	body        io.Reader
+	contentType string
+	bodyErr     error
+
+	form  url.Values
+	files []multipartFile
+}
+
+type multipartFile struct {
+	field    string
+	filename string
+	content  []byte
+}
+
+// Header sets a request header.
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.header.Set(key, value)
+	return b
+}
+
+// Query adds a query parameter to the target URL.
+func (b *RequestBuilder) Query(key, value string) *RequestBuilder {
+	if b.query == nil {
+		b.query = url.Values{}
+	}
+	b.query.Add(key, value)
+	return b
+}
+
+// Cookie adds a request cookie in addition to those in the client's jar.
+func (b *RequestBuilder) Cookie(name, value string) *RequestBuilder {
+	b.cookies = append(b.cookies, &http.Cookie{Name: name, Value: value})
+	return b
+}
+
+// Context sets the request context.
+func (b *RequestBuilder) Context(ctx context.Context) *RequestBuilder {
+	b.ctx = ctx
+	return b
+}
+
+// Body sets a raw body with the given content type.
+func (b *RequestBuilder) Body(body io.Reader, contentType string) *RequestBuilder {
+	b.body, b.contentType, b.form, b.files = body, contentType, nil, nil
+	return b
+}
+
+// JSON encodes v as the request body.
+func (b *RequestBuilder) JSON(v any) *RequestBuilder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.bodyErr = fmt.Errorf("routertest: encode JSON body: %w", err)
+		return b
+	}
+	return b.Body(bytes.NewReader(data), "application/json")
+}
+
+// Form sets url-encoded form values, replacing earlier ones.
+func (b *RequestBuilder) Form(values url.Values) *RequestBuilder {
+	b.body, b.contentType = nil, ""
+	b.form = url.Values{}
+	for key, vals := range values {
+		b.form[key] = append([]string(nil), vals...)
+	}
+	return b
+}
+
+// FormValue adds a single form value.
+func (b *RequestBuilder) FormValue(key, value string) *RequestBuilder {
+	if b.form == nil {
+		b.body, b.contentType = nil, ""
+		b.form = url.Values{}
+	}
+	b.form.Add(key, value)
+	return b
+}
+
+// File attaches a file and switches the body to multipart/form-data.
+func (b *RequestBuilder) File(field, filename string, content []byte) *RequestBuilder {
+	b.body, b.contentType = nil, ""
+	if b.form == nil {
+		b.form = url.Values{}
+	}
+	b.files = append(b.files, multipartFile{field: field, filename: filename, content: content})
+	return b
+}
+
+// Build returns the assembled request.
+func (b *RequestBuilder) Build() (*http.Request, error) {
+	if b.bodyErr != nil {
+		return nil, b.bodyErr
+	}
+
+	target, err := b.client.baseURL.Parse(b.target)
+	if err != nil {
+		return nil, fmt.Errorf("routertest: parse target %q: %w", b.target, err)
+	}
+	if len(b.query) > 0 {
+		q := target.Query()
+		for key, vals := range b.query {
+			for _, v := range vals {
+				q.Add(key, v)
+			}
+		}
+		target.RawQuery = q.Encode()
+	}
+
+	body, contentType, err := b.encodeBody()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, b.method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("routertest: build request: %w", err)
+	}
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.RequestURI = target.RequestURI()
+	for key, vals := range b.header {
+		req.Header[key] = append([]string(nil), vals...)
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, cookie := range b.cookies {
+		req.AddCookie(cookie)
+	}
+	return req, nil
+}
+
+func (b *RequestBuilder) encodeBody() (io.Reader, string, error) {
+	switch {
+	case len(b.files) > 0:
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for key, vals := range b.form {
+			for _, v := range vals {
+				if err := writer.WriteField(key, v); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		for _, file := range b.files {
+			part, err := writer.CreateFormFile(file.field, file.filename)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := part.Write(file.content); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, writer.FormDataContentType(), nil
+	case b.form != nil:
+		return strings.NewReader(b.form.Encode()), "application/x-www-form-urlencoded", nil
+	default:
+		return b.body, b.contentType, nil
+	}
+}
+
+// Send executes the request and returns transport or build errors.
+func (b *RequestBuilder) Send() (*Response, error) {
+	req, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	res, renders, err := b.client.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("routertest: %s %s: %w", req.Method, req.URL.RequestURI(), err)
+	}
+	return newResponse(b.client, req, res, renders)
+}
+
+// Do executes the request, failing t on transport or build errors. The returned
+// response reports assertion failures to t.
+func (b *RequestBuilder) Do(t testing.TB) *Response {
+	t.Helper()
+	res, err := b.Send()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.t = t
+	return res
+}
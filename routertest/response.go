@@ -0,0 +1,359 @@
+package routertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/flash"
+)
+
+// Response is a fully read response. Assert methods report failures to the
+// testing.TB passed to RequestBuilder.Do and return the response for chaining;
+// on responses from Send they panic instead.
+type Response struct {
+	Request    *http.Request
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Renders lists templates rendered while serving the request. It is only
+	// populated when the client has a ViewRecorder.
+	Renders []Render
+
+	t      testing.TB
+	client *Client
+	raw    *http.Response
+}
+
+func newResponse(client *Client, req *http.Request, res *http.Response, renders []Render) (*Response, error) {
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("routertest: read response body: %w", err)
+	}
+	return &Response{
+		Request:    req,
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+		Renders:    renders,
+		t:          unboundTB{},
+		client:     client,
+		raw:        res,
+	}, nil
+}
+
+// unboundTB backs responses returned by Send, which have no test to report to.
+type unboundTB struct {
+	testing.TB
+}
+
+func (unboundTB) Helper() {}
+
+func (unboundTB) Errorf(format string, args ...any) {
+	panic(fmt.Sprintf("routertest: assertion failed on a response from Send (use Do): "+format, args...))
+}
+
+func (unboundTB) Fatal(args ...any) {
+	panic(fmt.Sprint(append([]any{"routertest: "}, args...)...))
+}
+
+func (r *Response) errorf(format string, args ...any) {
+	r.t.Helper()
+	r.t.Errorf("%s %s: "+format, append([]any{r.Request.Method, r.Request.URL.RequestURI()}, args...)...)
+}
+
+// String returns the body as a string.
+func (r *Response) String() string {
+	return string(r.Body)
+}
+
+// Cookies returns the cookies set by the response.
+func (r *Response) Cookies() []*http.Cookie {
+	return r.raw.Cookies()
+}
+
+// Cookie returns the response cookie with the given name.
+func (r *Response) Cookie(name string) (*http.Cookie, bool) {
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == name {
+			return cookie, true
+		}
+	}
+	return nil, false
+}
+
+// DecodeJSON unmarshals the body into v.
+func (r *Response) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// JSONPath returns the value at a dot separated path such as "data.items.0.id".
+// Numbers decode as float64. An empty path or "$" returns the whole document.
+func (r *Response) JSONPath(path string) (any, bool) {
+	var doc any
+	if err := json.Unmarshal(r.Body, &doc); err != nil {
+		return nil, false
+	}
+	return lookupJSONPath(doc, path)
+}
+
+// Flash returns the flash data set by this response, or nil when the response
+// did not set the flash cookie.
+func (r *Response) Flash() router.ViewContext {
+	cookie, ok := r.Cookie(r.client.flashName)
+	if !ok || cookie.Value == "" {
+		return nil
+	}
+	return flash.Decode(cookie.Value)
+}
+
+// Follow issues a GET to the Location of a redirect response using the same
+// client, carrying over its cookie jar.
+func (r *Response) Follow() *Response {
+	r.t.Helper()
+	location := r.Header.Get("Location")
+	if location == "" {
+		r.errorf("Follow: response has no Location header (status %d)", r.StatusCode)
+		return r
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		r.errorf("Follow: invalid Location %q: %v", location, err)
+		return r
+	}
+	return r.client.Get(r.Request.URL.ResolveReference(u).String()).Do(r.t)
+}
+
+// AssertStatus checks the status code.
+func (r *Response) AssertStatus(want int) *Response {
+	r.t.Helper()
+	if r.StatusCode != want {
+		r.errorf("status = %d, want %d (body %q)", r.StatusCode, want, truncate(r.String(), 200))
+	}
+	return r
+}
+
+// AssertHeader checks a header value exactly.
+func (r *Response) AssertHeader(key, want string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); got != want {
+		r.errorf("header %s = %q, want %q", key, got, want)
+	}
+	return r
+}
+
+// AssertHeaderContains checks that a header contains substr.
+func (r *Response) AssertHeaderContains(key, substr string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); !strings.Contains(got, substr) {
+		r.errorf("header %s = %q, want it to contain %q", key, got, substr)
+	}
+	return r
+}
+
+// AssertBody checks the body exactly.
+func (r *Response) AssertBody(want string) *Response {
+	r.t.Helper()
+	if got := r.String(); got != want {
+		r.errorf("body = %q, want %q", truncate(got, 200), want)
+	}
+	return r
+}
+
+// AssertBodyContains checks that the body contains substr.
+func (r *Response) AssertBodyContains(substr string) *Response {
+	r.t.Helper()
+	if !strings.Contains(r.String(), substr) {
+		r.errorf("body %q does not contain %q", truncate(r.String(), 200), substr)
+	}
+	return r
+}
+
+// AssertJSONPath checks the value at path. want is compared after a JSON round
+// trip, so AssertJSONPath("count", 3) matches a decoded float64.
+func (r *Response) AssertJSONPath(path string, want any) *Response {
+	r.t.Helper()
+	got, ok := r.JSONPath(path)
+	if !ok {
+		r.errorf("JSON path %q not found in %s", path, truncate(r.String(), 200))
+		return r
+	}
+	normalized, err := normalizeJSON(want)
+	if err != nil {
+		r.errorf("JSON path %q: encode expected value: %v", path, err)
+		return r
+	}
+	if !reflect.DeepEqual(got, normalized) {
+		r.errorf("JSON path %q = %#v, want %#v", path, got, normalized)
+	}
+	return r
+}
+
+// AssertTemplate checks that name was rendered while serving the request.
+// It requires WithViewRecorder.
+func (r *Response) AssertTemplate(name string) *Response {
+	r.t.Helper()
+	if r.client.views == nil {
+		r.errorf("AssertTemplate requires a client created WithViewRecorder")
+		return r
+	}
+	names := make([]string, 0, len(r.Renders))
+	for _, render := range r.Renders {
+		names = append(names, render.Name)
+	}
+	if !slices.Contains(names, name) {
+		r.errorf("template %q not rendered (rendered %v)", name, names)
+	}
+	return r
+}
+
+// AssertCookie checks the value of a cookie set by the response.
+func (r *Response) AssertCookie(name, want string) *Response {
+	r.t.Helper()
+	cookie, ok := r.Cookie(name)
+	if !ok {
+		r.errorf("cookie %q not set", name)
+		return r
+	}
+	if cookie.Value != want {
+		r.errorf("cookie %q = %q, want %q", name, cookie.Value, want)
+	}
+	return r
+}
+
+// AssertFlash checks a flash value set by the response. Flash values are
+// serialized as strings, so want is formatted with %v before comparison.
+func (r *Response) AssertFlash(key string, want any) *Response {
+	r.t.Helper()
+	data := r.Flash()
+	if data == nil {
+		r.errorf("flash cookie %q not set", r.client.flashName)
+		return r
+	}
+	got, ok := data[key]
+	if !ok {
+		r.errorf("flash key %q not set (flash %v)", key, data)
+		return r
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		r.errorf("flash %q = %v, want %v", key, got, want)
+	}
+	return r
+}
+
+// AssertRedirect checks for a 3xx status and the Location header.
+func (r *Response) AssertRedirect(location string) *Response {
+	r.t.Helper()
+	if r.StatusCode < 300 || r.StatusCode >= 400 {
+		r.errorf("status = %d, want a redirect to %s", r.StatusCode, location)
+		return r
+	}
+	if got := r.Header.Get("Location"); got != location {
+		r.errorf("Location = %q, want %q", got, location)
+	}
+	return r
+}
+
+// AssertRedirectToRoute checks for a redirect to the named route built with
+// params. A query string on Location is ignored.
+func (r *Response) AssertRedirectToRoute(name string, params map[string]string) *Response {
+	r.t.Helper()
+	want, err := r.client.RouteURL(name, params)
+	if err != nil {
+		r.errorf("%v", err)
+		return r
+	}
+	if r.StatusCode < 300 || r.StatusCode >= 400 {
+		r.errorf("status = %d, want a redirect to route %q (%s)", r.StatusCode, name, want)
+		return r
+	}
+	location := r.Header.Get("Location")
+	if u, err := url.Parse(location); err == nil {
+		location = u.Path
+	}
+	if location != want {
+		r.errorf("Location path = %q, want route %q (%s)", location, name, want)
+	}
+	return r
+}
+
+func lookupJSONPath(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return doc, true
+	}
+	current := doc
+	for segment := range strings.SplitSeq(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func normalizeJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// buildRoutePath substitutes route parameters into a go-router path pattern.
+func buildRoutePath(pattern string, params map[string]string) (string, error) {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		if idx := strings.IndexByte(name, '<'); idx >= 0 {
+			name = name[:idx]
+		}
+		if segment[0] == '*' && name == "" {
+			name = "*"
+		}
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("routertest: missing param %q for route path %s", name, pattern)
+		}
+		if segment[0] == ':' {
+			value = url.PathEscape(value)
+		}
+		segments[i] = value
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,45 @@
+package routertest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goliatone/go-router"
+)
+
+// RoundTripFunc executes a request against an in-process server.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// fiberTester matches (*fiber.App).Test without importing Fiber.
+type fiberTester interface {
+	Test(req *http.Request, msTimeout ...int) (*http.Response, error)
+}
+
+// TransportFor returns an in-process transport for the server's wrapped
+// router. Wrapped routers implementing http.Handler are served through an
+// httptest.ResponseRecorder; Fiber-style apps are served through Test.
+// Calling TransportFor initializes the server.
+func TransportFor[T any](server router.Server[T]) (RoundTripFunc, error) {
+	if server == nil {
+		return nil, fmt.Errorf("routertest: nil server")
+	}
+	return transportForWrapped(any(server.WrappedRouter()))
+}
+
+func transportForWrapped(wrapped any) (RoundTripFunc, error) {
+	switch app := wrapped.(type) {
+	case http.Handler:
+		return func(req *http.Request) (*http.Response, error) {
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			return rec.Result(), nil
+		}, nil
+	case fiberTester:
+		return func(req *http.Request) (*http.Response, error) {
+			return app.Test(req, -1)
+		}, nil
+	default:
+		return nil, fmt.Errorf("routertest: unsupported wrapped router %T; set Config.Transport", wrapped)
+	}
+}
@@ -0,0 +1,70 @@
+package routertest
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/goliatone/go-router"
+)
+
+// Render is a template render captured by a ViewRecorder.
+type Render struct {
+	Name    string
+	Layouts []string
+	Bind    any
+}
+
+// ViewRecorder is a router.Views that records every render before delegating
+// to an optional inner engine. Install it as the adapter's view engine, e.g.
+// fiber.Config{Views: recorder}, router.WithHTTPRouterViews(recorder) or
+// router.ServeMuxConfig{Views: recorder}.
+type ViewRecorder struct {
+	mu      sync.Mutex
+	inner   router.Views
+	renders []Render
+}
+
+var _ router.Views = (*ViewRecorder)(nil)
+
+// NewViewRecorder wraps inner. With a nil inner engine, Render writes the
+// template name so responses stay non-empty.
+func NewViewRecorder(inner router.Views) *ViewRecorder {
+	return &ViewRecorder{inner: inner}
+}
+
+// Load loads the inner engine.
+func (v *ViewRecorder) Load() error {
+	if v.inner == nil {
+		return nil
+	}
+	return v.inner.Load()
+}
+
+// Render records the render and delegates to the inner engine.
+func (v *ViewRecorder) Render(w io.Writer, name string, bind any, layouts ...string) error {
+	v.mu.Lock()
+	v.renders = append(v.renders, Render{Name: name, Layouts: slices.Clone(layouts), Bind: bind})
+	v.mu.Unlock()
+
+	if v.inner == nil {
+		_, err := fmt.Fprint(w, name)
+		return err
+	}
+	return v.inner.Render(w, name, bind, layouts...)
+}
+
+// Renders returns the renders recorded since the last Reset.
+func (v *ViewRecorder) Renders() []Render {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return slices.Clone(v.renders)
+}
+
+// Reset clears recorded renders.
+func (v *ViewRecorder) Reset() {
+	v.mu.Lock()
+	v.renders = nil
+	v.mu.Unlock()
+}
@@ -0,0 +1,985 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const serveMuxAdapterName = "servemux"
+
+// serveMuxWildcardName is the ServeMux wildcard used for a bare "*" segment.
+// Handlers read it through Param("*"), matching Fiber's unnamed catch-all.
+const serveMuxWildcardName = "_wildcard"
+
+// ServeMuxConfig configures the net/http ServeMux adapter.
+type ServeMuxConfig struct {
+	// ConflictPolicy defaults to HTTPRouterConflictPanic because http.ServeMux
+	// panics on conflicting patterns. HTTPRouterConflictLogAndContinue behaves
+	// like log-and-skip since the mux cannot hold both registrations.
+	ConflictPolicy   *HTTPRouterConflictPolicy
+	NamedRoutePolicy NamedRouteCollisionPolicy
+	StrictRoutes     bool
+	// Views is the engine used by Context.Render.
+	Views Views
+	// HTTP2 enables h2c and tunes HTTP/2. See HTTP2Config.
+	HTTP2 *HTTP2Config
+}
+
+func (cfg ServeMuxConfig) withDefaults() ServeMuxConfig {
+	if cfg.ConflictPolicy == nil {
+		policy := HTTPRouterConflictPanic
+		cfg.ConflictPolicy = &policy
+	}
+	cfg.NamedRoutePolicy = cfg.NamedRoutePolicy.normalize()
+	return cfg
+}
+
+// ServeMuxServer implements Server for the standard library http.ServeMux
+// using its method and wildcard patterns ("GET /users/{id}", "{path...}").
+type ServeMuxServer struct {
+	lifecycle
+	mu                sync.Mutex
+	mux               *http.ServeMux
+	server            *http.Server
+	router            *ServeMuxRouter
+	views             Views
+	passLocalsToViews bool
+	initialized       bool
+	errorHandler      func(Context, error) error
+	conflictPolicy    HTTPRouterConflictPolicy
+	strictRoutes      bool
+	namedRoutePolicy  NamedRouteCollisionPolicy
+	http2             *HTTP2Config
+}
+
+var (
+	_ Server[*http.ServeMux] = (*ServeMuxServer)(nil)
+	_ LifecycleHooks         = (*ServeMuxServer)(nil)
+	_ ListenerServer         = (*ServeMuxServer)(nil)
+	_ TLSServer              = (*ServeMuxServer)(nil)
+)
+
+// NewServeMuxServer creates a Server backed by a new http.ServeMux.
+func NewServeMuxServer(opts ...func(*http.ServeMux) *http.ServeMux) Server[*http.ServeMux] {
+	return NewServeMuxServerWithConfig(ServeMuxConfig{}, opts...)
+}
+
+// NewServeMuxServerWithConfig allows callers to override adapter-level settings.
+func NewServeMuxServerWithConfig(cfg ServeMuxConfig, opts ...func(*http.ServeMux) *http.ServeMux) Server[*http.ServeMux] {
+	cfg = cfg.withDefaults()
+	mux := http.NewServeMux()
+	for _, opt := range opts {
+		mux = opt(mux)
+	}
+
+	return &ServeMuxServer{
+		mux:              mux,
+		errorHandler:     DefaultHTTPErrorHandler(DefaultHTTPErrorHandlerConfig()),
+		conflictPolicy:   *cfg.ConflictPolicy,
+		strictRoutes:     cfg.StrictRoutes,
+		namedRoutePolicy: cfg.NamedRoutePolicy,
+		views:            cfg.Views,
+		http2:            cfg.HTTP2,
+	}
+}
+
+func (a *ServeMuxServer) Router() Router[*http.ServeMux] {
+	if a.router == nil {
+		if a.errorHandler == nil {
+			a.errorHandler = DefaultHTTPErrorHandler(DefaultHTTPErrorHandlerConfig())
+		}
+		a.router = &ServeMuxRouter{
+			mux:            a.mux,
+			errorHandler:   a.errorHandler,
+			conflictPolicy: a.conflictPolicy,
+			BaseRouter: BaseRouter{
+				logger:           &defaultLogger{},
+				namedRoutePolicy: a.namedRoutePolicy,
+				routes:           []*RouteDefinition{},
+				middlewares:      []namedMiddleware{},
+				root: &routerRoot{
+					routes:            []*RouteDefinition{},
+					matchingSemantics: RouteMatchingSemantics{TrailingSlashDistinct: true},
+				},
+				views:             a.views,
+				passLocalsToViews: a.passLocalsToViews,
+			},
+		}
+	}
+	return a.router
+}
+
+// WrapHandler adapts h to an http.HandlerFunc. Path parameters are read from
+// the ServeMux pattern that matched the request, so the returned handler can be
+// registered directly on the wrapped mux.
+func (a *ServeMuxServer) WrapHandler(h HandlerFunc) any {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.Router()
+		params := serveMuxRequestParams(r)
+		c := newHTTPRouterContext(w, r, params, a.views)
+		c.router = a.router
+		c.adapter = serveMuxAdapterName
+		c.passLocalsToViews = a.passLocalsToViews
+
+		if r.Pattern != "" {
+			method, pathPattern := splitServeMuxPattern(r.Pattern)
+			if method == "" {
+				method = r.Method
+			}
+			if routeName, ok := a.router.RouteNameFromPath(method, normalizeServeMuxPath(pathPattern)); ok {
+				goCtx := c.Context()
+				goCtx = WithRouteName(goCtx, routeName)
+				goCtx = WithRouteParams(goCtx, httpRouterParamsMap(params))
+				c.SetContext(goCtx)
+			}
+		}
+
+		if err := h(c); err != nil {
+			if a.errorHandler == nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if handleErr := a.errorHandler(c, err); handleErr != nil {
+				a.router.logger.Error("error handler failed: %v", handleErr)
+			}
+		}
+	}
+}
+
+func (a *ServeMuxServer) WrappedRouter() *http.ServeMux {
+	a.Init()
+	return a.mux
+}
+
+// Init mounts the declared routes. It panics if a route hook rejects a route.
+func (a *ServeMuxServer) Init() {
+	if err := a.initialize(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *ServeMuxServer) initialize() (err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.initialized {
+		return nil
+	}
+
+	if a.router == nil {
+		a.Router()
+	}
+
+	a.router.registerLateRoutes(a.router)
+	if !a.router.root.beginFinalization() {
+		a.initialized = true
+		return nil
+	}
+	// Route hooks run once the root lock is released.
+	defer func() { err = a.router.root.announceRoutes() }()
+	defer a.router.root.finishFinalization()
+
+	if a.strictRoutes {
+		if errs := a.router.ValidateRoutes(); len(errs) > 0 {
+			panic(errors.Join(errs...))
+		}
+	}
+
+	if len(a.router.root.missHandlers) > 0 {
+		// "/" has the lowest precedence of any ServeMux pattern, so it only
+		// receives requests that no declared route accepts.
+		a.mux.Handle("/", http.HandlerFunc(a.serveMiss))
+	}
+
+	a.initialized = true
+	return nil
+}
+
+func (a *ServeMuxServer) serveMiss(w http.ResponseWriter, r *http.Request) {
+	if a.router.serveMissHandler(w, r) {
+		return
+	}
+	// Registering the fallback pattern hides ServeMux's own 405 handling,
+	// so restore it from the declared plan.
+	if allowed := a.router.allowedMethods(r.URL.Path); len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (a *ServeMuxServer) Serve(address string) error {
+	if err := a.runStartup(context.Background(), a.prepare); err != nil {
+		return err
+	}
+	ln, err := listenTCP("tcp", address)
+	if err != nil {
+		return err
+	}
+	return a.ServeListener(ln)
+}
+
+// ServeListener serves the router on ln. See ListenerServer.
+func (a *ServeMuxServer) ServeListener(ln net.Listener) error {
+	if err := a.runStartup(context.Background(), a.prepare); err != nil {
+		return errors.Join(err, ln.Close())
+	}
+	if err := a.runListen(ln); err != nil {
+		return err
+	}
+	return a.httpServer().Serve(ln)
+}
+
+// ServeTLS serves the router over TLS on address. See TLSServer.
+func (a *ServeMuxServer) ServeTLS(address string, config TLSConfig) error {
+	return serveTLS(&a.lifecycle, a.prepare, a, "tcp", address, withHTTP2Protos(config))
+}
+
+func (a *ServeMuxServer) prepare() error {
+	if err := a.initialize(); err != nil {
+		return err
+	}
+	if a.views != nil {
+		return a.views.Load()
+	}
+	return nil
+}
+
+// httpServer returns the server shared by every listener.
+func (a *ServeMuxServer) httpServer() *http.Server {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.server == nil {
+		a.server = newNetHTTPServer(a.mux, a.http2)
+	}
+	return a.server
+}
+
+func (a *ServeMuxServer) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	srv := a.server
+	a.mu.Unlock()
+	var err error
+	if srv != nil {
+		err = srv.Shutdown(ctx)
+	}
+	return errors.Join(err, a.runShutdown(ctx))
+}
+
+// OnRouteMounted adds a route hook. See LifecycleHooks.
+func (a *ServeMuxServer) OnRouteMounted(hook func(route RouteDefinition) error) {
+	a.Router()
+	a.router.root.addRouteHook(hook)
+}
+
+// ServeMuxRouter implements Router for http.ServeMux. Declared paths use the
+// package's ":param" and "*catchall" syntax; ServeMux-native "{param}",
+// "{rest...}" and "{$}" segments are accepted and normalized.
+type ServeMuxRouter struct {
+	BaseRouter
+	mux            *http.ServeMux
+	errorHandler   func(Context, error) error
+	conflictPolicy HTTPRouterConflictPolicy
+}
+
+var (
+	_ Router[*http.ServeMux]         = (*ServeMuxRouter)(nil)
+	_ MissHandlerRegistrar           = (*ServeMuxRouter)(nil)
+	_ RegistrationInspector          = (*ServeMuxRouter)(nil)
+	_ RouteMatchingSemanticsProvider = (*ServeMuxRouter)(nil)
+	_ RouteMutator                   = (*ServeMuxRouter)(nil)
+	_ RouteRemover                   = (*ServeMuxRouter)(nil)
+	_ RouteTransactor                = (*ServeMuxRouter)(nil)
+	_ RoutingCapabilityProvider      = (*ServeMuxRouter)(nil)
+	_ HostRouter[*http.ServeMux]     = (*ServeMuxRouter)(nil)
+	_ VersionRouter[*http.ServeMux]  = (*ServeMuxRouter)(nil)
+	_ URLBuilder                     = (*ServeMuxRouter)(nil)
+)
+
+func (r *ServeMuxRouter) GetPrefix() string {
+	return r.prefix
+}
+
+func (r *ServeMuxRouter) Group(prefix string) Router[*http.ServeMux] {
+	return &ServeMuxRouter{
+		mux:            r.mux,
+		errorHandler:   r.errorHandler,
+		conflictPolicy: r.conflictPolicy,
+		BaseRouter: BaseRouter{
+			prefix:            r.joinPath(r.prefix, normalizeServeMuxPath(prefix)),
+			middlewares:       slices.Clone(r.middlewares),
+			logger:            r.logger,
+			namedRoutePolicy:  r.namedRoutePolicy,
+			routes:            r.routes,
+			root:              r.root,
+			views:             r.views,
+			passLocalsToViews: r.passLocalsToViews,
+			routeScope:        r.routeScope,
+		},
+	}
+}
+
+func (r *ServeMuxRouter) Mount(prefix string) Router[*http.ServeMux] {
+	return r.Group(prefix)
+}
+
+// Host returns a group whose routes only answer requests for hosts matching
+// pattern. See HostRouter.
+func (r *ServeMuxRouter) Host(pattern string) Router[*http.ServeMux] {
+	scope := r.routeScope
+	scope.host = mustCompileHostPattern(pattern)
+	return r.scoped(scope)
+}
+
+// Version returns a group whose routes belong to the API version name. See
+// VersionRouter.
+func (r *ServeMuxRouter) Version(name string, strategy VersionStrategy) Router[*http.ServeMux] {
+	g := r.scoped(r.routeScope)
+	g.enterVersion(name, strategy)
+	return g
+}
+
+// scoped returns a group with the prefix and middleware of r and scope.
+func (r *ServeMuxRouter) scoped(scope routeScope) *ServeMuxRouter {
+	g := r.Group("").(*ServeMuxRouter)
+	g.prefix = r.prefix
+	g.routeScope = scope
+	return g
+}
+
+func (r *ServeMuxRouter) withScope(scope routeScope) lateRouteRegistrar {
+	if scope == r.routeScope {
+		return r
+	}
+	return r.scoped(scope)
+}
+
+func (r *ServeMuxRouter) mountFallback(route *RouteDefinition) error {
+	g := r.scoped(route.routeScope)
+	if conflict := g.detectRouteConflict(route.Method, route.Path); conflict != nil {
+		return newRouteConflictError(route.Method, route.Path, conflict, r.conflictPolicy, PathConflictModePreferStatic)
+	}
+	if err := r.root.attachRoute(route, g.handleOnMux); err != nil {
+		return err
+	}
+	r.root.recordMounted(route)
+	return nil
+}
+
+func (r *ServeMuxRouter) WithGroup(path string, cb func(r Router[*http.ServeMux])) Router[*http.ServeMux] {
+	g := r.Group(path)
+	cb(g)
+	return r
+}
+
+func (r *ServeMuxRouter) WithLogger(logger Logger) Router[*http.ServeMux] {
+	r.logger = logger
+	return r
+}
+
+func (r *ServeMuxRouter) Use(m ...MiddlewareFunc) Router[*http.ServeMux] {
+	r.root.beginMutation("register middleware", "", r.prefix)
+	changed := false
+	defer func() { r.root.endMutation(changed) }()
+	for _, mw := range m {
+		r.middlewares = append(r.middlewares, namedMiddleware{
+			Name: funcName(mw),
+			Mw:   mw,
+		})
+	}
+	changed = len(m) > 0
+	return r
+}
+
+func (r *ServeMuxRouter) HandleMiss(method HTTPMethod, handler HandlerFunc, m ...MiddlewareFunc) {
+	if handler == nil {
+		return
+	}
+	r.root.beginMutation("register miss handler", method, r.prefix)
+	defer r.root.endMutation(true)
+	r.setMissHandler(method, handler, r.buildNamedMiddlewares(m))
+}
+
+func (r *ServeMuxRouter) Handle(method HTTPMethod, pathStr string, handler HandlerFunc, m ...MiddlewareFunc) RouteInfo {
+	fullPath := r.joinPath(r.prefix, normalizeServeMuxPath(pathStr))
+	r.root.beginMutation("register route", method, fullPath)
+	changed := false
+	defer func() { r.root.endMutation(changed) }()
+
+	route, err := r.mountRoute(method, fullPath, handler, r.buildNamedMiddlewares(m), "")
+	if err != nil {
+		switch r.conflictPolicy {
+		case HTTPRouterConflictLogAndSkip, HTTPRouterConflictLogAndContinue:
+			if r.logger != nil {
+				r.logger.Warn("route conflict skipped: %v", err)
+			}
+			return noopRouteInfo
+		default:
+			panic(err)
+		}
+	}
+	changed = true
+	return route
+}
+
+// mountRoute validates and mounts a route. Callers must hold the root lock.
+func (r *ServeMuxRouter) mountRoute(method HTTPMethod, fullPath string, handler HandlerFunc, allMw []namedMiddleware, routeName string) (*RouteDefinition, error) {
+	if conflict := r.detectRouteConflict(method, fullPath); conflict != nil {
+		return nil, newRouteConflictError(method, fullPath, conflict, r.conflictPolicy, PathConflictModePreferStatic)
+	}
+
+	constraints := mustCompileRouteParamConstraints(method, fullPath)
+	route := &RouteDefinition{
+		Method:           method,
+		Path:             fullPath,
+		Name:             routeName,
+		Host:             r.host.String(),
+		Version:          r.version.String(),
+		Handlers:         chainHandlers(handler, routeName, allMw),
+		Parameters:       constraints.parameters(),
+		middlewares:      slices.Clone(allMw),
+		paramConstraints: constraints,
+		routeScope:       r.routeScope,
+	}
+	if routeName != "" {
+		r.applyInternalRouteName(route, routeName)
+	}
+
+	if err := r.root.attachRoute(route, r.handleOnMux); err != nil {
+		return nil, err
+	}
+
+	route.onSetName = func(route *RouteDefinition, name string) error {
+		return r.setPublicRouteName(route, name, nil)
+	}
+	r.root.routes = append(r.root.routes, route)
+	r.root.recordMounted(route)
+	return route, nil
+}
+
+// handleOnMux registers the pattern of mount, converting ServeMux
+// registration panics into conflict errors so the configured policy decides
+// the outcome. ServeMux accepts patterns while serving, so routes added by a
+// RouteMutation are mounted the same way.
+func (r *ServeMuxRouter) handleOnMux(mount *routeMount) (err error) {
+	pattern := serveMuxPattern(mount.method, mount.path)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			conflict := &routeConflict{
+				existing: &RouteDefinition{Method: mount.method},
+				reason:   fmt.Sprint(recovered),
+				index:    -1,
+			}
+			err = newRouteConflictError(mount.method, mount.path, conflict, r.conflictPolicy, PathConflictModePreferStatic)
+		}
+	}()
+	r.mux.Handle(pattern, r.serveMuxRouteHandler(mount))
+	return nil
+}
+
+// mountMutation mounts a route added by a RouteMutation.
+func (r *ServeMuxRouter) mountMutation(route *RouteDefinition) error {
+	if err := r.root.attachRoute(route, r.handleOnMux); err != nil {
+		return err
+	}
+	route.onSetName = func(route *RouteDefinition, name string) error {
+		return r.setPublicRouteName(route, name, nil)
+	}
+	r.root.recordMounted(route)
+	return nil
+}
+
+func (r *ServeMuxRouter) mutationPath(path string) string {
+	return r.joinPath(r.prefix, normalizeServeMuxPath(path))
+}
+
+func (r *ServeMuxRouter) BeginRouteMutation() *RouteMutation {
+	return newRouteMutation(&r.BaseRouter, r)
+}
+
+func (r *ServeMuxRouter) TryRemove(method HTTPMethod, path string) error {
+	return tryRemoveRoute(r.BeginRouteMutation(), method, path)
+}
+
+func (r *ServeMuxRouter) serveMuxRouteHandler(mount *routeMount) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		params := serveMuxRequestParams(req)
+		route, hostParams, ok := mount.resolve(routeRequest{
+			hostname: requestHostname(req.Host),
+			header:   req.Header.Get,
+			param:    params.ByName,
+		})
+		if !ok {
+			// ServeMux has no next candidate, so a constraint, host or version
+			// mismatch is a miss.
+			if !r.serveMissHandler(w, req) {
+				http.NotFound(w, req)
+			}
+			return
+		}
+		params = withHostParams(params, hostParams)
+		ctx := newHTTPRouterContext(w, req, params, r.views)
+		ctx.router = r
+		ctx.adapter = serveMuxAdapterName
+		ctx.passLocalsToViews = r.passLocalsToViews
+		ctx.setHandlers(route.Handlers)
+
+		goCtx := ctx.Context()
+		goCtx = WithRouteName(goCtx, route.Name)
+		goCtx = WithRouteParams(goCtx, httpRouterParamsMap(params))
+		goCtx = withRoute(goCtx, route)
+		ctx.SetContext(goCtx)
+		writeRouteHeaders(ctx, route)
+
+		if err := ctx.Next(); err != nil {
+			r.logger.Error("handler chain error: %v", err)
+			if r.errorHandler == nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if handleErr := r.errorHandler(ctx, err); handleErr != nil {
+				r.logger.Error("error handler failed: %v", handleErr)
+			}
+		}
+	}
+}
+
+// serveMissHandler runs the miss handler registered for the request method and
+// reports whether one was found.
+func (r *ServeMuxRouter) serveMissHandler(w http.ResponseWriter, req *http.Request) bool {
+	def := r.missHandler(HTTPMethod(req.Method))
+	if def == nil {
+		return false
+	}
+
+	ctx := newHTTPRouterContext(w, req, nil, r.views)
+	ctx.router = r
+	ctx.adapter = serveMuxAdapterName
+	ctx.passLocalsToViews = r.passLocalsToViews
+	ctx.setHandlers(def.Handlers)
+
+	goCtx := ctx.Context()
+	goCtx = WithRouteName(goCtx, "")
+	goCtx = WithRouteParams(goCtx, map[string]string{})
+	ctx.SetContext(goCtx)
+
+	if err := ctx.Next(); err != nil {
+		if r.errorHandler == nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return true
+		}
+		if handleErr := r.errorHandler(ctx, err); handleErr != nil {
+			r.logger.Error("error handler failed: %v", handleErr)
+		}
+	}
+	return true
+}
+
+func (r *ServeMuxRouter) TryReplace(method HTTPMethod, pathStr string, handler HandlerFunc, m ...MiddlewareFunc) (RouteInfo, error) {
+	return r.TryReplaceWithOptions(method, pathStr, handler, RouteMutationOptions{}, m...)
+}
+
+func (r *ServeMuxRouter) TryReplaceWithOptions(method HTTPMethod, pathStr string, handler HandlerFunc, options RouteMutationOptions, m ...MiddlewareFunc) (RouteInfo, error) {
+	fullPath := r.joinPath(r.prefix, normalizeServeMuxPath(pathStr))
+	r.root.registrationMu.Lock()
+	defer r.root.registrationMu.Unlock()
+	if r.root.registrationState() != RegistrationCollecting {
+		return nil, newRegistrationError("replace route", method, fullPath, r.root.registrationState(), ErrRouterSealed)
+	}
+
+	route := r.findRoute(method, fullPath)
+	if route == nil {
+		return nil, newRouteNotFoundError(method, fullPath)
+	}
+	r.replaceRouteChain(route, handler, options, false, m)
+	r.root.revision++
+	return route, nil
+}
+
+func (r *ServeMuxRouter) TryUpsert(method HTTPMethod, pathStr string, handler HandlerFunc, m ...MiddlewareFunc) (RouteInfo, bool, error) {
+	return r.TryUpsertWithOptions(method, pathStr, handler, RouteMutationOptions{}, m...)
+}
+
+func (r *ServeMuxRouter) TryUpsertWithOptions(method HTTPMethod, pathStr string, handler HandlerFunc, options RouteMutationOptions, m ...MiddlewareFunc) (RouteInfo, bool, error) {
+	fullPath := r.joinPath(r.prefix, normalizeServeMuxPath(pathStr))
+	r.root.registrationMu.Lock()
+	defer r.root.registrationMu.Unlock()
+	if r.root.registrationState() != RegistrationCollecting {
+		return nil, false, newRegistrationError("upsert route", method, fullPath, r.root.registrationState(), ErrRouterSealed)
+	}
+
+	if route := r.findRoute(method, fullPath); route != nil {
+		r.replaceRouteChain(route, handler, options, options.MiddlewareOnAddOnly, m)
+		r.root.revision++
+		return route, true, nil
+	}
+
+	route, err := r.mountRoute(method, fullPath, handler, r.buildNamedMiddlewares(m), "")
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Warn("route conflict rejected during upsert: %v", err)
+		}
+		return nil, false, err
+	}
+	r.root.revision++
+	return route, false, nil
+}
+
+func (r *ServeMuxRouter) findRoute(method HTTPMethod, fullPath string) *RouteDefinition {
+	for _, route := range r.root.routes {
+		if r.isSameRoute(route, method, fullPath) {
+			return route
+		}
+	}
+	return nil
+}
+
+func (r *ServeMuxRouter) replaceRouteChain(route *RouteDefinition, handler HandlerFunc, options RouteMutationOptions, skipSupplied bool, m []MiddlewareFunc) {
+	allMw := slices.Clone(route.middlewares)
+	if options.ReplaceMiddleware {
+		allMw = slices.Clone(r.middlewares)
+	}
+	if !skipSupplied {
+		for _, mw := range m {
+			allMw = append(allMw, namedMiddleware{Name: funcName(mw), Mw: mw})
+		}
+	}
+	route.middlewares = slices.Clone(allMw)
+	route.Handlers = chainHandlers(handler, route.Name, allMw)
+}
+
+func (r *ServeMuxRouter) detectRouteConflict(method HTTPMethod, fullPath string) *routeConflict {
+	for _, route := range r.root.routes {
+		if route.Method != method || r.isRouteVariant(route, fullPath) {
+			continue
+		}
+		if route.Path == fullPath {
+			return &routeConflict{
+				existing: route,
+				reason:   "duplicate route",
+				index:    -1,
+			}
+		}
+		if conflict := detectServeMuxPathConflict(route.Path, fullPath); conflict != nil {
+			conflict.existing = route
+			return conflict
+		}
+	}
+	return nil
+}
+
+// allowedMethods lists the declared methods whose pattern accepts path.
+func (r *ServeMuxRouter) allowedMethods(path string) []string {
+	r.root.registrationMu.Lock()
+	defer r.root.registrationMu.Unlock()
+	seen := make(map[string]struct{})
+	methods := make([]string, 0)
+	for _, route := range r.root.routes {
+		if !pathMatchesPattern(route.Path, path) || (hasTrailingSlash(route.Path) != hasTrailingSlash(path) && !containsCatchAll(route.Path)) {
+			continue
+		}
+		method := string(route.Method)
+		if _, ok := seen[method]; ok {
+			continue
+		}
+		seen[method] = struct{}{}
+		methods = append(methods, method)
+		if route.Method == GET {
+			if _, ok := seen[string(HEAD)]; !ok {
+				seen[string(HEAD)] = struct{}{}
+				methods = append(methods, string(HEAD))
+			}
+		}
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+func (r *ServeMuxRouter) Get(path string, handler HandlerFunc, mw ...MiddlewareFunc) RouteInfo {
+	return r.Handle(GET, path, handler, mw...)
+}
+
+func (r *ServeMuxRouter) Post(path string, handler HandlerFunc, mw ...MiddlewareFunc) RouteInfo {
+	return r.Handle(POST, path, handler, mw...)
+}
+
+func (r *ServeMuxRouter) Put(path string, handler HandlerFunc, mw ...MiddlewareFunc) RouteInfo {
+	return r.Handle(PUT, path, handler, mw...)
+}
+
+func (r *ServeMuxRouter) Delete(path string, handler HandlerFunc, mw ...MiddlewareFunc) RouteInfo {
+	return r.Handle(DELETE, path, handler, mw...)
+}
+
+func (r *ServeMuxRouter) Patch(path string, handler HandlerFunc, mw ...MiddlewareFunc) RouteInfo {
+	return r.Handle(PATCH, path, handler, mw...)
+}
+
+func (r *ServeMuxRouter) Head(path string, handler HandlerFunc, mw ...MiddlewareFunc) RouteInfo {
+	return r.Handle(HEAD, path, handler, mw...)
+}
+
+func (r *ServeMuxRouter) Static(prefix, root string, config ...Static) Router[*http.ServeMux] {
+	fullPrefix := r.joinPath(r.prefix, prefix)
+	path, handler := r.makeStaticHandler(fullPrefix, root, config...)
+	wildcard := r.joinPath(path, "*filepath")
+	if path != "/" {
+		r.addInternalLateRoute(GET, path, handler, "static.get")
+		r.addInternalLateRoute(HEAD, path, handler, "static.head")
+	}
+	r.addInternalLateRoute(GET, wildcard, handler, "static.get")
+	r.addInternalLateRoute(HEAD, wildcard, handler, "static.head")
+	return r
+}
+
+func (r *ServeMuxRouter) WebSocket(path string, config WebSocketConfig, handler func(WebSocketContext) error) RouteInfo {
+	fullPath := r.joinPath(r.prefix, normalizeServeMuxPath(path))
+	r.root.beginMutation("register websocket route", GET, fullPath)
+	changed := false
+	defer func() { r.root.endMutation(changed) }()
+
+	r.logger.Info("registering websocket route", "path", path, "fullPath", fullPath)
+
+	httpHandler := HTTPRouterWebSocketHandler(config, handler, r.views)
+	routeHandler := func(ctx Context) error {
+		hc, ok := ctx.(*httpRouterContext)
+		if !ok || hc == nil {
+			return fmt.Errorf("expected httpRouterContext, got %T", ctx)
+		}
+		httpHandler(hc.w, hc.r, hc.params)
+		return nil
+	}
+
+	route, err := r.mountRoute(GET, fullPath, routeHandler, slices.Clone(r.middlewares), "websocket")
+	if err != nil {
+		switch r.conflictPolicy {
+		case HTTPRouterConflictLogAndSkip, HTTPRouterConflictLogAndContinue:
+			if r.logger != nil {
+				r.logger.Warn("route conflict skipped: %v", err)
+			}
+			return noopRouteInfo
+		default:
+			panic(err)
+		}
+	}
+	changed = true
+	return route
+}
+
+func (r *ServeMuxRouter) PrintRoutes() {
+	r.BaseRouter.PrintRoutes()
+}
+
+func (r *ServeMuxRouter) ValidateRoutes() []error {
+	routes := collectRoutesForValidation(&r.BaseRouter)
+	errs := ValidateRouteDefinitionsWithOptions(routes, r.routeValidationOptions())
+	if r.namedRoutePolicy.normalize() == NamedRouteCollisionPolicyError {
+		errs = append(errs, r.namedRouteConflicts()...)
+	}
+	return errs
+}
+
+func (r *ServeMuxRouter) routeValidationOptions() RouteValidationOptions {
+	return RouteValidationOptions{
+		PathConflictMode:         PathConflictModePreferStatic,
+		EnforceCatchAllConflicts: false,
+		EnforceRouteLints:        false,
+		NamedRoutePolicy:         r.namedRoutePolicy,
+	}
+}
+
+// RoutingCapabilities reports ServeMux's precedence model: the most specific
+// pattern wins regardless of registration order, static segments beat
+// wildcards, and catch-alls coexist with narrower routes.
+func (r *ServeMuxRouter) RoutingCapabilities() RoutingCapabilities {
+	caps := r.BaseRouter.RoutingCapabilities()
+	caps.PathConflictMode = PathConflictModePreferStatic
+	caps.CatchAllSiblings = true
+	caps.OrderIndependent = true
+	return caps
+}
+
+// detectServeMuxPathConflict applies ServeMux's rule: two patterns for the same
+// method conflict when they accept a common request and neither accepts a
+// strict subset of the other's requests.
+func detectServeMuxPathConflict(existingPath, newPath string) *routeConflict {
+	if !serveMuxPatternsOverlap(existingPath, newPath) {
+		return nil
+	}
+	semantics := RouteMatchingSemantics{TrailingSlashDistinct: true}
+	existingContainsNew := routePatternContainsWithSemantics(existingPath, newPath, semantics)
+	newContainsExisting := routePatternContainsWithSemantics(newPath, existingPath, semantics)
+	if existingContainsNew != newContainsExisting {
+		return nil
+	}
+
+	reason := "pattern overlaps existing route and neither is more specific"
+	if existingContainsNew {
+		reason = "wildcard segment conflicts with existing route"
+	}
+	return &routeConflict{
+		reason: reason,
+		index:  -1,
+	}
+}
+
+func serveMuxPatternsOverlap(left, right string) bool {
+	leftParts := splitPathSegments(left)
+	rightParts := splitPathSegments(right)
+	for i := 0; ; i++ {
+		leftDone := i >= len(leftParts)
+		rightDone := i >= len(rightParts)
+		if !leftDone && classifySegment(leftParts[i]) == segmentCatchAll {
+			return true
+		}
+		if !rightDone && classifySegment(rightParts[i]) == segmentCatchAll {
+			return true
+		}
+		if leftDone || rightDone {
+			return leftDone && rightDone && hasTrailingSlash(left) == hasTrailingSlash(right)
+		}
+		leftKind := classifySegment(leftParts[i])
+		rightKind := classifySegment(rightParts[i])
+		if leftKind == segmentStatic && rightKind == segmentStatic && leftParts[i] != rightParts[i] {
+			return false
+		}
+	}
+}
+
+// serveMuxPattern converts a declared route into a ServeMux pattern.
+func serveMuxPattern(method HTTPMethod, fullPath string) string {
+	segments := splitPathSegments(fullPath)
+	var b strings.Builder
+	if method != "" {
+		b.WriteString(string(method))
+		b.WriteByte(' ')
+	}
+	catchAll := false
+	for _, segment := range segments {
+		b.WriteByte('/')
+		switch classifySegment(segment) {
+		case segmentParam:
+			name, _, _ := parseParamSegment(segment)
+			b.WriteString("{" + name + "}")
+		case segmentCatchAll:
+			name := strings.TrimPrefix(segment, "*")
+			if name == "" {
+				name = serveMuxWildcardName
+			}
+			b.WriteString("{" + name + "...}")
+			catchAll = true
+		default:
+			b.WriteString(segment)
+		}
+	}
+	if !catchAll && (len(segments) == 0 || hasTrailingSlash(fullPath)) {
+		// A trailing slash would otherwise register a ServeMux subtree.
+		b.WriteString("/{$}")
+	}
+	return b.String()
+}
+
+// normalizeServeMuxPath rewrites ServeMux-native wildcard segments into the
+// package's declared path syntax so conflict checks, manifests and OpenAPI
+// output see a single representation.
+func normalizeServeMuxPath(path string) string {
+	if !strings.Contains(path, "{") {
+		return path
+	}
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			continue
+		}
+		name := part[1 : len(part)-1]
+		switch {
+		case name == "$":
+			parts[i] = ""
+		case strings.HasSuffix(name, "..."):
+			parts[i] = "*" + strings.TrimSuffix(name, "...")
+		default:
+			parts[i] = ":" + name
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// splitServeMuxPattern separates "[METHOD ][HOST]/path" into method and path.
+func splitServeMuxPattern(pattern string) (string, string) {
+	method := ""
+	if before, after, ok := strings.Cut(pattern, " "); ok {
+		method = before
+		pattern = strings.TrimLeft(after, " ")
+	}
+	if idx := strings.Index(pattern, "/"); idx > 0 {
+		pattern = pattern[idx:]
+	}
+	return method, pattern
+}
+
+// serveMuxRequestParams exposes the wildcards of the matched pattern as
+// httprouter params so the shared net/http context can serve Param lookups.
+func serveMuxRequestParams(req *http.Request) httprouter.Params {
+	if req == nil || req.Pattern == "" {
+		return nil
+	}
+	_, pathPattern := splitServeMuxPattern(req.Pattern)
+	var params httprouter.Params
+	for segment := range strings.SplitSeq(pathPattern, "/") {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name := strings.TrimSuffix(segment[1:len(segment)-1], "...")
+		if name == "$" {
+			continue
+		}
+		key := name
+		if name == serveMuxWildcardName {
+			key = "*"
+		}
+		params = append(params, httprouter.Param{Key: key, Value: req.PathValue(name)})
+	}
+	return params
+}
+
+func httpRouterParamsMap(ps httprouter.Params) map[string]string {
+	out := make(map[string]string, len(ps))
+	for _, p := range ps {
+		out[p.Key] = p.Value
+	}
+	return out
+}
+
+// ServeMuxWebSocketFactory implements WebSocketContextFactory for ServeMux.
+// ServeMux requests use the net/http context, so upgrades reuse the HTTPRouter
+// WebSocket context implementation.
+type ServeMuxWebSocketFactory struct {
+	HTTPRouterWebSocketFactory
+}
+
+// NewServeMuxWebSocketFactory creates a new ServeMux WebSocket factory
+func NewServeMuxWebSocketFactory() *ServeMuxWebSocketFactory {
+	return &ServeMuxWebSocketFactory{}
+}
+
+// AdapterName returns the adapter name
+func (f *ServeMuxWebSocketFactory) AdapterName() string {
+	return serveMuxAdapterName
+}
+
+// RegisterServeMuxWebSocketFactory registers the ServeMux WebSocket factory globally
+func RegisterServeMuxWebSocketFactory() {
+	RegisterWebSocketFactory(serveMuxAdapterName, NewServeMuxWebSocketFactory())
+}
+
+func init() {
+	RegisterServeMuxWebSocketFactory()
+}
@@ -0,0 +1,282 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	goerrors "github.com/goliatone/go-errors"
+)
+
+func performServeMuxRequest(t *testing.T, handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequestWithContext(t.Context(), method, path, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeMuxRouter_ParamsAndRouteContext(t *testing.T) {
+	server := NewServeMuxServer()
+	r := server.Router()
+
+	r.Get("/users/:id", func(c Context) error {
+		return c.JSON(http.StatusOK, map[string]string{
+			"id":    c.Param("id"),
+			"route": c.RouteName(),
+			"param": c.RouteParams()["id"],
+		})
+	}).SetName("users.show")
+	r.Get("/files/{path...}", func(c Context) error {
+		return c.SendString(c.Param("path"))
+	})
+
+	mux := server.WrappedRouter()
+
+	rec := performServeMuxRequest(t, mux, http.MethodGet, "/users/42")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`"id":"42"`, `"route":"users.show"`, `"param":"42"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("body = %s, want %s", body, want)
+		}
+	}
+
+	rec = performServeMuxRequest(t, mux, http.MethodGet, "/files/css/app.css")
+	if rec.Code != http.StatusOK || rec.Body.String() != "css/app.css" {
+		t.Fatalf("catch-all response = %d %q", rec.Code, rec.Body.String())
+	}
+
+	routes := r.Routes()
+	if len(routes) != 2 || routes[1].Path != "/files/*path" {
+		t.Fatalf("routes = %+v, want normalized catch-all path", routes)
+	}
+}
+
+func TestServeMuxRouter_PrefersStaticSiblingsRegardlessOfOrder(t *testing.T) {
+	server := NewServeMuxServer()
+	r := server.Router()
+
+	r.Get("/admin/*", func(c Context) error { return c.SendString("catch-all") })
+	r.Get("/admin/:section", func(c Context) error { return c.SendString("param") })
+	r.Get("/admin/search", func(c Context) error { return c.SendString("static") })
+
+	mux := server.WrappedRouter()
+	cases := map[string]string{
+		"/admin/search":     "static",
+		"/admin/users":      "param",
+		"/admin/users/list": "catch-all",
+	}
+	for path, want := range cases {
+		rec := performServeMuxRequest(t, mux, http.MethodGet, path)
+		if got := rec.Body.String(); got != want {
+			t.Fatalf("GET %s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestServeMuxRouter_TrailingSlashIsExact(t *testing.T) {
+	server := NewServeMuxServer()
+	r := server.Router()
+	r.Get("/", func(c Context) error { return c.SendString("root") })
+	r.Get("/docs/", func(c Context) error { return c.SendString("docs") })
+
+	mux := server.WrappedRouter()
+	if rec := performServeMuxRequest(t, mux, http.MethodGet, "/docs/"); rec.Body.String() != "docs" {
+		t.Fatalf("GET /docs/ = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := performServeMuxRequest(t, mux, http.MethodGet, "/docs/page"); rec.Code != http.StatusNotFound {
+		t.Fatalf("GET /docs/page status = %d, want 404 (no subtree match)", rec.Code)
+	}
+	if rec := performServeMuxRequest(t, mux, http.MethodGet, "/missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("GET /missing status = %d, want 404 (root is exact)", rec.Code)
+	}
+}
+
+func TestServeMuxRouter_ConflictPolicy(t *testing.T) {
+	t.Run("panic", func(t *testing.T) {
+		server := NewServeMuxServer()
+		r := server.Router()
+		r.Get("/users/:id", func(c Context) error { return nil })
+
+		defer func() {
+			recovered := recover()
+			err, ok := recovered.(error)
+			if !ok {
+				t.Fatalf("recovered = %#v, want route conflict error", recovered)
+			}
+			var routerErr *goerrors.Error
+			if !errors.As(err, &routerErr) || routerErr.TextCode != "ROUTE_CONFLICT" {
+				t.Fatalf("error = %v, want ROUTE_CONFLICT", err)
+			}
+		}()
+		r.Get("/users/:name", func(c Context) error { return nil })
+	})
+
+	t.Run("log and skip ambiguous overlap", func(t *testing.T) {
+		policy := HTTPRouterConflictLogAndSkip
+		server := NewServeMuxServerWithConfig(ServeMuxConfig{ConflictPolicy: &policy})
+		r := server.Router()
+		r.Get("/a/:x", func(c Context) error { return c.SendString("first") })
+		if info := r.Get("/:y/b", func(c Context) error { return c.SendString("second") }); info != noopRouteInfo {
+			t.Fatalf("conflicting route info = %T, want noop", info)
+		}
+		if got := len(r.Routes()); got != 1 {
+			t.Fatalf("routes = %d, want 1", got)
+		}
+	})
+}
+
+func TestServeMuxRouter_MissHandlerAndMethodNotAllowed(t *testing.T) {
+	server := NewServeMuxServer()
+	r := server.Router()
+	r.Get("/health", func(c Context) error { return c.SendString("ok") })
+	r.(MissHandlerRegistrar).HandleMiss(GET, func(c Context) error {
+		return c.Status(http.StatusTeapot).SendString("miss " + c.Path())
+	})
+
+	mux := server.WrappedRouter()
+	if rec := performServeMuxRequest(t, mux, http.MethodGet, "/health"); rec.Body.String() != "ok" {
+		t.Fatalf("GET /health = %q", rec.Body.String())
+	}
+	rec := performServeMuxRequest(t, mux, http.MethodGet, "/unknown")
+	if rec.Code != http.StatusTeapot || rec.Body.String() != "miss /unknown" {
+		t.Fatalf("miss response = %d %q", rec.Code, rec.Body.String())
+	}
+	rec = performServeMuxRequest(t, mux, http.MethodPost, "/health")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST /health status = %d, want 405", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Fatalf("Allow = %q, want GET, HEAD", allow)
+	}
+	if rec := performServeMuxRequest(t, mux, http.MethodPost, "/unknown"); rec.Code != http.StatusNotFound {
+		t.Fatalf("POST /unknown status = %d, want 404", rec.Code)
+	}
+}
+
+func TestServeMuxRouter_TryReplaceAndSnapshot(t *testing.T) {
+	server := NewServeMuxServer()
+	r := server.Router()
+	r.Get("/preview", func(c Context) error { return c.SendString("old") })
+
+	mutator := requireCapability[RouteMutator](t, r)
+	if _, err := mutator.TryReplaceWithOptions(GET, "/preview", func(c Context) error { return c.SendString("new") }, RouteMutationOptions{}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if _, replaced, err := mutator.TryUpsertWithOptions(POST, "/preview", func(c Context) error { return c.SendStatus(http.StatusCreated) }, RouteMutationOptions{}); err != nil || replaced {
+		t.Fatalf("upsert = replaced %v err %v, want added route", replaced, err)
+	}
+
+	mux := server.WrappedRouter()
+	if rec := performServeMuxRequest(t, mux, http.MethodGet, "/preview"); rec.Body.String() != "new" {
+		t.Fatalf("GET /preview = %q, want new", rec.Body.String())
+	}
+	if rec := performServeMuxRequest(t, mux, http.MethodPost, "/preview"); rec.Code != http.StatusCreated {
+		t.Fatalf("POST /preview status = %d", rec.Code)
+	}
+
+	snapshot := requireCapability[RegistrationInspector](t, r).RegistrationSnapshot()
+	if snapshot.State != RegistrationSealed || len(snapshot.MountedRoutes) != 2 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+	if _, err := mutator.TryReplaceWithOptions(GET, "/preview", func(c Context) error { return nil }, RouteMutationOptions{}); !errors.Is(err, ErrRouterSealed) {
+		t.Fatalf("replace after seal err = %v, want ErrRouterSealed", err)
+	}
+}
+
+func TestServeMuxRouter_CapabilitiesAndSemantics(t *testing.T) {
+	r := NewServeMuxServer().Router()
+	caps := requireCapability[RoutingCapabilityProvider](t, r).RoutingCapabilities()
+	if !caps.Manifest || caps.PathConflictMode != PathConflictModePreferStatic || !caps.CatchAllSiblings || !caps.OrderIndependent {
+		t.Fatalf("capabilities = %+v", caps)
+	}
+	if semantics := requireCapability[RouteMatchingSemanticsProvider](t, r).RouteMatchingSemantics(); !semantics.TrailingSlashDistinct {
+		t.Fatalf("semantics = %+v", semantics)
+	}
+
+	httpCaps := NewHTTPServer().Router().(RoutingCapabilityProvider).RoutingCapabilities()
+	if httpCaps.PathConflictMode != PathConflictModeStrict || httpCaps.CatchAllSiblings {
+		t.Fatalf("httprouter capabilities = %+v", httpCaps)
+	}
+}
+
+func TestServeMuxRouter_StaticAndGroups(t *testing.T) {
+	server := NewServeMuxServer()
+	r := server.Router()
+	api := r.Group("/api/{version}")
+	api.Get("/items", func(c Context) error { return c.SendString("items " + c.Param("version")) })
+	r.Static("/assets", "testdata/static")
+
+	mux := server.WrappedRouter()
+	if rec := performServeMuxRequest(t, mux, http.MethodGet, "/api/v2/items"); rec.Body.String() != "items v2" {
+		t.Fatalf("group route = %q", rec.Body.String())
+	}
+
+	rec := performServeMuxRequest(t, mux, http.MethodGet, "/assets/index.html")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("static status = %d", rec.Code)
+	}
+	if body, _ := io.ReadAll(rec.Body); len(body) == 0 {
+		t.Fatal("static body is empty")
+	}
+}
+
+func TestServeMuxRouter_WrapHandlerReadsPatternParams(t *testing.T) {
+	server := NewServeMuxServer()
+	mux := server.WrappedRouter()
+	handler := server.WrapHandler(func(c Context) error {
+		return c.SendString(c.Param("slug") + "|" + c.Param("*"))
+	}).(func(http.ResponseWriter, *http.Request))
+	mux.HandleFunc("GET /posts/{slug}/{_wildcard...}", handler)
+
+	rec := performServeMuxRequest(t, mux, http.MethodGet, "/posts/hello/a/b")
+	if rec.Body.String() != "hello|a/b" {
+		t.Fatalf("wrapped handler body = %q", rec.Body.String())
+	}
+}
+
+func TestServeMuxWebSocketFactoryRegistered(t *testing.T) {
+	factory := GetServeMuxWebSocketFactory()
+	if factory == nil || factory.AdapterName() != "servemux" {
+		t.Fatalf("factory = %#v", factory)
+	}
+
+	ctx := newHTTPRouterContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws", nil), nil, nil)
+	ctx.adapter = serveMuxAdapterName
+	if got := getWebSocketFactory(ctx); got != factory {
+		t.Fatalf("factory for servemux context = %#v", got)
+	}
+}
+
+func TestServeMuxPatternTranslation(t *testing.T) {
+	cases := []struct {
+		method HTTPMethod
+		path   string
+		want   string
+	}{
+		{GET, "/", "GET /{$}"},
+		{GET, "/users/:id", "GET /users/{id}"},
+		{GET, "/users/:id<int>", "GET /users/{id}"},
+		{POST, "/docs/", "POST /docs/{$}"},
+		{GET, "/static/*filepath", "GET /static/{filepath...}"},
+		{GET, "/*", "GET /{_wildcard...}"},
+	}
+	for _, tc := range cases {
+		if got := serveMuxPattern(tc.method, tc.path); got != tc.want {
+			t.Fatalf("serveMuxPattern(%s, %s) = %q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+
+	if got := normalizeServeMuxPath("/users/{id}/files/{rest...}"); got != "/users/:id/files/*rest" {
+		t.Fatalf("normalizeServeMuxPath = %q", got)
+	}
+	if got := normalizeServeMuxPath("/docs/{$}"); got != "/docs/" {
+		t.Fatalf("normalizeServeMuxPath({$}) = %q", got)
+	}
+}
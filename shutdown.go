@@ -0,0 +1,272 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ShutdownPhase names a step of ShutdownCoordinator.Shutdown.
+type ShutdownPhase string
+
+const (
+	// ShutdownStopAccepting starts the shutdown of each server, which closes
+	// its listeners and idle connections.
+	ShutdownStopAccepting ShutdownPhase = "stop_accepting"
+	// ShutdownCloseWebSockets closes WebSocket clients with
+	// CloseServiceRestart and a reconnect hint.
+	ShutdownCloseWebSockets ShutdownPhase = "close_websockets"
+	// ShutdownDrainStreams ends SSE responses and event stream subscriptions.
+	ShutdownDrainStreams ShutdownPhase = "drain_streams"
+	// ShutdownFlushEvents delivers pending acknowledgments and event batches.
+	ShutdownFlushEvents ShutdownPhase = "flush_events"
+	// ShutdownWaitInFlight waits for in-flight handlers to return.
+	ShutdownWaitInFlight ShutdownPhase = "wait_in_flight"
+)
+
+// Shutdowner is implemented by servers, such as Server[T] and http.Server.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// StreamDrainer ends long-lived streams during a shutdown and returns how
+// many it ended. ssefiber.Drainer implements it, and eventstream.AsDrainer
+// adapts an eventstream.Stream.
+type StreamDrainer interface {
+	Drain(ctx context.Context) (int, error)
+}
+
+// Flusher delivers buffered work during a shutdown. AckManager and
+// EventBatcher implement it.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// ShutdownConfig configures a ShutdownCoordinator.
+type ShutdownConfig struct {
+	// ReconnectAfter is the reconnect hint sent to WebSocket clients in the
+	// close reason, as {"reconnect_after_ms":1000}. Defaults to one second.
+	ReconnectAfter time.Duration
+	// DrainTimeout bounds the wait for in-flight handlers, counted from the
+	// start of that phase. Zero waits until the Shutdown context ends.
+	DrainTimeout time.Duration
+	// Logger logs the outcome of each phase when set.
+	Logger Logger
+}
+
+// ShutdownPhaseResult is the outcome of one shutdown phase. Count is the
+// number of servers, clients, streams or flushers the phase handled.
+type ShutdownPhaseResult struct {
+	Phase    ShutdownPhase
+	Count    int
+	Duration time.Duration
+	Err      error
+}
+
+// ShutdownReport lists the outcome of each phase, in order.
+type ShutdownReport struct {
+	Phases []ShutdownPhaseResult
+}
+
+// Err joins the errors of the failed phases.
+func (r ShutdownReport) Err() error {
+	var errs []error
+	for _, phase := range r.Phases {
+		if phase.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", phase.Phase, phase.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ShutdownCoordinator shuts an application down in ordered phases, so
+// clients of a rolling deploy move to another instance instead of being cut
+// off mid-message:
+//
+//  1. stop accepting new requests
+//  2. close WebSocket clients with code 1012 and a reconnect hint
+//  3. end SSE streams with a final retry directive
+//  4. flush acknowledgments and event batches
+//  5. wait for in-flight handlers, up to DrainTimeout
+//
+// Register the parts of the application, then call Shutdown once, usually on
+// SIGTERM:
+//
+//	coordinator := router.NewShutdownCoordinator(router.ShutdownConfig{DrainTimeout: 20 * time.Second})
+//	coordinator.AddServer(app).AddWSHub(hub).AddStreams(sseDrainer, eventstream.AsDrainer(stream)).AddFlusher(acks)
+//
+//	report := coordinator.Shutdown(ctx)
+//	if err := report.Err(); err != nil {
+//		log.Printf("unclean shutdown: %v", err)
+//	}
+type ShutdownCoordinator struct {
+	config ShutdownConfig
+
+	mu       sync.Mutex
+	servers  []Shutdowner
+	hubs     []*WSHub
+	streams  []StreamDrainer
+	flushers []Flusher
+
+	once   sync.Once
+	report ShutdownReport
+}
+
+// NewShutdownCoordinator returns a coordinator with no registered parts.
+func NewShutdownCoordinator(config ...ShutdownConfig) *ShutdownCoordinator {
+	cfg := ShutdownConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.ReconnectAfter <= 0 {
+		cfg.ReconnectAfter = time.Second
+	}
+	return &ShutdownCoordinator{config: cfg}
+}
+
+// AddServer registers servers that stop accepting in the first phase and are
+// awaited in the last.
+func (c *ShutdownCoordinator) AddServer(servers ...Shutdowner) *ShutdownCoordinator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.servers = append(c.servers, servers...)
+	return c
+}
+
+// AddWSHub registers hubs whose clients are closed and rooms destroyed.
+func (c *ShutdownCoordinator) AddWSHub(hubs ...*WSHub) *ShutdownCoordinator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hubs = append(c.hubs, hubs...)
+	return c
+}
+
+// AddStreams registers stream drainers. They run in the order added.
+func (c *ShutdownCoordinator) AddStreams(streams ...StreamDrainer) *ShutdownCoordinator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streams = append(c.streams, streams...)
+	return c
+}
+
+// AddFlusher registers flushers. They run in the order added.
+func (c *ShutdownCoordinator) AddFlusher(flushers ...Flusher) *ShutdownCoordinator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushers = append(c.flushers, flushers...)
+	return c
+}
+
+// Shutdown runs every phase and reports their outcomes. A failed phase does
+// not stop the later ones. Phases share ctx; once it ends, the remaining
+// waits return at once. Later calls return the first report.
+func (c *ShutdownCoordinator) Shutdown(ctx context.Context) ShutdownReport {
+	c.once.Do(func() {
+		c.report = c.shutdown(ctx)
+	})
+	return c.report
+}
+
+func (c *ShutdownCoordinator) shutdown(ctx context.Context) ShutdownReport {
+	c.mu.Lock()
+	servers := append([]Shutdowner(nil), c.servers...)
+	hubs := append([]*WSHub(nil), c.hubs...)
+	streams := append([]StreamDrainer(nil), c.streams...)
+	flushers := append([]Flusher(nil), c.flushers...)
+	c.mu.Unlock()
+
+	var report ShutdownReport
+	run := func(phase ShutdownPhase, fn func() (int, error)) {
+		start := time.Now()
+		count, err := fn()
+		result := ShutdownPhaseResult{Phase: phase, Count: count, Duration: time.Since(start), Err: err}
+		report.Phases = append(report.Phases, result)
+		c.logPhase(result)
+	}
+
+	// The servers shut down in the background until the last phase, which
+	// cancels them once DrainTimeout passes.
+	serverCtx, cancelServers := context.WithCancel(ctx)
+	defer cancelServers()
+	serverErrs := make([]error, len(servers))
+	var stopped sync.WaitGroup
+	run(ShutdownStopAccepting, func() (int, error) {
+		for i, server := range servers {
+			stopped.Go(func() { serverErrs[i] = server.Shutdown(serverCtx) })
+		}
+		return len(servers), nil
+	})
+
+	run(ShutdownCloseWebSockets, func() (int, error) {
+		reason := fmt.Sprintf(`{"reconnect_after_ms":%d}`, c.config.ReconnectAfter.Milliseconds())
+		var (
+			closed int
+			errs   []error
+		)
+		for _, hub := range hubs {
+			n, err := hub.CloseWithStatus(CloseServiceRestart, reason)
+			closed += n
+			errs = append(errs, err, hub.destroyRooms())
+		}
+		return closed, errors.Join(errs...)
+	})
+
+	run(ShutdownDrainStreams, func() (int, error) {
+		var (
+			ended int
+			errs  []error
+		)
+		for _, stream := range streams {
+			n, err := stream.Drain(ctx)
+			ended += n
+			errs = append(errs, err)
+		}
+		return ended, errors.Join(errs...)
+	})
+
+	run(ShutdownFlushEvents, func() (int, error) {
+		var errs []error
+		for _, flusher := range flushers {
+			errs = append(errs, flusher.Flush(ctx))
+		}
+		return len(flushers), errors.Join(errs...)
+	})
+
+	run(ShutdownWaitInFlight, func() (int, error) {
+		done := make(chan struct{})
+		go func() {
+			stopped.Wait()
+			close(done)
+		}()
+		var deadline <-chan time.Time
+		if c.config.DrainTimeout > 0 {
+			timer := time.NewTimer(c.config.DrainTimeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		select {
+		case <-done:
+		case <-deadline:
+			cancelServers()
+			<-done
+		case <-ctx.Done():
+			<-done
+		}
+		return len(servers), errors.Join(serverErrs...)
+	})
+
+	return report
+}
+
+func (c *ShutdownCoordinator) logPhase(result ShutdownPhaseResult) {
+	if c.config.Logger == nil {
+		return
+	}
+	if result.Err != nil {
+		c.config.Logger.Warn("shutdown phase %s failed after %s: %v", result.Phase, result.Duration, result.Err)
+		return
+	}
+	c.config.Logger.Info("shutdown phase %s handled %d in %s", result.Phase, result.Count, result.Duration)
+}
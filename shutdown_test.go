@@ -0,0 +1,157 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/eventstream"
+)
+
+func TestShutdownCoordinator_DrainsInPhases(t *testing.T) {
+	app := router.NewHTTPServer()
+	hub := router.NewWSHub()
+	app.Router().Get("/ws", hub.Handler(), router.WebSocketUpgrade(router.DefaultWebSocketConfig()))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	app.Router().Get("/slow", func(c router.Context) error {
+		close(started)
+		<-release
+		return c.SendString("done")
+	})
+	server := httptest.NewServer(app.WrappedRouter())
+	defer server.Close()
+
+	ws, resp, err := websocket.DefaultDialer.Dial(strings.Replace(server.URL, "http://", "ws://", 1)+"/ws", nil)
+	defer closeWebSocketResponse(t, resp)
+	require.NoError(t, err)
+	defer closeWebSocketConn(t, ws)
+	require.Eventually(t, func() bool { return hub.ClientCount() == 1 }, time.Second, 5*time.Millisecond)
+
+	slow := make(chan string, 1)
+	go func() {
+		res, err := http.Get(server.URL + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		slow <- res.Status
+	}()
+	<-started
+
+	stream := eventstream.New()
+	sub, err := stream.Subscribe(context.Background(), eventstream.Scope{"user": "1"}, "")
+	require.NoError(t, err)
+
+	var processed atomic.Int32
+	batcher := router.NewEventBatcher(10, time.Hour, func(events []*router.EventMessage) {
+		processed.Add(int32(len(events)))
+	})
+	batcher.Add(&router.EventMessage{Type: "a"})
+	batcher.Add(&router.EventMessage{Type: "b"})
+
+	coordinator := router.NewShutdownCoordinator(router.ShutdownConfig{ReconnectAfter: 2 * time.Second}).
+		AddServer(server.Config).
+		AddWSHub(hub).
+		AddStreams(eventstream.AsDrainer(stream)).
+		AddFlusher(batcher, router.NewAckManager(time.Second))
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	report := coordinator.Shutdown(context.Background())
+	require.NoError(t, report.Err())
+
+	phases := make([]router.ShutdownPhase, 0, len(report.Phases))
+	counts := map[router.ShutdownPhase]int{}
+	for _, phase := range report.Phases {
+		phases = append(phases, phase.Phase)
+		counts[phase.Phase] = phase.Count
+	}
+	assert.Equal(t, []router.ShutdownPhase{
+		router.ShutdownStopAccepting,
+		router.ShutdownCloseWebSockets,
+		router.ShutdownDrainStreams,
+		router.ShutdownFlushEvents,
+		router.ShutdownWaitInFlight,
+	}, phases)
+	assert.Equal(t, 1, counts[router.ShutdownCloseWebSockets])
+	assert.Equal(t, 1, counts[router.ShutdownDrainStreams])
+	assert.Equal(t, "200 OK", <-slow, "in-flight requests finish")
+	assert.EqualValues(t, 2, processed.Load(), "pending batches are processed")
+
+	_, _, err = ws.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.True(t, errors.As(err, &closeErr))
+	assert.Equal(t, router.CloseServiceRestart, closeErr.Code)
+	assert.Equal(t, `{"reconnect_after_ms":2000}`, closeErr.Text)
+
+	_, open := <-sub.Records
+	assert.False(t, open, "subscriptions end")
+	assert.Equal(t, report, coordinator.Shutdown(context.Background()))
+}
+
+func TestShutdownCoordinator_DrainTimeout(t *testing.T) {
+	app := router.NewHTTPServer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app.Router().Get("/stuck", func(c router.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	server := httptest.NewServer(app.WrappedRouter())
+	defer server.Close()
+	defer close(release)
+	go func() {
+		if res, err := http.Get(server.URL + "/stuck"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	<-started
+
+	acks := router.NewAckManager(time.Hour)
+	report := router.NewShutdownCoordinator(router.ShutdownConfig{DrainTimeout: 20 * time.Millisecond}).
+		AddServer(server.Config).
+		AddFlusher(acks).
+		Shutdown(context.Background())
+
+	err := report.Err()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	last := report.Phases[len(report.Phases)-1]
+	assert.Equal(t, router.ShutdownWaitInFlight, last.Phase)
+	assert.Error(t, last.Err)
+	assert.Equal(t, 0, acks.PendingCount())
+}
+
+func TestShutdownCoordinator_DestroysRoomsThatCloseKeeps(t *testing.T) {
+	ctx := context.Background()
+	closed := router.NewWSHub()
+	kept, err := closed.CreateRoom(ctx, "lobby", "Lobby", router.RoomConfig{})
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+	assert.False(t, kept.IsDestroyed(), "Close leaves rooms to the caller")
+	_, err = closed.GetRoom("lobby")
+	require.NoError(t, err)
+
+	hub := router.NewWSHub()
+	room, err := hub.CreateRoom(ctx, "lobby", "Lobby", router.RoomConfig{})
+	require.NoError(t, err)
+	report := router.NewShutdownCoordinator().AddWSHub(hub).Shutdown(ctx)
+	require.NoError(t, report.Err())
+	assert.True(t, room.IsDestroyed())
+	_, err = hub.GetRoom("lobby")
+	assert.Error(t, err)
+}
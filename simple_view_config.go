@@ -3,6 +3,7 @@ package router
 import (
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 )
@@ -93,6 +94,15 @@ func (c *SimpleViewConfig) WithFunctions(funcs map[string]any) *SimpleViewConfig
 	return c
 }
 
+// WithURLBuilder registers the url and absolute_url template functions of
+// URLTemplateFunctions alongside the configured functions.
+func (c *SimpleViewConfig) WithURLBuilder(b URLBuilder) *SimpleViewConfig {
+	funcs := URLTemplateFunctions(b)
+	maps.Copy(funcs, c.Functions)
+	c.Functions = funcs
+	return c
+}
+
 // WithEmbedFS switches the config into embed mode with the provided templates.
 func (c *SimpleViewConfig) WithEmbedFS(dirFS string, templates ...fs.FS) *SimpleViewConfig {
 	c.Embed = true
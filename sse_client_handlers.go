@@ -30,11 +30,11 @@ const (
 )
 
 var (
-	sseClientETag       = generateETag(sseClientJS, "sse.js")
-	sseClientJSMapETag  = generateETag(sseClientJSMap, "sse.js.map")
-	sseClientMinETag    = generateETag(sseClientMinJS, "sse.min.js")
-	sseClientModuleETag = generateETag(sseClientModuleJS, "sse.mjs")
-	sseClientDTSETag    = generateETag(sseClientDTS, "sse.d.ts")
+	sseClientETag       = contentETag("ws-"+WebSocketClientVersion+"-sse.js-", sseClientJS, false)
+	sseClientJSMapETag  = contentETag("ws-"+WebSocketClientVersion+"-sse.js.map-", sseClientJSMap, false)
+	sseClientMinETag    = contentETag("ws-"+WebSocketClientVersion+"-sse.min.js-", sseClientMinJS, false)
+	sseClientModuleETag = contentETag("ws-"+WebSocketClientVersion+"-sse.mjs-", sseClientModuleJS, false)
+	sseClientDTSETag    = contentETag("ws-"+WebSocketClientVersion+"-sse.d.ts-", sseClientDTS, false)
 )
 
 type sseEmbeddedAsset struct {
@@ -56,7 +56,7 @@ func setSSEClientHeaders(c Context, contentType, etag string, maxAge time.Durati
 
 func serveSSEEmbeddedAsset(asset sseEmbeddedAsset) HandlerFunc {
 	return func(c Context) error {
-		if checkETag(c, asset.etag) {
+		if matchesIfNoneMatch(c, asset.etag) {
 			return c.Status(304).Send(nil)
 		}
 
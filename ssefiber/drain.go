@@ -0,0 +1,97 @@
+package ssefiber
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Drainer ends open SSE streams when the server shuts down. Each stream
+// writes a final retry directive before it closes, so clients reconnect,
+// ideally to another instance, after the configured retry interval.
+//
+//	drainer := ssefiber.NewDrainer()
+//	ssefiber.MountFiber(app.Router(), ssefiber.WithStream(stream), ssefiber.WithDrainer(drainer))
+//	coordinator.AddStreams(drainer)
+//
+// Streams started after Drain end at once.
+type Drainer struct {
+	mu       sync.Mutex
+	drain    chan struct{}
+	draining bool
+	active   int
+	ended    int
+	idle     chan struct{}
+}
+
+// NewDrainer returns a Drainer for WithDrainer.
+func NewDrainer() *Drainer {
+	return &Drainer{drain: make(chan struct{})}
+}
+
+// WithDrainer registers the streams of the handler with drainer.
+func WithDrainer(drainer *Drainer) Option {
+	return func(cfg *Config) {
+		if cfg != nil {
+			cfg.Drainer = drainer
+		}
+	}
+}
+
+// Drain signals every open stream to end and waits until they have, or ctx
+// ends. It returns the number of streams ended for the shutdown, including
+// those the server ended first.
+func (d *Drainer) Drain(ctx context.Context) (int, error) {
+	d.mu.Lock()
+	if !d.draining {
+		d.draining = true
+		close(d.drain)
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+		if d.active == 0 {
+			close(d.idle)
+		}
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.active > 0 {
+		return d.ended, fmt.Errorf("%d sse streams still open: %w", d.active, ctx.Err())
+	}
+	return d.ended, nil
+}
+
+// track registers a stream. The stream ends when the returned channel is
+// closed and calls release once it has.
+func (d *Drainer) track() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active++
+	return d.drain
+}
+
+// release unregisters a stream. shutdown reports that it ended for a
+// shutdown rather than a client disconnect or an ended subscription.
+func (d *Drainer) release(shutdown bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active--
+	if shutdown {
+		d.ended++
+	}
+	if d.active == 0 && d.idle != nil {
+		select {
+		case <-d.idle:
+		default:
+			close(d.idle)
+		}
+	}
+}
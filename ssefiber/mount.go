@@ -48,6 +48,7 @@ type Config struct {
 	MaxHeartbeatInterval time.Duration
 	MinRetryInterval     time.Duration
 	MaxRetryInterval     time.Duration
+	Drainer              *Drainer
 }
 
 type effectiveConfig struct {
@@ -119,8 +120,18 @@ func handlerWithConfig(cfg Config) router.HandlerFunc {
 		cursor := resolveCursor(ctx, cfg.CursorQueryParam)
 		effective := resolveEffectiveConfig(cfg, ctx)
 
+		// Track the stream before subscribing, so a concurrent Drain waits
+		// for it once the subscription is visible.
+		var drainC <-chan struct{}
+		if cfg.Drainer != nil {
+			drainC = cfg.Drainer.track()
+		}
+
 		sub, err := cfg.Stream.Subscribe(ctx.Context(), scope, cursor)
 		if err != nil {
+			if cfg.Drainer != nil {
+				cfg.Drainer.release(false)
+			}
 			return err
 		}
 
@@ -131,8 +142,20 @@ func handlerWithConfig(cfg Config) router.HandlerFunc {
 
 		reader, writer := io.Pipe()
 		go func() {
+			var shutdown bool
+			if cfg.Drainer != nil {
+				defer func() { cfg.Drainer.release(shutdown) }()
+			}
 			defer writer.Close()
 
+			// endStream asks the client to reconnect after the retry interval
+			// once the server ends the stream.
+			endStream := func() {
+				if effective.retry > 0 {
+					_ = writeRetryDirective(writer, effective.retry)
+				}
+			}
+
 			if effective.retry > 0 {
 				if err := writeRetryDirective(writer, effective.retry); err != nil {
 					_ = writer.CloseWithError(err)
@@ -167,6 +190,13 @@ func handlerWithConfig(cfg Config) router.HandlerFunc {
 			for {
 				select {
 				case <-ctx.Context().Done():
+					// Fiber ends request contexts when the server shuts down.
+					shutdown = true
+					endStream()
+					return
+				case <-drainC:
+					shutdown = true
+					endStream()
 					return
 				case now := <-heartbeatC:
 					if err := writeHeartbeatFrame(writer, heartbeatFrame{
@@ -178,6 +208,7 @@ func handlerWithConfig(cfg Config) router.HandlerFunc {
 					}
 				case record, ok := <-sub.Records:
 					if !ok {
+						endStream()
 						return
 					}
 					if err := writeRecordFrame(writer, record); err != nil {
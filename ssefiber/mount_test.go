@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -169,6 +170,48 @@ func TestHandlerEmitsFullStreamGapPayloadAndCloses(t *testing.T) {
 	assert.NotContains(t, body, "\nid:")
 }
 
+func TestHandlerDrainEndsStreamWithRetryDirective(t *testing.T) {
+	stream := eventstream.New()
+	scope := eventstream.Scope{"tenant": "t1"}
+	drainer := ssefiber.NewDrainer()
+
+	handler := ssefiber.Handler(
+		ssefiber.WithStream(stream),
+		ssefiber.WithScopeResolver(staticScopeResolver(scope)),
+		ssefiber.WithDrainer(drainer),
+	)
+
+	ctx := newStreamMockContext(t)
+	ctx.reqCtx = t.Context()
+
+	drained := make(chan int, 1)
+	var body string
+	ctx.sendStream = func(reader io.Reader) error {
+		go func() {
+			require.Eventually(t, func() bool {
+				return stream.SnapshotStats().ActiveSubscribers == 1
+			}, time.Second, 5*time.Millisecond)
+			ended, err := drainer.Drain(context.Background())
+			assert.NoError(t, err)
+			drained <- ended
+		}()
+
+		raw, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		body = string(raw)
+		return nil
+	}
+
+	err := handler(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, <-drained)
+	assert.Equal(t, 2, strings.Count(body, "retry: 3000"), "drained streams end with a retry directive")
+
+	ended, err := drainer.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, ended)
+}
+
 func TestMountFiberStreamsEventsAndCleansUpSubscriber(t *testing.T) {
 	adapter := router.NewFiberAdapter()
 	stream := eventstream.New()
@@ -0,0 +1,275 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// TLSCertificate names a PEM encoded certificate chain and its private key.
+type TLSCertificate struct {
+	CertFile string
+	KeyFile  string
+}
+
+// TLSConfig configures TLS serving. Certificate and CA files are read again
+// when they change on disk, so renewed certificates are served without a
+// restart. A reload that fails keeps the files loaded last.
+type TLSConfig struct {
+	// Certificates are served by SNI: a client gets the first certificate
+	// whose names match the server name it asked for, or the first one.
+	Certificates []TLSCertificate
+	// ReloadInterval is how often, at most, handshakes check the files for
+	// changes. Defaults to 10 seconds; a negative interval disables reloads.
+	ReloadInterval time.Duration
+	// ClientCAFile enables mutual TLS. Client certificates are verified
+	// against the PEM encoded CAs it holds.
+	ClientCAFile string
+	// ClientAuth defaults to tls.RequireAndVerifyClientCert when
+	// ClientCAFile is set. Use tls.VerifyClientCertIfGiven to make client
+	// certificates optional.
+	ClientAuth tls.ClientAuthType
+	// MinVersion defaults to TLS 1.2.
+	MinVersion uint16
+	// NextProtos lists the ALPN protocols, in preference order.
+	NextProtos []string
+	// Logger logs failed reloads when set.
+	Logger Logger
+}
+
+// TLSServer is implemented by servers that serve TLS on an address. The
+// Fiber, httprouter and ServeMux adapters implement it:
+//
+//	err := app.(router.TLSServer).ServeTLS(":8443", router.TLSConfig{
+//		Certificates: []router.TLSCertificate{{CertFile: "tls.crt", KeyFile: "tls.key"}},
+//		ClientCAFile: "clients-ca.pem",
+//	})
+type TLSServer interface {
+	ServeTLS(address string, config TLSConfig) error
+}
+
+// NewTLSConfig loads the files of config and returns a tls.Config that
+// serves them. Use it with servers the adapters do not create.
+func NewTLSConfig(config TLSConfig) (*tls.Config, error) {
+	if len(config.Certificates) == 0 {
+		return nil, errors.New("tls requires at least one certificate")
+	}
+	if config.ReloadInterval == 0 {
+		config.ReloadInterval = 10 * time.Second
+	}
+	if config.ClientCAFile != "" && config.ClientAuth == tls.NoClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	reloader := &tlsReloader{config: config}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: config.MinVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return reloader.current(), nil
+		},
+	}, nil
+}
+
+// NewTLSListener returns a listener that performs TLS handshakes with config
+// on the connections ln accepts. Use it with ServeListener to serve TLS on a
+// Unix socket or an inherited listener.
+func NewTLSListener(ln net.Listener, config TLSConfig) (net.Listener, error) {
+	tlsConfig, err := NewTLSConfig(config)
+	if err != nil {
+		return nil, errors.Join(err, ln.Close())
+	}
+	return &tlsListener{Listener: tls.NewListener(ln, tlsConfig)}, nil
+}
+
+// tlsListener marks a TLS listener for ListenInfo.
+type tlsListener struct {
+	net.Listener
+}
+
+// listenTLS binds address for ServeTLS.
+func listenTLS(network, address string, config TLSConfig) (net.Listener, error) {
+	if address == "" {
+		address = ":https"
+	}
+	ln, err := listenTCP(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewTLSListener(ln, config)
+}
+
+// tlsReloader holds the tls.Config built from the files last loaded.
+type tlsReloader struct {
+	config TLSConfig
+
+	mu       sync.Mutex
+	loaded   *tls.Config
+	modTimes map[string]time.Time
+	checked  time.Time
+}
+
+// current returns the loaded config, reloading the files first when the
+// reload interval passed and one of them changed.
+func (r *tlsReloader) current() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.config.ReloadInterval < 0 || time.Since(r.checked) < r.config.ReloadInterval {
+		return r.loaded
+	}
+	r.checked = time.Now()
+	if !r.changed() {
+		return r.loaded
+	}
+	if err := r.loadLocked(); err != nil && r.config.Logger != nil {
+		r.config.Logger.Warn("tls reload failed, serving previous certificates: %v", err)
+	}
+	return r.loaded
+}
+
+func (r *tlsReloader) files() []string {
+	files := make([]string, 0, 2*len(r.config.Certificates)+1)
+	for _, cert := range r.config.Certificates {
+		files = append(files, cert.CertFile, cert.KeyFile)
+	}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	return files
+}
+
+func (r *tlsReloader) changed() bool {
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *tlsReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checked = time.Now()
+	return r.loadLocked()
+}
+
+func (r *tlsReloader) loadLocked() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	certificates := make([]tls.Certificate, 0, len(r.config.Certificates))
+	for _, files := range r.config.Certificates {
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load certificate %s: %w", files.CertFile, err)
+		}
+		certificates = append(certificates, cert)
+	}
+
+	loaded := &tls.Config{
+		Certificates: certificates,
+		MinVersion:   r.config.MinVersion,
+		NextProtos:   slices.Clone(r.config.NextProtos),
+		ClientAuth:   r.config.ClientAuth,
+	}
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in client CA file %s", r.config.ClientCAFile)
+		}
+		loaded.ClientCAs = pool
+	}
+
+	r.loaded = loaded
+	r.modTimes = modTimes
+	return nil
+}
+
+// TLSContext is implemented by contexts of requests served over TLS. The
+// state is nil for plain connections.
+type TLSContext interface {
+	TLSConnectionState() *tls.ConnectionState
+}
+
+// ClientIdentity is the identity in a verified client certificate.
+type ClientIdentity struct {
+	Subject        pkix.Name
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+	IPAddresses    []net.IP
+	Certificate    *x509.Certificate
+}
+
+// ClientIdentityFromContext returns the identity of the client certificate
+// that mutual TLS verified for the request. It reports false for plain
+// connections and for certificates that were not verified:
+//
+//	func requireService(next router.HandlerFunc) router.HandlerFunc {
+//		return func(c router.Context) error {
+//			id, ok := router.ClientIdentityFromContext(c)
+//			if !ok || !slices.Contains(id.URIs, "spiffe://example.org/billing") {
+//				return c.SendStatus(http.StatusForbidden)
+//			}
+//			return next(c)
+//		}
+//	}
+func ClientIdentityFromContext(c Context) (ClientIdentity, bool) {
+	tc, ok := c.(TLSContext)
+	if !ok {
+		return ClientIdentity{}, false
+	}
+	state := tc.TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ClientIdentity{}, false
+	}
+	cert := state.VerifiedChains[0][0]
+	identity := ClientIdentity{
+		Subject:        cert.Subject,
+		DNSNames:       slices.Clone(cert.DNSNames),
+		EmailAddresses: slices.Clone(cert.EmailAddresses),
+		IPAddresses:    slices.Clone(cert.IPAddresses),
+		Certificate:    cert,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity, true
+}
+
+// serveTLS is ServeTLS for the adapters.
+func serveTLS(l *lifecycle, prepare func() error, s ListenerServer, network, address string, config TLSConfig) error {
+	if err := l.runStartup(context.Background(), prepare); err != nil {
+		return err
+	}
+	ln, err := listenTLS(network, address, config)
+	if err != nil {
+		return err
+	}
+	return s.ServeListener(ln)
+}
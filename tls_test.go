@@ -0,0 +1,241 @@
+package router_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+)
+
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	pool   *x509.CertPool
+	file   string
+	serial int64
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key, pool: x509.NewCertPool(), file: filepath.Join(dir, "ca.pem"), serial: 1}
+	ca.pool.AddCert(cert)
+	require.NoError(t, os.WriteFile(ca.file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return ca
+}
+
+// issue writes a certificate for template to dir/name.crt and dir/name.key.
+func (ca *testCA) issue(t *testing.T, dir, name string, template *x509.Certificate) router.TLSCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca.serial++
+	template.SerialNumber = big.NewInt(ca.serial)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	files := router.TLSCertificate{CertFile: filepath.Join(dir, name+".crt"), KeyFile: filepath.Join(dir, name+".key")}
+	require.NoError(t, os.WriteFile(files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return files
+}
+
+func serverCert(names ...string) *x509.Certificate {
+	return &x509.Certificate{
+		Subject:     pkix.Name{CommonName: names[0]},
+		DNSNames:    names,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	}
+}
+
+// handshake returns the serial number of the certificate served for
+// serverName.
+func handshake(t *testing.T, addr, serverName string, ca *testCA) int64 {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: serverName, RootCAs: ca.pool})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestNewTLSConfig_SelectsBySNIAndReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	api := ca.issue(t, dir, "api", serverCert("api.example.test"))
+	admin := ca.issue(t, dir, "admin", serverCert("admin.example.test"))
+
+	tlsConfig, err := router.NewTLSConfig(router.TLSConfig{
+		Certificates:   []router.TLSCertificate{api, admin},
+		ReloadInterval: time.Nanosecond,
+	})
+	require.NoError(t, err)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = conn.(*tls.Conn).Handshake()
+				_ = conn.Close()
+			}()
+		}
+	}()
+	addr := ln.Addr().String()
+
+	assert.EqualValues(t, 2, handshake(t, addr, "api.example.test", ca))
+	assert.EqualValues(t, 3, handshake(t, addr, "admin.example.test", ca))
+
+	renewed := ca.issue(t, dir, "api", serverCert("api.example.test"))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(renewed.CertFile, later, later))
+	require.NoError(t, os.Chtimes(renewed.KeyFile, later, later))
+	assert.EqualValues(t, 4, handshake(t, addr, "api.example.test", ca), "renewed certificate is served")
+
+	require.NoError(t, os.WriteFile(api.CertFile, []byte("broken"), 0o600))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(api.CertFile, later, later))
+	assert.EqualValues(t, 4, handshake(t, addr, "api.example.test", ca), "a failed reload keeps the last certificate")
+
+	_, err = router.NewTLSConfig(router.TLSConfig{})
+	assert.Error(t, err)
+}
+
+func TestServeTLS_MutualTLSExposesClientIdentity(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	server := ca.issue(t, dir, "server", serverCert("localhost"))
+	spiffe, err := url.Parse("spiffe://example.test/billing")
+	require.NoError(t, err)
+	client := ca.issue(t, dir, "client", &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "billing", Organization: []string{"payments"}},
+		DNSNames:    []string{"billing.internal"},
+		URIs:        []*url.URL{spiffe},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	})
+	clientCert, err := tls.LoadX509KeyPair(client.CertFile, client.KeyFile)
+	require.NoError(t, err)
+
+	whoami := func(c router.Context) error {
+		id, ok := router.ClientIdentityFromContext(c)
+		if !ok {
+			return c.SendStatus(http.StatusForbidden)
+		}
+		return c.SendString(id.Subject.CommonName + " " + strings.Join(id.Subject.Organization, ",") + " " +
+			strings.Join(id.DNSNames, ",") + " " + strings.Join(id.URIs, ","))
+	}
+	servers := []struct {
+		name   string
+		server func() lifecycleServer
+	}{
+		{"fiber", func() lifecycleServer {
+			app := router.NewFiberAdapter(func(*fiber.App) *fiber.App {
+				return fiber.New(fiber.Config{DisableStartupMessage: true})
+			})
+			app.Router().Get("/whoami", whoami)
+			return app.(lifecycleServer)
+		}},
+		{"httprouter", func() lifecycleServer {
+			app := router.NewHTTPServer()
+			app.Router().Get("/whoami", whoami)
+			return app.(lifecycleServer)
+		}},
+	}
+
+	for _, tc := range servers {
+		t.Run(tc.name, func(t *testing.T) {
+			app := tc.server()
+			listening := make(chan router.ListenInfo, 1)
+			app.OnListen(func(info router.ListenInfo) error {
+				listening <- info
+				return nil
+			})
+			go app.(router.TLSServer).ServeTLS("127.0.0.1:0", router.TLSConfig{
+				Certificates: []router.TLSCertificate{server},
+				ClientCAFile: ca.file,
+			})
+			info := <-listening
+			defer app.Shutdown(context.Background())
+			assert.True(t, info.TLS)
+			url := "https://localhost:" + portOf(t, info.Addr) + "/whoami"
+
+			anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool}}}
+			_, err := anonymous.Get(url)
+			assert.Error(t, err, "client certificates are required")
+
+			authenticated := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      ca.pool,
+				Certificates: []tls.Certificate{clientCert},
+			}}}
+			assert.Equal(t, "billing payments billing.internal spiffe://example.test/billing", getBody(t, authenticated, url))
+		})
+	}
+}
+
+func TestClientIdentityFromContext_PlainRequest(t *testing.T) {
+	app := router.NewHTTPServer()
+	app.Router().Get("/whoami", func(c router.Context) error {
+		_, ok := router.ClientIdentityFromContext(c)
+		assert.False(t, ok)
+		return c.SendStatus(http.StatusNoContent)
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.(router.ListenerServer).ServeListener(ln)
+	defer app.Shutdown(context.Background())
+
+	res, err := http.Get("http://" + ln.Addr().String() + "/whoami")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func portOf(t *testing.T, addr net.Addr) string {
+	t.Helper()
+	_, port, err := net.SplitHostPort(addr.String())
+	require.NoError(t, err)
+	return port
+}
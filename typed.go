@@ -0,0 +1,344 @@
+package router
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var typedParamTags = []string{TagPath, TagQuery, TagHeader, TagCookie}
+
+// NoContent is a Typed response type for handlers that reply without a body.
+// It is written as 204 unless WithTypedStatus says otherwise.
+type NoContent struct{}
+
+// TypedOption configures a handler created with Typed or TypedRoute.
+type TypedOption func(*typedSpec)
+
+// WithTypedStatus sets the status written for a successful response and
+// documented in the route's responses. Defaults to 200, or 204 for NoContent.
+func WithTypedStatus(code int) TypedOption {
+	return func(s *typedSpec) {
+		s.status = code
+	}
+}
+
+// WithTypedDescription sets the description of the documented success response.
+func WithTypedDescription(description string) TypedOption {
+	return func(s *typedSpec) {
+		s.description = description
+	}
+}
+
+// WithTypedMiddleware sets the route middlewares of a TypedRoute, as the
+// middlewares argument of Router.Handle does.
+func WithTypedMiddleware(middlewares ...MiddlewareFunc) TypedOption {
+	return func(s *typedSpec) {
+		s.middlewares = append(s.middlewares, middlewares...)
+	}
+}
+
+// typedSpec is the request and response shape of a Typed handler.
+type typedSpec struct {
+	reqType     reflect.Type
+	resType     reflect.Type
+	status      int
+	description string
+	noContent   bool
+	params      []typedParam
+	hasBody     bool
+	middlewares []MiddlewareFunc
+}
+
+// typedParam is a Req field read from the path, query, headers or cookies.
+type typedParam struct {
+	name     string
+	in       string
+	typ      reflect.Type
+	validate string
+}
+
+// Typed adapts fn into a HandlerFunc that decodes the request into Req and
+// writes Res as JSON.
+//
+// Req must be a struct (or pointer to one). Fields tagged `path`, `query`,
+// `header` or `cookie` are read from that source; the remaining exported
+// fields are bound from the request body as Context.Bind would. Malformed input
+// is returned as the binders' bad request error.
+//
+// Register typed handlers with TypedRoute to also describe the route from Req
+// and Res.
+//
+// Example:
+//
+//	type GetUser struct {
+//		ID     int    `path:"id"`
+//		Fields string `query:"fields"`
+//	}
+//
+//	app.Router().Get("/users/:id", router.Typed(func(ctx router.Context, req GetUser) (User, error) {
+//		return store.Find(ctx.Context(), req.ID)
+//	}))
+func Typed[Req, Res any](fn func(ctx Context, req Req) (Res, error), opts ...TypedOption) HandlerFunc {
+	_, handler := newTypedHandler(fn, opts...)
+	return handler
+}
+
+// TypedRoute registers fn on r as Typed does, and describes the route: Req
+// fills RouteDefinition.Parameters and RequestBody, and Res the success
+// response, using the same schemas as ExtractSchemaFromType. Metadata already
+// declared for the route, such as path constraint schemas, is kept.
+//
+// Example:
+//
+//	router.TypedRoute(app.Router(), router.GET, "/users/:id", func(ctx router.Context, req GetUser) (User, error) {
+//		return store.Find(ctx.Context(), req.ID)
+//	}).SetName("user:read")
+func TypedRoute[T, Req, Res any](r Router[T], method HTTPMethod, path string, fn func(ctx Context, req Req) (Res, error), opts ...TypedOption) RouteInfo {
+	spec, handler := newTypedHandler(fn, opts...)
+	ri := r.Handle(method, path, handler, spec.middlewares...)
+	if route, ok := ri.(*RouteDefinition); ok {
+		applyTypedRouteMetadata(route, spec)
+	}
+	return ri
+}
+
+func newTypedHandler[Req, Res any](fn func(ctx Context, req Req) (Res, error), opts ...TypedOption) (*typedSpec, HandlerFunc) {
+	spec := newTypedSpec(reflect.TypeFor[Req](), reflect.TypeFor[Res]())
+	for _, opt := range opts {
+		if opt != nil {
+			opt(spec)
+		}
+	}
+
+	return spec, func(ctx Context) error {
+		var req Req
+		if err := spec.decode(ctx, reflect.ValueOf(&req).Elem()); err != nil {
+			return err
+		}
+
+		res, err := fn(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if state, ok := AsResponseState(ctx); ok && state.ResponseWritten() {
+			return nil
+		}
+		if spec.noContent {
+			return ctx.NoContent(spec.status)
+		}
+		return ctx.JSON(spec.status, res)
+	}
+}
+
+func newTypedSpec(reqType, resType reflect.Type) *typedSpec {
+	spec := &typedSpec{
+		reqType:   reqType,
+		resType:   resType,
+		status:    http.StatusOK,
+		noContent: resType == reflect.TypeFor[NoContent](),
+	}
+	if spec.noContent {
+		spec.status = http.StatusNoContent
+	}
+
+	structType := reqType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("router.Typed: request type %s must be a struct", reqType))
+	}
+
+	for field := range structType.Fields() {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		if name, in, ok := typedParamSource(field); ok {
+			spec.params = append(spec.params, typedParam{name: name, in: in, typ: field.Type, validate: field.Tag.Get(TAG_VALIDATE)})
+			continue
+		}
+		if strings.Split(field.Tag.Get("json"), ",")[0] != "-" {
+			spec.hasBody = true
+		}
+	}
+	return spec
+}
+
+// typedParamSource returns the name and location of a parameter field.
+func typedParamSource(field reflect.StructField) (name, in string, ok bool) {
+	for _, tag := range typedParamTags {
+		value, found := field.Tag.Lookup(tag)
+		if !found {
+			continue
+		}
+		name = strings.Split(value, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if name == "-" {
+			return "", "", false
+		}
+		return name, tag, true
+	}
+	return "", "", false
+}
+
+func isTypedParamField(field reflect.StructField) bool {
+	_, _, ok := typedParamSource(field)
+	return ok
+}
+
+// decode populates target, a Req value, from ctx.
+func (s *typedSpec) decode(ctx Context, target reflect.Value) error {
+	if target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+	v := target.Addr().Interface()
+
+	if s.hasBody {
+		if err := bindBodyAs(ctx, v, true); err != nil {
+			return err
+		}
+	}
+
+	// Explicit sources are applied after the body so they always win.
+	for _, bind := range []func(Context, any) error{bindParams, bindQuery, bindHeaders, bindCookies} {
+		if err := bind(ctx, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTypedRouteMetadata describes route from spec, keeping any parameters,
+// request body or responses that are already declared.
+func applyTypedRouteMetadata(route *RouteDefinition, spec *typedSpec) {
+	for _, param := range spec.params {
+		if hasParameter(route.Parameters, param.name, param.in) {
+			continue
+		}
+		schema := typedSchema(param.typ, nil)
+		constraints, required := validationTagSchema(param.typ, param.validate)
+		maps.Copy(schema, constraints)
+		route.Parameters = append(route.Parameters, Parameter{
+			Name:     param.name,
+			In:       param.in,
+			Required: param.in == TagPath || required,
+			Schema:   schema,
+		})
+	}
+
+	if spec.hasBody && route.RequestBody == nil && methodAllowsBody(route.Method) {
+		route.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]any{
+				"application/json": map[string]any{"schema": typedSchema(spec.reqType, isTypedParamField)},
+			},
+		}
+	}
+
+	for _, res := range route.Responses {
+		if res.Code == spec.status {
+			return
+		}
+	}
+	response := Response{Code: spec.status, Description: spec.description}
+	if response.Description == "" {
+		response.Description = http.StatusText(spec.status)
+	}
+	if !spec.noContent {
+		response.Content = map[string]any{
+			"application/json": map[string]any{"schema": typedSchema(spec.resType, nil)},
+		}
+	}
+	route.Responses = append(route.Responses, response)
+}
+
+func hasParameter(params []Parameter, name, in string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+func methodAllowsBody(method HTTPMethod) bool {
+	switch method {
+	case GET, HEAD, DELETE:
+		return false
+	default:
+		return true
+	}
+}
+
+// typedSchema builds an OpenAPI schema for t. Nested structs are expanded
+// inline; exclude drops top level fields.
+func typedSchema(t reflect.Type, exclude func(reflect.StructField) bool) map[string]any {
+	builder := &typedSchemaBuilder{visiting: map[reflect.Type]bool{}}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return convertPropertyInfo(builder.property(t))
+	}
+	if _, special := handleSpecialType(t); special {
+		return convertPropertyInfo(builder.property(t))
+	}
+
+	metadata := builder.extract(t, exclude)
+	schema := map[string]any{"type": "object"}
+	if props := convertPropertiesWithRelations(metadata); len(props) > 0 {
+		schema["properties"] = props
+	}
+	if len(metadata.Required) > 0 {
+		schema["required"] = metadata.Required
+	}
+	return schema
+}
+
+// typedSchemaBuilder expands nested structs through ExtractSchemaFromType's
+// PropertyTypeMapper, stopping at recursive types.
+type typedSchemaBuilder struct {
+	visiting map[reflect.Type]bool
+}
+
+func (b *typedSchemaBuilder) extract(t reflect.Type, exclude func(reflect.StructField) bool) SchemaMetadata {
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	opts := ExtractSchemaFromTypeOptions{PropertyTypeMapper: b.property}
+	if exclude != nil {
+		opts.CustomFieldFilter = func(field reflect.StructField) bool { return !exclude(field) }
+	}
+	return ExtractSchemaFromType(t, opts)
+}
+
+func (b *typedSchemaBuilder) property(t reflect.Type) PropertyInfo {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if _, special := handleSpecialType(t); special {
+		return extractPropertyInfoWithPath(t, nil, false)
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if b.visiting[t] {
+			return PropertyInfo{Type: "object"}
+		}
+		return PropertyInfo{Type: "object", Properties: b.extract(t, nil).Properties}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return PropertyInfo{Type: "string", Format: "byte"}
+		}
+		items := b.property(t.Elem())
+		return PropertyInfo{Type: "array", Items: &items}
+	default:
+		return extractPropertyInfoWithPath(t, nil, false)
+	}
+}
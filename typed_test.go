@@ -0,0 +1,172 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+type typedAddress struct {
+	City string `json:"city"`
+}
+
+type updateUserRequest struct {
+	ID      int       `path:"id"`
+	Notify  bool      `query:"notify"`
+	Tags    []string  `query:"tag"`
+	Since   time.Time `query:"since"`
+	TraceID string    `header:"X-Trace-ID"`
+	Session string    `cookie:"session"`
+
+	Name    string        `json:"name" bun:"name,notnull"`
+	Address *typedAddress `json:"address,omitempty"`
+}
+
+type userResponse struct {
+	ID      int          `json:"id"`
+	Name    string       `json:"name"`
+	Notify  bool         `json:"notify"`
+	Tags    []string     `json:"tags"`
+	Trace   string       `json:"trace"`
+	Session string       `json:"session"`
+	City    string       `json:"city"`
+	Address typedAddress `json:"address"`
+}
+
+func updateUser(_ router.Context, req updateUserRequest) (userResponse, error) {
+	res := userResponse{
+		ID: req.ID, Name: req.Name, Notify: req.Notify, Tags: req.Tags,
+		Trace: req.TraceID, Session: req.Session,
+	}
+	if req.Address != nil {
+		res.City = req.Address.City
+	}
+	return res, nil
+}
+
+func typedUserRoutes[T any](r router.Router[T]) {
+	r.Put("/api/users/:id", router.Typed(updateUser))
+}
+
+func TestTyped_BindsRequestAcrossAdapters(t *testing.T) {
+	setup := routertest.Setup{Fiber: typedUserRoutes, HTTPRouter: typedUserRoutes, ServeMux: typedUserRoutes}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		client.Put("/api/users/7?notify=true&tag=a&tag=b").
+			Header("X-Trace-ID", "trace-1").
+			Cookie("session", "s-1").
+			JSON(map[string]any{"name": "Ada", "address": map[string]string{"city": "Paris"}}).
+			Do(t).
+			AssertStatus(http.StatusOK).
+			AssertJSONPath("id", 7).
+			AssertJSONPath("name", "Ada").
+			AssertJSONPath("notify", true).
+			AssertJSONPath("tags", []string{"a", "b"}).
+			AssertJSONPath("trace", "trace-1").
+			AssertJSONPath("session", "s-1").
+			AssertJSONPath("city", "Paris")
+
+		client.Put("/api/users/7?notify=maybe").Do(t).AssertStatus(http.StatusBadRequest)
+	})
+}
+
+func TestTyped_StatusAndNoContent(t *testing.T) {
+	app := router.NewHTTPServer()
+	r := app.Router()
+	r.Post("/api/items", router.Typed(func(_ router.Context, req struct {
+		Name string `json:"name"`
+	}) (map[string]string, error) {
+		return map[string]string{"name": req.Name}, nil
+	}, router.WithTypedStatus(http.StatusCreated)))
+	r.Delete("/api/items/:id", router.Typed(func(_ router.Context, req struct {
+		ID string `path:"id"`
+	}) (router.NoContent, error) {
+		if req.ID == "missing" {
+			return router.NoContent{}, router.NewNotFoundError("item not found")
+		}
+		return router.NoContent{}, nil
+	}))
+
+	client := routertest.NewClient(app)
+	client.Post("/api/items").JSON(map[string]string{"name": "x"}).Do(t).
+		AssertStatus(http.StatusCreated).
+		AssertJSONPath("name", "x")
+	client.Delete("/api/items/1").Do(t).AssertStatus(http.StatusNoContent).AssertBody("")
+	client.Delete("/api/items/missing").Do(t).AssertStatus(http.StatusNotFound)
+}
+
+func TestTyped_HandlerMayWriteItsOwnResponse(t *testing.T) {
+	legacy := router.Typed(func(ctx router.Context, _ struct{}) (router.NoContent, error) {
+		return router.NoContent{}, ctx.Redirect("/new", http.StatusFound)
+	})
+
+	fiberApp := router.NewFiberAdapter()
+	fiberApp.Router().Get("/legacy", legacy)
+	httpApp := router.NewHTTPServer()
+	httpApp.Router().Get("/legacy", legacy)
+
+	routertest.NewClient(fiberApp).Get("/legacy").Do(t).AssertRedirect("/new")
+	routertest.NewClient(httpApp).Get("/legacy").Do(t).AssertRedirect("/new")
+}
+
+func TestTyped_DescribesRoute(t *testing.T) {
+	assertRoute := func(t *testing.T, routes []router.RouteDefinition) {
+		t.Helper()
+		require.Len(t, routes, 1)
+		route := routes[0]
+
+		params := map[string]router.Parameter{}
+		for _, p := range route.Parameters {
+			params[p.In+":"+p.Name] = p
+		}
+		require.Len(t, params, 6)
+		assert.True(t, params["path:id"].Required)
+		assert.Equal(t, "integer", params["path:id"].Schema["type"])
+		assert.Equal(t, "array", params["query:tag"].Schema["type"])
+		assert.Equal(t, "date-time", params["query:since"].Schema["format"])
+		assert.False(t, params["header:X-Trace-ID"].Required)
+		assert.Contains(t, params, "cookie:session")
+
+		require.NotNil(t, route.RequestBody)
+		body := route.RequestBody.Content["application/json"].(map[string]any)["schema"].(map[string]any)
+		props := body["properties"].(map[string]any)
+		assert.Len(t, props, 2)
+		assert.Equal(t, []string{"name"}, body["required"])
+		address := props["address"].(map[string]any)
+		assert.Equal(t, "string", address["properties"].(map[string]any)["city"].(map[string]any)["type"])
+
+		require.Len(t, route.Responses, 1)
+		assert.Equal(t, http.StatusOK, route.Responses[0].Code)
+		res := route.Responses[0].Content["application/json"].(map[string]any)["schema"].(map[string]any)
+		assert.Contains(t, res["properties"], "tags")
+	}
+
+	fiberApp := router.NewFiberAdapter()
+	router.TypedRoute(fiberApp.Router(), router.PUT, "/users/:id", updateUser)
+	assertRoute(t, fiberApp.Router().Routes())
+
+	muxApp := router.NewServeMuxServer()
+	router.TypedRoute(muxApp.Router(), router.PUT, "/users/:id", updateUser)
+	assertRoute(t, muxApp.Router().Routes())
+}
+
+func TestTyped_DeclaredMetadataWins(t *testing.T) {
+	app := router.NewHTTPServer()
+	router.TypedRoute(app.Router(), router.GET, "/orders/:id<uint>", func(_ router.Context, req struct {
+		ID uint `path:"id"`
+	}) (router.NoContent, error) {
+		return router.NoContent{}, nil
+	}).AddResponse(http.StatusOK, "ignored", nil)
+
+	route := app.Router().Routes()[0]
+	require.Len(t, route.Parameters, 1)
+	assert.Equal(t, 0, route.Parameters[0].Schema["minimum"], "constraint-derived schema is kept")
+	assert.Nil(t, route.RequestBody, "GET routes do not document a body")
+	require.Len(t, route.Responses, 2)
+	assert.Equal(t, http.StatusNoContent, route.Responses[0].Code)
+}
@@ -0,0 +1,363 @@
+package router
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	goerrors "github.com/goliatone/go-errors"
+)
+
+// URLBuilder is implemented by routers that build URLs for named routes.
+//
+// Params fill the ":name" and "*name" segments of the route path and the
+// "{name}" labels of its host. Values are formatted with fmt.Sprint, checked
+// against the parameter constraints and escaped. Params the route does not
+// declare are added to the query string, after query:
+//
+//	u, err := r.URLFor("users.show", map[string]any{"id": 7, "tab": "posts"}, nil)
+//	// "/users/7?tab=posts"
+type URLBuilder interface {
+	// URLFor returns the path and query of the named route.
+	URLFor(name string, params map[string]any, query url.Values) (string, error)
+	// AbsoluteURLFor returns the absolute URL of the named route, resolved
+	// against the base URL set with SetBaseURL.
+	AbsoluteURLFor(name string, params map[string]any, query url.Values) (string, error)
+	// RequestURLFor returns the absolute URL of the named route for the
+	// scheme and host of the request c. Forwarded headers are ignored unless
+	// SetTrustForwardedHeaders enabled them.
+	RequestURLFor(c Context, name string, params map[string]any, query url.Values) (string, error)
+	// SetBaseURL sets the scheme, host and path prefix of absolute URLs, such
+	// as "https://example.com" or "https://example.com/app".
+	SetBaseURL(base string) error
+	// SetTrustForwardedHeaders makes RequestURLFor take the scheme and host
+	// from X-Forwarded-Proto, X-Forwarded-Ssl and X-Forwarded-Host. Enable it
+	// only behind a proxy that sets or strips these headers.
+	SetTrustForwardedHeaders(trust bool)
+}
+
+// URLFor returns the URL of the named route of r. See URLBuilder.
+func URLFor[T any](r Router[T], name string, params map[string]any, query url.Values) (string, error) {
+	b, ok := r.(URLBuilder)
+	if !ok {
+		return "", newURLError(name, fmt.Errorf("%T does not build route URLs", r))
+	}
+	return b.URLFor(name, params, query)
+}
+
+// URLBuilderFunc is a URLBuilder that defers to the builder f returns. It lets
+// views be configured before the router they link to exists:
+//
+//	var app router.Server[*fiber.App]
+//	urls := router.URLBuilderFunc(func() router.URLBuilder {
+//		return app.Router().(router.URLBuilder)
+//	})
+//	cfg := router.NewSimpleViewConfig("./views").WithURLBuilder(urls)
+type URLBuilderFunc func() URLBuilder
+
+func (f URLBuilderFunc) URLFor(name string, params map[string]any, query url.Values) (string, error) {
+	return f().URLFor(name, params, query)
+}
+
+func (f URLBuilderFunc) AbsoluteURLFor(name string, params map[string]any, query url.Values) (string, error) {
+	return f().AbsoluteURLFor(name, params, query)
+}
+
+func (f URLBuilderFunc) RequestURLFor(c Context, name string, params map[string]any, query url.Values) (string, error) {
+	return f().RequestURLFor(c, name, params, query)
+}
+
+func (f URLBuilderFunc) SetBaseURL(base string) error {
+	return f().SetBaseURL(base)
+}
+
+func (f URLBuilderFunc) SetTrustForwardedHeaders(trust bool) {
+	f().SetTrustForwardedHeaders(trust)
+}
+
+func (br *BaseRouter) URLFor(name string, params map[string]any, query url.Values) (string, error) {
+	route, err := br.urlRoute(name)
+	if err != nil {
+		return "", err
+	}
+	built, err := buildRouteURL(route, params, query)
+	if err != nil {
+		return "", err
+	}
+	return built.pathAndQuery(), nil
+}
+
+func (br *BaseRouter) AbsoluteURLFor(name string, params map[string]any, query url.Values) (string, error) {
+	route, err := br.urlRoute(name)
+	if err != nil {
+		return "", err
+	}
+	br.root.registrationMu.Lock()
+	base := br.root.baseURL
+	br.root.registrationMu.Unlock()
+	if base == nil {
+		return "", newURLError(name, fmt.Errorf("no base URL is set"))
+	}
+
+	built, err := buildRouteURL(route, params, query)
+	if err != nil {
+		return "", err
+	}
+	host := base.Host
+	if route.host != nil && !route.host.anyHost {
+		if host, err = route.host.build(built.hostParams); err != nil {
+			return "", newURLError(name, err)
+		}
+		if port := base.Port(); port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+	}
+	return base.Scheme + "://" + host + strings.TrimRight(base.EscapedPath(), "/") + built.pathAndQuery(), nil
+}
+
+// RequestURLFor returns the absolute URL of the named route for the scheme and
+// host of c. A route on another host gets that host, and no port. Behind a
+// proxy, set a base URL and use AbsoluteURLFor, or trust the forwarded
+// headers with SetTrustForwardedHeaders.
+func (br *BaseRouter) RequestURLFor(c Context, name string, params map[string]any, query url.Values) (string, error) {
+	route, err := br.urlRoute(name)
+	if err != nil {
+		return "", err
+	}
+	built, err := buildRouteURL(route, params, query)
+	if err != nil {
+		return "", err
+	}
+	br.root.registrationMu.Lock()
+	trust := br.root.trustForwardedHeaders
+	br.root.registrationMu.Unlock()
+
+	host := requestHostForOriginCheck(c, trust)
+	if route.host != nil && !route.host.anyHost {
+		// Keep the request host when it already serves the route.
+		current, ok := route.host.match(requestHostname(host))
+		if !ok || !maps.Equal(current, built.hostParams) {
+			if host, err = route.host.build(built.hostParams); err != nil {
+				return "", newURLError(name, err)
+			}
+		}
+	}
+	return requestSchemeForOriginCheck(c, trust) + "://" + host + built.pathAndQuery(), nil
+}
+
+func (br *BaseRouter) SetBaseURL(base string) error {
+	parsed, err := url.Parse(strings.TrimSpace(base))
+	if err == nil && (parsed.Scheme == "" || parsed.Host == "") {
+		err = fmt.Errorf("base URL %q needs a scheme and host", base)
+	}
+	if err != nil {
+		return goerrors.Wrap(err, goerrors.CategoryBadInput, "invalid base URL").
+			WithTextCode("ROUTE_BASE_URL")
+	}
+	parsed.RawQuery, parsed.Fragment = "", ""
+
+	br.root.registrationMu.Lock()
+	defer br.root.registrationMu.Unlock()
+	br.root.baseURL = parsed
+	return nil
+}
+
+func (br *BaseRouter) SetTrustForwardedHeaders(trust bool) {
+	br.root.registrationMu.Lock()
+	defer br.root.registrationMu.Unlock()
+	br.root.trustForwardedHeaders = trust
+}
+
+// urlRoute returns the route publicly named name.
+func (br *BaseRouter) urlRoute(name string) (*RouteDefinition, error) {
+	br.root.registrationMu.Lock()
+	defer br.root.registrationMu.Unlock()
+	if binding := br.root.namedRoutes[name]; binding != nil && binding.Mode == routeNameModePublic {
+		return binding.Route, nil
+	}
+	return nil, goerrors.New(fmt.Sprintf("route %q not found", name), goerrors.CategoryNotFound).
+		WithCode(http.StatusNotFound).
+		WithTextCode("ROUTE_NOT_FOUND").
+		WithMetadata(map[string]any{"route": name})
+}
+
+func newURLError(name string, err error) error {
+	return goerrors.Wrap(err, goerrors.CategoryBadInput, fmt.Sprintf("cannot build URL for route %q: %v", name, err)).
+		WithTextCode("ROUTE_URL").
+		WithMetadata(map[string]any{"route": name})
+}
+
+// routeURL is a route path with its parameters filled in.
+type routeURL struct {
+	path       string
+	query      url.Values
+	hostParams map[string]string
+}
+
+func (u routeURL) pathAndQuery() string {
+	if encoded := u.query.Encode(); encoded != "" {
+		return u.path + "?" + encoded
+	}
+	return u.path
+}
+
+// buildRouteURL fills the path of route with params. Params that are not path
+// or host parameters are added to query.
+func buildRouteURL(route *RouteDefinition, params map[string]any, query url.Values) (routeURL, error) {
+	values := make(map[string]string, len(params))
+	for key, value := range params {
+		if value != nil {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	used := map[string]struct{}{}
+
+	segments := strings.Split(route.Path, "/")
+	out := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name, _, _ := parseParamSegment(segment)
+			optional := strings.HasSuffix(name, "?")
+			name = strings.TrimSuffix(name, "?")
+			value, ok := values[name]
+			used[name] = struct{}{}
+			switch {
+			case ok && value != "":
+				out = append(out, url.PathEscape(value))
+			case optional:
+			default:
+				return routeURL{}, newURLError(route.Name, fmt.Errorf("missing path parameter %q", name))
+			}
+		case strings.HasPrefix(segment, "*"):
+			name := cmp.Or(segment[1:], "*")
+			used[name] = struct{}{}
+			parts := strings.Split(strings.TrimPrefix(values[name], "/"), "/")
+			for i, part := range parts {
+				parts[i] = url.PathEscape(part)
+			}
+			out = append(out, strings.Join(parts, "/"))
+		default:
+			out = append(out, segment)
+		}
+	}
+
+	for _, pc := range route.paramConstraints {
+		value, ok := values[pc.name]
+		if !ok {
+			continue
+		}
+		for _, constraint := range pc.constraints {
+			if !constraint.Match(value) {
+				return routeURL{}, newURLError(route.Name, fmt.Errorf("path parameter %q value %q does not satisfy its constraints", pc.name, value))
+			}
+		}
+	}
+
+	built := routeURL{path: strings.Join(out, "/"), query: url.Values{}}
+	if built.path == "" {
+		built.path = "/"
+	}
+	maps.Copy(built.query, query)
+	if route.host != nil {
+		for _, label := range route.host.labels {
+			if name, ok := strings.CutPrefix(label, "{"); ok {
+				name = strings.TrimSuffix(name, "}")
+				used[name] = struct{}{}
+				if value, ok := values[name]; ok {
+					if built.hostParams == nil {
+						built.hostParams = map[string]string{}
+					}
+					built.hostParams[name] = value
+				}
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if _, ok := used[key]; !ok {
+			built.query.Add(key, values[key])
+		}
+	}
+	return built, nil
+}
+
+// build returns the host name of the pattern for params. Wildcard labels
+// cannot be filled and fail.
+func (p *hostPattern) build(params map[string]string) (string, error) {
+	labels := slices.Clone(p.labels)
+	for i, label := range labels {
+		switch {
+		case label == "*":
+			return "", fmt.Errorf("host pattern %q has a wildcard label", p.pattern)
+		case label[0] == '{':
+			name := label[1 : len(label)-1]
+			value := strings.ToLower(params[name])
+			if value == "" || strings.ContainsAny(value, "./:") {
+				return "", fmt.Errorf("host parameter %q needs a single label value", name)
+			}
+			labels[i] = value
+		}
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// URLTemplateFunctions returns the template functions that build route URLs
+// with b:
+//
+//	{{ url("users.show", "id", user.ID) }}
+//	{{ absolute_url("users.show", "id", user.ID, "tab", "posts") }}
+//
+// Arguments after the route name are key and value pairs, or a single map.
+// Keys that are not route parameters become query parameters.
+func URLTemplateFunctions(b URLBuilder) map[string]any {
+	return map[string]any{
+		"url": func(name string, args ...any) (string, error) {
+			params, err := templateURLParams(args)
+			if err != nil {
+				return "", newURLError(name, err)
+			}
+			return b.URLFor(name, params, nil)
+		},
+		"absolute_url": func(name string, args ...any) (string, error) {
+			params, err := templateURLParams(args)
+			if err != nil {
+				return "", newURLError(name, err)
+			}
+			return b.AbsoluteURLFor(name, params, nil)
+		},
+	}
+}
+
+func templateURLParams(args []any) (map[string]any, error) {
+	if len(args) == 1 {
+		switch m := args[0].(type) {
+		case map[string]any:
+			return m, nil
+		case ViewContext:
+			return m, nil
+		case map[string]string:
+			params := make(map[string]any, len(m))
+			for key, value := range m {
+				params[key] = value
+			}
+			return params, nil
+		}
+	}
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("url parameters must be key and value pairs")
+	}
+	params := make(map[string]any, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("url parameter name %v is not a string", args[i])
+		}
+		params[key] = args[i+1]
+	}
+	return params, nil
+}
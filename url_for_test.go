@@ -0,0 +1,142 @@
+package router_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+func registerURLRoutes[T any](r router.Router[T]) {
+	r.Get("/users/:id<int;min(1)>", func(c router.Context) error { return nil }).SetName("users.show")
+	r.Get("/posts/:slug", func(c router.Context) error { return nil }).SetName("posts.show")
+	r.Get("/files/*path", func(c router.Context) error { return nil }).SetName("files")
+	r.Get("/", func(c router.Context) error { return nil }).SetName("home")
+	router.Host(r, "{tenant}.example.com").Get("/dashboard", func(c router.Context) error { return nil }).SetName("tenant.dashboard")
+	router.Version(r.Group("/api"), "v2", router.VersionByPath()).Get("/users/:id", func(c router.Context) error { return nil }).SetName("api.users.show")
+}
+
+func TestURLFor_BuildsNamedRoutes(t *testing.T) {
+	r := router.NewHTTPServer().Router()
+	registerURLRoutes(r)
+
+	u, err := router.URLFor(r, "users.show", map[string]any{"id": 7, "tab": "posts"}, url.Values{"page": {"2"}})
+	require.NoError(t, err)
+	assert.Equal(t, "/users/7?page=2&tab=posts", u)
+
+	u, err = router.URLFor(r, "posts.show", map[string]any{"slug": "hello world/again"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/posts/hello%20world%2Fagain", u)
+
+	u, err = router.URLFor(r, "files", map[string]any{"path": "/docs/a b.pdf"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/files/docs/a%20b.pdf", u)
+
+	u, err = router.URLFor(r, "home", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/", u)
+
+	u, err = router.URLFor(r, "api.users.show", map[string]any{"id": "me"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2/users/me", u)
+
+	u, err = router.URLFor(r, "tenant.dashboard", map[string]any{"tenant": "acme"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/dashboard", u, "host parameters do not leak into the query")
+
+	_, err = router.URLFor(r, "users.show", map[string]any{"id": 0}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ROUTE_URL")
+	_, err = router.URLFor(r, "users.show", nil, nil)
+	assert.Error(t, err)
+	_, err = router.URLFor(r, "missing", nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ROUTE_NOT_FOUND")
+}
+
+func TestURLFor_AbsoluteURLs(t *testing.T) {
+	app := router.NewHTTPServer()
+	r := app.Router()
+	registerURLRoutes(r)
+	b := r.(router.URLBuilder)
+
+	_, err := b.AbsoluteURLFor("home", nil, nil)
+	assert.Error(t, err, "absolute URLs need a base URL")
+	assert.Error(t, b.SetBaseURL("/relative"))
+	require.NoError(t, b.SetBaseURL("https://example.com:8443/app/"))
+
+	u, err := b.AbsoluteURLFor("users.show", map[string]any{"id": 3}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com:8443/app/users/3", u)
+
+	u, err = b.AbsoluteURLFor("tenant.dashboard", map[string]any{"tenant": "Acme"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.example.com:8443/app/dashboard", u)
+	_, err = b.AbsoluteURLFor("tenant.dashboard", nil, nil)
+	assert.Error(t, err)
+
+	r.Get("/links", func(c router.Context) error {
+		self, err := b.RequestURLFor(c, "users.show", map[string]any{"id": 5}, nil)
+		if err != nil {
+			return err
+		}
+		tenant, err := b.RequestURLFor(c, "tenant.dashboard", map[string]any{"tenant": "beta"}, nil)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, map[string]string{"self": self, "tenant": tenant})
+	})
+	routertest.NewClient(app).Get("http://localhost:3000/links").Do(t).
+		AssertStatus(http.StatusOK).
+		AssertJSONPath("self", "http://localhost:3000/users/5").
+		AssertJSONPath("tenant", "http://beta.example.com/dashboard")
+
+	proxied := func() *routertest.RequestBuilder {
+		return routertest.NewClient(app).Get("http://localhost:3000/links").
+			Header("X-Forwarded-Proto", "https").
+			Header("X-Forwarded-Host", "beta.example.com")
+	}
+	proxied().Do(t).
+		AssertJSONPath("self", "http://localhost:3000/users/5")
+	b.SetTrustForwardedHeaders(true)
+	proxied().Do(t).
+		AssertJSONPath("self", "https://beta.example.com/users/5").
+		AssertJSONPath("tenant", "https://beta.example.com/dashboard")
+}
+
+func TestURLFor_TemplateFunctionsAndNamespaces(t *testing.T) {
+	var r router.Router[*http.ServeMux]
+	cfg := router.NewSimpleViewConfig("./views").
+		WithFunctions(map[string]any{"upper": func(s string) string { return s }}).
+		WithURLBuilder(router.URLBuilderFunc(func() router.URLBuilder { return r.(router.URLBuilder) }))
+	funcs := cfg.GetTemplateFunctions()
+	assert.Contains(t, funcs, "upper")
+
+	r = router.NewServeMuxServer().Router()
+	registerURLRoutes(r)
+
+	urlFunc := funcs["url"].(func(string, ...any) (string, error))
+	u, err := urlFunc("users.show", "id", 9, "sort", "name")
+	require.NoError(t, err)
+	assert.Equal(t, "/users/9?sort=name", u)
+	u, err = urlFunc("posts.show", map[string]any{"slug": "intro"})
+	require.NoError(t, err)
+	assert.Equal(t, "/posts/intro", u)
+	_, err = urlFunc("users.show", "id")
+	assert.Error(t, err)
+
+	resolver := router.NewNamespaceResolver(
+		map[string]string{"admin": "/admin"},
+		map[string]map[string]string{"admin": {"user.read": "/users/:id<int>"}},
+	)
+	u, err = resolver.URL("admin", "user.read", map[string]any{"id": 4}, url.Values{"x": {"1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "/admin/users/4?x=1", u)
+	_, err = resolver.URL("admin", "user.read", map[string]any{"id": "abc"}, nil)
+	assert.Error(t, err)
+}
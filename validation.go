@@ -0,0 +1,709 @@
+package router
+
+import (
+	"fmt"
+	"maps"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	goerrors "github.com/goliatone/go-errors"
+)
+
+// ValidationRule is a compiled `validate` tag rule, such as the "min=3" in
+// `validate:"required,min=3"`.
+type ValidationRule struct {
+	// Validate checks a present field value, with pointers already
+	// dereferenced. The error message is reported for the field.
+	Validate func(value reflect.Value) error
+	// Schema is merged into the field's OpenAPI schema.
+	Schema map[string]any
+}
+
+// ValidationRuleFactory compiles a rule for a field of type t (pointers
+// removed) from the text after "=", e.g. "3" for "min=3". arg is empty for
+// bare rules.
+type ValidationRuleFactory func(t reflect.Type, arg string) (ValidationRule, error)
+
+var validationRules = struct {
+	sync.RWMutex
+	factories map[string]ValidationRuleFactory
+}{
+	factories: map[string]ValidationRuleFactory{
+		"min":      sizeRule("min"),
+		"max":      sizeRule("max"),
+		"len":      sizeRule("len"),
+		"gt":       numberRule("gt"),
+		"gte":      numberRule("gte"),
+		"lt":       numberRule("lt"),
+		"lte":      numberRule("lte"),
+		"oneof":    oneOfRule,
+		"email":    emailRule,
+		"url":      urlRule,
+		"uuid":     patternRule(uuidPattern, "must be a valid UUID", map[string]any{"format": "uuid"}),
+		"alpha":    patternRule(`^[A-Za-z]+$`, "must contain only letters", nil),
+		"alphanum": patternRule(`^[A-Za-z0-9]+$`, "must contain only letters and digits", nil),
+		"numeric":  patternRule(`^[-+]?[0-9]+(?:\.[0-9]+)?$`, "must be numeric", nil),
+		"pattern":  regexRule,
+	},
+}
+
+// Rules handled by the validator itself rather than a factory.
+const (
+	validateRequired  = "required"
+	validateOmitEmpty = "omitempty"
+)
+
+// RegisterValidationRule adds or replaces a named `validate` tag rule.
+//
+// Example:
+//
+//	router.RegisterValidationRule("sku", func(t reflect.Type, _ string) (router.ValidationRule, error) {
+//		if t.Kind() != reflect.String {
+//			return router.ValidationRule{}, fmt.Errorf("sku applies to strings")
+//		}
+//		return router.ValidationRule{
+//			Validate: func(v reflect.Value) error {
+//				if !strings.HasPrefix(v.String(), "SKU-") {
+//					return errors.New("must be a SKU")
+//				}
+//				return nil
+//			},
+//			Schema: map[string]any{"pattern": "^SKU-"},
+//		}, nil
+//	})
+func RegisterValidationRule(name string, factory ValidationRuleFactory) {
+	if name == "" || factory == nil {
+		return
+	}
+	validationRules.Lock()
+	validationRules.factories[name] = factory
+	validationRules.Unlock()
+	structValidators.Clear()
+}
+
+func lookupValidationRule(name string) (ValidationRuleFactory, bool) {
+	validationRules.RLock()
+	defer validationRules.RUnlock()
+	factory, ok := validationRules.factories[name]
+	return factory, ok
+}
+
+// Validate checks v, a struct or pointer to one, against its `validate` tags.
+// Nested structs, and slices and maps of them, are checked too. Failures are
+// returned as a validation error with one FieldError per field, keyed by the
+// field's JSON name or, for `path`, `query`, `header` and `cookie` fields, the
+// parameter name. A malformed tag is returned as a plain error.
+//
+// Context binders call Validate for the fields they bind unless
+// BindConfig.DisableValidation is set.
+func Validate(v any) error {
+	return validateSource(v, "", true)
+}
+
+// validateSource checks the fields bound from source: one of the parameter
+// tags, or "" for the body. all checks every field.
+func validateSource(v any, source string, all bool) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	sv := structValidatorFor(rv.Type())
+	if sv.err != nil {
+		return sv.err
+	}
+
+	var errs []goerrors.FieldError
+	sv.validate(rv, "", func(fieldSource string) bool { return all || fieldSource == source }, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	if all {
+		return NewValidationError("validation failed", errs)
+	}
+	if source == "" {
+		source = bindSourceBody
+	}
+	return NewValidationError("validation failed", errs, map[string]any{"source": source})
+}
+
+// structValidators caches compiled validators by struct type.
+var structValidators sync.Map
+
+type structValidator struct {
+	fields []fieldValidator
+	err    error
+}
+
+type fieldValidator struct {
+	index     []int
+	name      string
+	source    string
+	required  bool
+	omitEmpty bool
+	rules     []ValidationRule
+	// nested validates struct values reached through the field, including
+	// slice and map elements.
+	nested *structValidator
+}
+
+func structValidatorFor(t reflect.Type) *structValidator {
+	if cached, ok := structValidators.Load(t); ok {
+		return cached.(*structValidator)
+	}
+	sv := compileStructValidator(t, map[reflect.Type]*structValidator{})
+	actual, _ := structValidators.LoadOrStore(t, sv)
+	return actual.(*structValidator)
+}
+
+func compileStructValidator(t reflect.Type, visiting map[reflect.Type]*structValidator) *structValidator {
+	if sv, ok := visiting[t]; ok {
+		return sv
+	}
+	sv := &structValidator{}
+	visiting[t] = sv
+
+	for _, field := range validatedFields(t) {
+		fv := fieldValidator{index: field.Index, name: validationFieldName(field)}
+		if _, in, ok := typedParamSource(field); ok {
+			fv.source = in
+		}
+
+		base := field.Type
+		for base.Kind() == reflect.Pointer {
+			base = base.Elem()
+		}
+		if tag := field.Tag.Get(TAG_VALIDATE); tag != "" && tag != "-" {
+			if err := fv.compileRules(base, tag); err != nil {
+				sv.err = fmt.Errorf("validate: %s.%s: %w", t.Name(), field.Name, err)
+				return sv
+			}
+		}
+
+		if elem := validatedStructType(base); elem != nil {
+			nested := compileStructValidator(elem, visiting)
+			if nested.err != nil {
+				sv.err = nested.err
+				return sv
+			}
+			fv.nested = nested
+		}
+
+		if fv.required || fv.omitEmpty || len(fv.rules) > 0 || fv.nested != nil {
+			sv.fields = append(sv.fields, fv)
+		}
+	}
+	return sv
+}
+
+// validatedFields lists the exported fields of t, flattening untagged
+// embedded structs.
+func validatedFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for field := range t.Fields() {
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(TAG_JSON) == "" {
+			for _, inner := range validatedFields(field.Type) {
+				inner.Index = append([]int{field.Index[0]}, inner.Index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// validationFieldName matches the property names of ExtractSchemaFromType,
+// or the parameter name for fields bound from the request line or headers.
+func validationFieldName(field reflect.StructField) string {
+	if name, _, ok := typedParamSource(field); ok {
+		return name
+	}
+	name := getFieldNameFromTags(field, []string{TAG_JSON, TAG_BUN, TAG_CRUD})
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// validatedStructType returns the struct type reached through t, directly or
+// as a slice, array or map element, when it should be validated recursively.
+func validatedStructType(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+	if _, special := handleSpecialType(t); special {
+		return nil
+	}
+	return t
+}
+
+func (fv *fieldValidator) compileRules(t reflect.Type, tag string) error {
+	for _, spec := range splitValidationTag(tag) {
+		name, arg, _ := strings.Cut(spec, "=")
+		switch name {
+		case validateRequired:
+			fv.required = true
+			continue
+		case validateOmitEmpty:
+			fv.omitEmpty = true
+			continue
+		}
+		factory, ok := lookupValidationRule(name)
+		if !ok {
+			return fmt.Errorf("unknown rule %q", name)
+		}
+		rule, err := factory(t, arg)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", spec, err)
+		}
+		if rule.Validate == nil {
+			return fmt.Errorf("rule %q has no Validate function", spec)
+		}
+		fv.rules = append(fv.rules, rule)
+	}
+	return nil
+}
+
+// splitValidationTag splits a tag on commas. Patterns may contain a literal
+// comma written as `\,`; other backslashes are kept as written.
+func splitValidationTag(tag string) []string {
+	var specs []string
+	var current strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			i++
+			current.WriteByte(tag[i])
+		case tag[i] == ',':
+			if spec := strings.TrimSpace(current.String()); spec != "" {
+				specs = append(specs, spec)
+			}
+			current.Reset()
+		default:
+			current.WriteByte(tag[i])
+		}
+	}
+	if spec := strings.TrimSpace(current.String()); spec != "" {
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func (sv *structValidator) validate(rv reflect.Value, prefix string, include func(source string) bool, errs *[]goerrors.FieldError) {
+	for _, fv := range sv.fields {
+		if !include(fv.source) {
+			continue
+		}
+		value, ok := fieldByIndex(rv, fv.index)
+		if !ok {
+			continue
+		}
+		path := fv.name
+		if prefix != "" {
+			path = prefix + "." + fv.name
+		}
+		fv.validate(value, path, errs)
+	}
+}
+
+func (fv *fieldValidator) validate(value reflect.Value, path string, errs *[]goerrors.FieldError) {
+	if value.IsZero() {
+		if fv.required {
+			*errs = append(*errs, goerrors.FieldError{Field: path, Message: "is required"})
+			return
+		}
+		if fv.omitEmpty || value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			return
+		}
+	}
+
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	for _, rule := range fv.rules {
+		if err := rule.Validate(value); err != nil {
+			*errs = append(*errs, goerrors.FieldError{Field: path, Message: err.Error(), Value: fieldErrorValue(value)})
+			return
+		}
+	}
+
+	if fv.nested == nil {
+		return
+	}
+	include := func(string) bool { return true }
+	switch value.Kind() {
+	case reflect.Struct:
+		fv.nested.validate(value, path, include, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if elem, ok := derefStruct(value.Index(i)); ok {
+				fv.nested.validate(elem, fmt.Sprintf("%s[%d]", path, i), include, errs)
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if elem, ok := derefStruct(iter.Value()); ok {
+				fv.nested.validate(elem, fmt.Sprintf("%s.%v", path, iter.Key()), include, errs)
+			}
+		}
+	}
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex without panicking on nil
+// embedded pointers.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+func derefStruct(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, v.Kind() == reflect.Struct
+}
+
+// fieldErrorValue echoes scalar values back in field errors.
+func fieldErrorValue(v reflect.Value) any {
+	if isScalarKind(v.Kind()) {
+		return v.Interface()
+	}
+	return nil
+}
+
+// validationTagSchema returns the OpenAPI constraints described by a
+// `validate` tag and whether it marks the field required. Rules that do not
+// compile are skipped; Validate reports them.
+func validationTagSchema(t reflect.Type, tag string) (map[string]any, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var schema map[string]any
+	required := false
+	for _, spec := range splitValidationTag(tag) {
+		name, arg, _ := strings.Cut(spec, "=")
+		if name == validateRequired {
+			required = true
+			continue
+		}
+		factory, ok := lookupValidationRule(name)
+		if !ok {
+			continue
+		}
+		rule, err := factory(t, arg)
+		if err != nil || len(rule.Schema) == 0 {
+			continue
+		}
+		if schema == nil {
+			schema = map[string]any{}
+		}
+		maps.Copy(schema, rule.Schema)
+	}
+	return schema, required
+}
+
+type sizeKind int
+
+const (
+	sizeNumber sizeKind = iota
+	sizeString
+	sizeItems
+	sizeProperties
+)
+
+func sizeKindOf(t reflect.Type) (sizeKind, bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return sizeNumber, true
+	case reflect.String:
+		return sizeString, true
+	case reflect.Slice, reflect.Array:
+		return sizeItems, true
+	case reflect.Map:
+		return sizeProperties, true
+	default:
+		return 0, false
+	}
+}
+
+func numberOf(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func sizeOf(v reflect.Value, kind sizeKind) int {
+	if kind == sizeString {
+		return utf8.RuneCountInString(v.String())
+	}
+	return v.Len()
+}
+
+// schemaNumber keeps integer bounds integral in the generated schema.
+func schemaNumber(n float64) any {
+	if n == float64(int64(n)) {
+		return int64(n)
+	}
+	return n
+}
+
+// sizeRule compares numbers by value, strings by rune count, and slices and
+// maps by length.
+func sizeRule(op string) ValidationRuleFactory {
+	return func(t reflect.Type, arg string) (ValidationRule, error) {
+		kind, ok := sizeKindOf(t)
+		if !ok {
+			return ValidationRule{}, fmt.Errorf("does not apply to %s", t)
+		}
+		bound, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return ValidationRule{}, fmt.Errorf("invalid number %q", arg)
+		}
+		if kind == sizeNumber {
+			if op == "len" {
+				return ValidationRule{}, fmt.Errorf("does not apply to %s", t)
+			}
+			return numberRule(map[string]string{"min": "gte", "max": "lte"}[op])(t, arg)
+		}
+
+		size := int(bound)
+		keys := map[sizeKind][2]string{
+			sizeString:     {"minLength", "maxLength"},
+			sizeItems:      {"minItems", "maxItems"},
+			sizeProperties: {"minProperties", "maxProperties"},
+		}[kind]
+		unit := map[sizeKind]string{sizeString: "characters", sizeItems: "items", sizeProperties: "entries"}[kind]
+
+		schema := map[string]any{}
+		var message string
+		var check func(n int) bool
+		switch op {
+		case "min":
+			schema[keys[0]] = size
+			message = fmt.Sprintf("must contain at least %d %s", size, unit)
+			check = func(n int) bool { return n >= size }
+		case "max":
+			schema[keys[1]] = size
+			message = fmt.Sprintf("must contain at most %d %s", size, unit)
+			check = func(n int) bool { return n <= size }
+		default:
+			schema[keys[0]], schema[keys[1]] = size, size
+			message = fmt.Sprintf("must contain exactly %d %s", size, unit)
+			check = func(n int) bool { return n == size }
+		}
+		return ValidationRule{
+			Validate: func(v reflect.Value) error {
+				if !check(sizeOf(v, kind)) {
+					return fmt.Errorf("%s", message)
+				}
+				return nil
+			},
+			Schema: schema,
+		}, nil
+	}
+}
+
+func numberRule(op string) ValidationRuleFactory {
+	return func(t reflect.Type, arg string) (ValidationRule, error) {
+		if kind, ok := sizeKindOf(t); !ok || kind != sizeNumber {
+			return ValidationRule{}, fmt.Errorf("does not apply to %s", t)
+		}
+		bound, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return ValidationRule{}, fmt.Errorf("invalid number %q", arg)
+		}
+		value := schemaNumber(bound)
+
+		var schema map[string]any
+		var message string
+		var check func(n float64) bool
+		switch op {
+		case "gt":
+			schema = map[string]any{"minimum": value, "exclusiveMinimum": true}
+			message, check = fmt.Sprintf("must be greater than %v", value), func(n float64) bool { return n > bound }
+		case "gte":
+			schema = map[string]any{"minimum": value}
+			message, check = fmt.Sprintf("must be at least %v", value), func(n float64) bool { return n >= bound }
+		case "lt":
+			schema = map[string]any{"maximum": value, "exclusiveMaximum": true}
+			message, check = fmt.Sprintf("must be less than %v", value), func(n float64) bool { return n < bound }
+		default:
+			schema = map[string]any{"maximum": value}
+			message, check = fmt.Sprintf("must be at most %v", value), func(n float64) bool { return n <= bound }
+		}
+		return ValidationRule{
+			Validate: func(v reflect.Value) error {
+				if !check(numberOf(v)) {
+					return fmt.Errorf("%s", message)
+				}
+				return nil
+			},
+			Schema: schema,
+		}, nil
+	}
+}
+
+// oneOfRule takes space separated values, as in `validate:"oneof=draft published"`.
+func oneOfRule(t reflect.Type, arg string) (ValidationRule, error) {
+	options := strings.Fields(arg)
+	if len(options) == 0 {
+		return ValidationRule{}, fmt.Errorf("requires at least one value")
+	}
+
+	enum := make([]any, len(options))
+	switch kind, ok := sizeKindOf(t); {
+	case ok && kind == sizeString:
+		for i, option := range options {
+			enum[i] = option
+		}
+	case ok && kind == sizeNumber:
+		for i, option := range options {
+			n, err := strconv.ParseFloat(option, 64)
+			if err != nil {
+				return ValidationRule{}, fmt.Errorf("invalid number %q", option)
+			}
+			enum[i] = schemaNumber(n)
+		}
+	default:
+		return ValidationRule{}, fmt.Errorf("does not apply to %s", t)
+	}
+
+	message := "must be one of: " + strings.Join(options, ", ")
+	return ValidationRule{
+		Validate: func(v reflect.Value) error {
+			var got any = v.String()
+			if v.Kind() != reflect.String {
+				got = schemaNumber(numberOf(v))
+			}
+			for _, option := range enum {
+				if option == got {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s", message)
+		},
+		Schema: map[string]any{"enum": enum},
+	}, nil
+}
+
+func stringRule(t reflect.Type, arg string) error {
+	if t.Kind() != reflect.String {
+		return fmt.Errorf("does not apply to %s", t)
+	}
+	if arg != "" {
+		return fmt.Errorf("takes no arguments")
+	}
+	return nil
+}
+
+func emailRule(t reflect.Type, arg string) (ValidationRule, error) {
+	if err := stringRule(t, arg); err != nil {
+		return ValidationRule{}, err
+	}
+	return ValidationRule{
+		Validate: func(v reflect.Value) error {
+			addr, err := mail.ParseAddress(v.String())
+			if err != nil || addr.Address != v.String() {
+				return fmt.Errorf("must be a valid email address")
+			}
+			return nil
+		},
+		Schema: map[string]any{"format": "email"},
+	}, nil
+}
+
+func urlRule(t reflect.Type, arg string) (ValidationRule, error) {
+	if err := stringRule(t, arg); err != nil {
+		return ValidationRule{}, err
+	}
+	return ValidationRule{
+		Validate: func(v reflect.Value) error {
+			u, err := url.Parse(v.String())
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("must be a valid URL")
+			}
+			return nil
+		},
+		Schema: map[string]any{"format": "uri"},
+	}, nil
+}
+
+func patternRule(pattern, message string, schema map[string]any) ValidationRuleFactory {
+	re := regexp.MustCompile(pattern)
+	return func(t reflect.Type, arg string) (ValidationRule, error) {
+		if err := stringRule(t, arg); err != nil {
+			return ValidationRule{}, err
+		}
+		s := map[string]any{"pattern": pattern}
+		maps.Copy(s, schema)
+		return ValidationRule{
+			Validate: func(v reflect.Value) error {
+				if !re.MatchString(v.String()) {
+					return fmt.Errorf("%s", message)
+				}
+				return nil
+			},
+			Schema: s,
+		}, nil
+	}
+}
+
+// regexRule anchors its expression like the regex path constraint.
+func regexRule(t reflect.Type, arg string) (ValidationRule, error) {
+	if t.Kind() != reflect.String {
+		return ValidationRule{}, fmt.Errorf("does not apply to %s", t)
+	}
+	if arg == "" {
+		return ValidationRule{}, fmt.Errorf("pattern requires an expression")
+	}
+	pattern := "^(?:" + arg + ")$"
+	if _, err := regexp.Compile(pattern); err != nil {
+		return ValidationRule{}, err
+	}
+	return patternRule(pattern, "must match "+arg, nil)(t, "")
+}
@@ -0,0 +1,180 @@
+package router_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	goerrors "github.com/goliatone/go-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+type validatedItem struct {
+	SKU      string `json:"sku" validate:"required,pattern=[A-Z]{3}-\\d+"`
+	Quantity int    `json:"quantity" validate:"gte=1,lte=99"`
+}
+
+type validatedOrder struct {
+	Email    string            `json:"email,omitempty" validate:"required,email"`
+	Name     string            `json:"name" validate:"min=2,max=5"`
+	Status   string            `json:"status" validate:"oneof=draft placed"`
+	Website  *string           `json:"website" validate:"url"`
+	Notes    string            `json:"notes" validate:"omitempty,min=10"`
+	Items    []validatedItem   `json:"items" validate:"min=1"`
+	Labels   map[string]string `json:"labels" validate:"max=2"`
+	Priority int               `query:"priority" validate:"oneof=1 2 3"`
+}
+
+func fieldErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var routerErr *goerrors.Error
+	require.True(t, errors.As(err, &routerErr), "expected a go-errors error, got %v", err)
+	assert.Equal(t, "VALIDATION_ERROR", routerErr.TextCode)
+	out := map[string]string{}
+	for _, fe := range routerErr.ValidationErrors {
+		out[fe.Field] = fe.Message
+	}
+	return out
+}
+
+func TestValidate_ReportsFieldsByJSONName(t *testing.T) {
+	bad := "not a url"
+	err := router.Validate(&validatedOrder{
+		Name:     "x",
+		Status:   "shipped",
+		Website:  &bad,
+		Notes:    "short",
+		Items:    []validatedItem{{SKU: "ABC-12", Quantity: 1}, {SKU: "abc", Quantity: 100}},
+		Labels:   map[string]string{"a": "1", "b": "2", "c": "3"},
+		Priority: 7,
+	})
+
+	assert.Equal(t, map[string]string{
+		"email":             "is required",
+		"name":              "must contain at least 2 characters",
+		"status":            "must be one of: draft, placed",
+		"website":           "must be a valid URL",
+		"notes":             "must contain at least 10 characters",
+		"items[1].sku":      "must match [A-Z]{3}-\\d+",
+		"items[1].quantity": "must be at most 99",
+		"labels":            "must contain at most 2 entries",
+		"priority":          "must be one of: 1, 2, 3",
+	}, fieldErrors(t, err))
+
+	assert.NoError(t, router.Validate(validatedOrder{
+		Email: "ada@example.com", Name: "Ada", Status: "draft",
+		Items: []validatedItem{{SKU: "ABC-1", Quantity: 2}}, Priority: 1,
+	}))
+	assert.NotContains(t, fieldErrors(t, router.Validate(&validatedOrder{})), "notes", "omitempty skips empty values")
+}
+
+func TestValidate_RunsAfterBinding(t *testing.T) {
+	setup := routertest.Setup{Fiber: validationRoutes, HTTPRouter: validationRoutes, ServeMux: validationRoutes}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		client.Post("/api/users?limit=10").JSON(map[string]string{"email": "ada@example.com"}).Do(t).
+			AssertStatus(http.StatusOK)
+
+		res := client.Post("/api/users?limit=500").JSON(map[string]string{"email": "ada@example.com"}).Do(t).
+			AssertStatus(http.StatusBadRequest)
+		assert.Contains(t, res.String(), "VALIDATION_ERROR")
+		assert.Contains(t, res.String(), `"limit"`)
+
+		res = client.Post("/api/users").JSON(map[string]string{"email": "nope"}).Do(t).
+			AssertStatus(http.StatusBadRequest)
+		assert.Contains(t, res.String(), `"field":"email"`)
+
+		client.Post("/api/lenient/users?limit=500").JSON(map[string]string{}).Do(t).
+			AssertStatus(http.StatusOK)
+		client.Post("/api/v2/users").JSON(map[string]string{}).Do(t).
+			AssertStatus(http.StatusUnprocessableEntity)
+		client.Post("/api/v2/users").Body(strings.NewReader(`{"email":`), "application/json").Do(t).
+			AssertStatus(http.StatusBadRequest)
+	})
+}
+
+type validatedUser struct {
+	Limit int    `query:"limit" validate:"max=100"`
+	Email string `json:"email" validate:"required,email"`
+}
+
+func validationRoutes[T any](r router.Router[T]) {
+	handler := func(c router.Context) error {
+		var req validatedUser
+		if err := c.BindQuery(&req); err != nil {
+			return err
+		}
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, req)
+	}
+	r.Post("/api/users", handler)
+	r.Post("/api/lenient/users", handler, router.BindConfigMiddleware(router.BindConfig{DisableValidation: true}))
+	r.Post("/api/v2/users", handler, router.BindConfigMiddleware(router.BindConfig{ValidationStatus: http.StatusUnprocessableEntity}))
+}
+
+func TestValidate_TypedRoutesDocumentRules(t *testing.T) {
+	app := router.NewHTTPServer()
+	router.TypedRoute(app.Router(), router.POST, "/api/orders", func(_ router.Context, req validatedOrder) (router.NoContent, error) {
+		return router.NoContent{}, nil
+	})
+
+	route := app.Router().Routes()[0]
+	require.Len(t, route.Parameters, 1)
+	assert.Equal(t, []any{int64(1), int64(2), int64(3)}, route.Parameters[0].Schema["enum"])
+
+	schema := route.RequestBody.Content["application/json"].(map[string]any)["schema"].(map[string]any)
+	assert.Contains(t, schema["required"], "email", "validate required outranks omitempty")
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, "email", props["email"].(map[string]any)["format"])
+	assert.Equal(t, 2, props["name"].(map[string]any)["minLength"])
+	assert.Equal(t, []any{"draft", "placed"}, props["status"].(map[string]any)["enum"])
+	assert.Equal(t, "uri", props["website"].(map[string]any)["format"])
+	assert.Equal(t, 1, props["items"].(map[string]any)["minItems"])
+	item := props["items"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, "^(?:[A-Z]{3}-\\d+)$", item["sku"].(map[string]any)["pattern"])
+
+	client := routertest.NewClient(app)
+	client.Post("/api/orders?priority=2").
+		JSON(map[string]any{"email": "ada@example.com", "name": "Ada", "status": "draft", "items": []any{map[string]any{"sku": "ABC-1", "quantity": 1}}}).
+		Do(t).AssertStatus(http.StatusNoContent)
+	client.Post("/api/orders?priority=2").JSON(map[string]any{"name": "Ada"}).Do(t).
+		AssertStatus(http.StatusBadRequest)
+}
+
+func TestRegisterValidationRule(t *testing.T) {
+	router.RegisterValidationRule("even", func(t reflect.Type, arg string) (router.ValidationRule, error) {
+		if t.Kind() != reflect.Int {
+			return router.ValidationRule{}, fmt.Errorf("applies to int")
+		}
+		return router.ValidationRule{
+			Validate: func(v reflect.Value) error {
+				if v.Int()%2 != 0 {
+					return errors.New("must be even")
+				}
+				return nil
+			},
+			Schema: map[string]any{"multipleOf": 2},
+		}, nil
+	})
+
+	type pair struct {
+		Count int `json:"count" validate:"even"`
+	}
+	assert.NoError(t, router.Validate(pair{Count: 2}))
+	assert.Equal(t, map[string]string{"count": "must be even"}, fieldErrors(t, router.Validate(pair{Count: 3})))
+
+	type broken struct {
+		Name string `validate:"nope"`
+	}
+	err := router.Validate(broken{})
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), `unknown rule "nope"`), err.Error())
+}
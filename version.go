@@ -0,0 +1,518 @@
+package router
+
+import (
+	"cmp"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	goerrors "github.com/goliatone/go-errors"
+)
+
+// VersionSource names where a request states the API version it wants.
+type VersionSource string
+
+const (
+	// VersionFromPath versions routes by a path segment such as "/v2/users".
+	VersionFromPath VersionSource = "path"
+	// VersionFromHeader reads the version from a request header.
+	VersionFromHeader VersionSource = "header"
+	// VersionFromAccept reads the version from a vendor media type in the
+	// Accept header, such as "application/vnd.app.v2+json".
+	VersionFromAccept VersionSource = "accept"
+)
+
+// DefaultVersionHeader is the header VersionByHeader reads when no name is
+// given.
+const DefaultVersionHeader = "API-Version"
+
+// VersionStrategy tells a Version group how requests select its routes.
+type VersionStrategy struct {
+	Source VersionSource
+	// Header is the header read by VersionFromHeader.
+	Header string
+	// Vendor is the media type vendor matched by VersionFromAccept: "app"
+	// matches "application/vnd.app.v2+json" and
+	// "application/vnd.app+json; version=v2".
+	Vendor string
+	// Default is the version served to header and Accept requests that do
+	// not ask for one. The latest declared version is used when empty.
+	Default string
+}
+
+// VersionByPath versions routes by a path segment after the group prefix.
+func VersionByPath() VersionStrategy {
+	return VersionStrategy{Source: VersionFromPath}
+}
+
+// VersionByHeader versions routes by the named request header, or
+// DefaultVersionHeader when name is empty.
+func VersionByHeader(name string) VersionStrategy {
+	return VersionStrategy{Source: VersionFromHeader, Header: name}
+}
+
+// VersionByAccept versions routes by the vendor media type of the Accept
+// header.
+func VersionByAccept(vendor string) VersionStrategy {
+	return VersionStrategy{Source: VersionFromAccept, Vendor: vendor}
+}
+
+func (s VersionStrategy) normalize() (VersionStrategy, error) {
+	s.Default = strings.TrimSpace(s.Default)
+	switch s.Source {
+	case "", VersionFromPath:
+		return VersionStrategy{Source: VersionFromPath}, nil
+	case VersionFromHeader:
+		s.Header = http.CanonicalHeaderKey(cmp.Or(strings.TrimSpace(s.Header), DefaultVersionHeader))
+		s.Vendor = ""
+	case VersionFromAccept:
+		s.Vendor = strings.ToLower(strings.TrimSpace(s.Vendor))
+		if s.Vendor == "" {
+			return s, fmt.Errorf("accept versioning needs a media type vendor")
+		}
+		s.Header = ""
+	default:
+		return s, fmt.Errorf("unknown version source %q", s.Source)
+	}
+	return s, nil
+}
+
+// VersionRouter is implemented by routers that can group routes by API
+// version.
+//
+// Version returns a group whose routes belong to the version name. With
+// VersionByPath the name becomes a path segment; with the header and Accept
+// strategies routes keep their path and requests choose between versions:
+//
+//	v1 := router.Version(app.Router(), "v1", router.VersionByPath())
+//	v2 := router.Version(app.Router(), "v2", router.VersionByPath())
+//	v1.Get("/users", listUsersV1) // GET /v1/users
+//	v2.Get("/users", listUsersV2) // GET /v2/users
+//	v1.Get("/teams", listTeams)   // GET /v1/teams and GET /v2/teams
+//
+// A version serves the routes it does not declare from the nearest older
+// version. Header and Accept requests for a version that was never declared
+// get the nearest older one, or the oldest when they ask for something
+// earlier still.
+type VersionRouter[T any] interface {
+	Version(name string, strategy VersionStrategy) Router[T]
+}
+
+// Version returns the version group of r for name. It panics when r does not
+// implement VersionRouter.
+func Version[T any](r Router[T], name string, strategy VersionStrategy) Router[T] {
+	vr, ok := r.(VersionRouter[T])
+	if !ok {
+		panic(fmt.Sprintf("router: %T does not support version groups", r))
+	}
+	return vr.Version(name, strategy)
+}
+
+// Deprecation describes the retirement of a route or API version. Deprecated
+// routes answer with the Deprecation (RFC 9745), Sunset (RFC 8594) and Link
+// headers and are marked deprecated in OpenAPI documents.
+type Deprecation struct {
+	// At is when the route was deprecated. The zero value still marks the
+	// route deprecated in documents but sends no Deprecation header.
+	At time.Time `json:"at,omitzero"`
+	// Sunset is when the route is expected to stop answering.
+	Sunset time.Time `json:"sunset,omitzero"`
+	// Link points to documentation about the deprecation.
+	Link string `json:"link,omitempty"`
+}
+
+// Deprecate marks a single route deprecated.
+func Deprecate(ri RouteInfo, d Deprecation) RouteInfo {
+	if route, ok := ri.(*RouteDefinition); ok {
+		route.Deprecation = &d
+	}
+	return ri
+}
+
+// DeprecateVersion marks every route of the API version name deprecated, in
+// all strategies it was declared with and including the routes it inherits
+// from older versions. Deprecations set with
+// Deprecate take precedence.
+func DeprecateVersion[T any](r Router[T], name string, d Deprecation) error {
+	vr, ok := r.(interface {
+		deprecateVersion(name string, d Deprecation) error
+	})
+	if !ok {
+		return goerrors.New(fmt.Sprintf("%T does not support version groups", r), goerrors.CategoryBadInput).
+			WithTextCode("API_VERSION_UNSUPPORTED")
+	}
+	return vr.deprecateVersion(name, d)
+}
+
+// apiVersion is a version declared through a Version group. Routes of the
+// version point to it, so deprecating the version reaches all of them.
+type apiVersion struct {
+	name        string
+	strategy    VersionStrategy
+	deprecation *Deprecation
+	root        *routerRoot
+}
+
+// String returns the version name, or "" for unversioned routes.
+func (v *apiVersion) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.name
+}
+
+// declareVersion returns the version name of the router in strategy,
+// declaring it on first use.
+func (br *BaseRouter) declareVersion(name string, strategy VersionStrategy) *apiVersion {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	strategy, err := strategy.normalize()
+	if err == nil && (name == "" || strings.Contains(name, "/")) {
+		err = fmt.Errorf("version name %q must be a single path segment", name)
+	}
+	if err != nil {
+		panic(newVersionError(name, err))
+	}
+
+	br.root.beginMutation("declare version", "", name)
+	changed := false
+	defer func() { br.root.endMutation(changed) }()
+
+	for _, version := range br.root.versions {
+		if version.name == name && version.strategy == strategy {
+			return version
+		}
+	}
+	version := &apiVersion{name: name, strategy: strategy, root: br.root}
+	br.root.versions = append(slices.Clone(br.root.versions), version)
+	changed = true
+	return version
+}
+
+// enterVersion scopes the group br to the version name. Path versions add the
+// name to the prefix.
+func (br *BaseRouter) enterVersion(name string, strategy VersionStrategy) {
+	version := br.declareVersion(name, strategy)
+	br.version = version
+	if version.strategy.Source == VersionFromPath {
+		br.versionPrefix = br.prefix
+		br.prefix = br.joinPath(br.prefix, version.name)
+	}
+}
+
+func (br *BaseRouter) deprecateVersion(name string, d Deprecation) error {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	br.root.registrationMu.Lock()
+	defer br.root.registrationMu.Unlock()
+	if state := br.root.registrationState(); state != RegistrationCollecting {
+		return newRegistrationError("deprecate version", "", name, state, ErrRouterSealed)
+	}
+	found := false
+	for _, version := range br.root.versions {
+		if version.name == name {
+			version.deprecation = &d
+			found = true
+		}
+	}
+	if found {
+		br.root.revision++
+		return nil
+	}
+	return goerrors.New(fmt.Sprintf("api version %q is not declared", name), goerrors.CategoryNotFound).
+		WithTextCode("API_VERSION_NOT_FOUND").
+		WithMetadata(map[string]any{"version": name})
+}
+
+func newVersionError(name string, err error) error {
+	return goerrors.Wrap(err, goerrors.CategoryBadInput, "invalid api version").
+		WithCode(http.StatusInternalServerError).
+		WithTextCode("API_VERSION").
+		WithMetadata(map[string]any{"version": name})
+}
+
+// family returns the declared versions sharing the strategy of v.
+func (v *apiVersion) family() []*apiVersion {
+	v.root.registrationMu.Lock()
+	versions := v.root.versions
+	v.root.registrationMu.Unlock()
+
+	family := make([]*apiVersion, 0, len(versions))
+	for _, version := range versions {
+		if version.strategy == v.strategy {
+			family = append(family, version)
+		}
+	}
+	return family
+}
+
+// negotiate returns the version req asks for in the strategy of v, mapped to
+// the nearest declared version at or below it.
+func (v *apiVersion) negotiate(req routeRequest) string {
+	var requested string
+	switch v.strategy.Source {
+	case VersionFromHeader:
+		requested = strings.TrimSpace(req.header(v.strategy.Header))
+	case VersionFromAccept:
+		requested = acceptedVersion(req.header("Accept"), v.strategy.Vendor)
+	default:
+		return v.name
+	}
+
+	family := v.family()
+	requested = cmp.Or(requested, v.strategy.Default)
+	if requested == "" {
+		return slices.MaxFunc(family, func(a, b *apiVersion) int { return compareVersions(a.name, b.name) }).name
+	}
+	var nearest, oldest *apiVersion
+	for _, version := range family {
+		if oldest == nil || compareVersions(version.name, oldest.name) < 0 {
+			oldest = version
+		}
+		if compareVersions(version.name, requested) <= 0 && (nearest == nil || compareVersions(version.name, nearest.name) > 0) {
+			nearest = version
+		}
+	}
+	return cmp.Or(nearest, oldest).name
+}
+
+// acceptedVersion returns the version named by the first vendor media type
+// of an Accept header.
+func acceptedVersion(accept, vendor string) string {
+	prefix := "vnd." + vendor
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		_, subtype, _ := strings.Cut(mediaType, "/")
+		subtype, _, _ = strings.Cut(subtype, "+")
+		switch {
+		case strings.HasPrefix(subtype, prefix+"."):
+			return strings.TrimPrefix(subtype, prefix+".")
+		case subtype == prefix && params["version"] != "":
+			return params["version"]
+		}
+	}
+	return ""
+}
+
+// selectVersion picks the route of tier, routes sharing a path and host, that
+// serves req: the newest version at or below the negotiated one, else the
+// unversioned route.
+func selectVersion(tier []*RouteDefinition, req routeRequest) *RouteDefinition {
+	var unversioned, best *RouteDefinition
+	want := ""
+	for _, route := range tier {
+		if !route.paramConstraints.match(req.param) {
+			continue
+		}
+		if route.version == nil {
+			if unversioned == nil {
+				unversioned = route
+			}
+			continue
+		}
+		if want == "" {
+			want = route.version.negotiate(req)
+		}
+		if compareVersions(route.Version, want) <= 0 && (best == nil || compareVersions(route.Version, best.Version) > 0) {
+			best = route
+		}
+	}
+	if best == nil {
+		return unversioned
+	}
+	return best
+}
+
+// compareVersions orders version names such as "v1", "v1.2" or "2024-06-01"
+// by their numeric components, comparing other components as text.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range max(len(pa), len(pb)) {
+		x, y := "0", "0"
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		c := strings.Compare(x, y)
+		if errX == nil && errY == nil {
+			c = cmp.Compare(nx, ny)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []string {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	return strings.FieldsFunc(version, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	})
+}
+
+// deprecation returns the deprecation of the route, or of its version.
+func (route *RouteDefinition) deprecation() *Deprecation {
+	if route.Deprecation != nil {
+		return route.Deprecation
+	}
+	if route.version != nil {
+		return route.version.deprecation
+	}
+	return nil
+}
+
+// writeRouteHeaders sets the response headers the route's version implies
+// before its handlers run. Vary and Link add to the values already set.
+func writeRouteHeaders(c Context, route *RouteDefinition) {
+	if route.version != nil {
+		switch route.version.strategy.Source {
+		case VersionFromHeader:
+			addVary(c, route.version.strategy.Header)
+		case VersionFromAccept:
+			addVary(c, "Accept")
+		}
+	}
+
+	d := route.deprecation()
+	if d == nil {
+		return
+	}
+	if !d.At.IsZero() {
+		c.SetHeader("Deprecation", "@"+strconv.FormatInt(d.At.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		c.SetHeader("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		addLink(c, fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+	}
+}
+
+// addLink adds link to the Link response header, after the links already set.
+func addLink(c Context, link string) {
+	if appender, ok := AsResponseHeaderAppender(c); ok {
+		appender.AppendResponseHeader("Link", link)
+		return
+	}
+	if state, ok := AsResponseState(c); ok {
+		if existing := state.ResponseHeaders().Values("Link"); len(existing) > 0 {
+			link = strings.Join(existing, ", ") + ", " + link
+		}
+	}
+	c.SetHeader("Link", link)
+}
+
+// versionPath joins a path version to the prefix in front of it.
+func versionPath(prefix string, version *apiVersion) string {
+	return strings.TrimRight(prefix, "/") + "/" + version.name
+}
+
+// versionedRest returns the path of a versioned route after its version
+// segment.
+func versionedRest(route *RouteDefinition) string {
+	if route.version.strategy.Source != VersionFromPath {
+		return route.Path
+	}
+	return strings.TrimPrefix(route.Path, versionPath(route.versionPrefix, route.version))
+}
+
+// inheritedRoutes returns the routes version serves: for each method, host
+// and path, the newest declaration at or below version in its strategy.
+func inheritedRoutes(routes []*RouteDefinition, version *apiVersion) []*RouteDefinition {
+	index := map[string]int{}
+	var out []*RouteDefinition
+	for _, route := range routes {
+		v := route.version
+		if v == nil || v.strategy != version.strategy || compareVersions(v.name, version.name) > 0 {
+			continue
+		}
+		key := strings.Join([]string{string(route.Method), route.Host, route.versionPrefix, versionedRest(route)}, " ")
+		i, seen := index[key]
+		switch {
+		case !seen:
+			index[key] = len(out)
+			out = append(out, route)
+		case compareVersions(v.name, out[i].Version) > 0:
+			out[i] = route
+		}
+	}
+	return out
+}
+
+// inheritAs returns a copy of route served under version.
+func inheritAs(route *RouteDefinition, version *apiVersion) RouteDefinition {
+	inherited := *route
+	if version.strategy.Source == VersionFromPath {
+		inherited.Path = versionPath(route.versionPrefix, version) + versionedRest(route)
+	}
+	inherited.Version = version.name
+	inherited.version = version
+	return inherited
+}
+
+// registerVersionFallbacks mounts, for each path version, the routes it
+// inherits from older versions. Header and Accept versions share paths and
+// fall back while resolving the request instead.
+func (br *BaseRouter) registerVersionFallbacks(reg lateRouteRegistrar) {
+	br.root.beginMutation("register version fallbacks", "", "")
+	changed := false
+	defer func() { br.root.endMutation(changed) }()
+
+	routes := slices.Clone(br.root.routes)
+	for _, version := range br.root.versions {
+		if version.strategy.Source != VersionFromPath {
+			continue
+		}
+		for _, route := range inheritedRoutes(routes, version) {
+			if route.version == version {
+				continue
+			}
+			inherited := inheritAs(route, version)
+			inherited.publicName = ""
+			inherited.nameMode = routeNameModeInternal
+			inherited.onSetName = nil
+			inherited.mount = nil
+			inherited.inheritedFrom = route
+			if err := reg.mountFallback(&inherited); err != nil {
+				br.logger.Warn("skipping inherited version route", "method", inherited.Method, "path", inherited.Path, "error", err)
+				continue
+			}
+			changed = true
+		}
+	}
+}
+
+// routesForVersion returns the routes a document for version describes:
+// unversioned routes and the routes version declares or inherits.
+func routesForVersion(routes []RouteDefinition, name string) []RouteDefinition {
+	var versions []*apiVersion
+	declared := make([]*RouteDefinition, 0, len(routes))
+	out := make([]RouteDefinition, 0, len(routes))
+	for i := range routes {
+		route := &routes[i]
+		if route.version == nil {
+			out = append(out, *route)
+			continue
+		}
+		if route.Version == name && !slices.Contains(versions, route.version) {
+			versions = append(versions, route.version)
+		}
+		declared = append(declared, route)
+	}
+	for _, version := range versions {
+		for _, route := range inheritedRoutes(declared, version) {
+			out = append(out, inheritAs(route, version))
+		}
+	}
+	return out
+}
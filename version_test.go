@@ -0,0 +1,194 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-router/routertest"
+)
+
+var (
+	v1DeprecatedAt = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	v1Sunset       = time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC)
+)
+
+func registerVersions[T any](r router.Router[T]) {
+	api := r.Group("/api")
+	v1 := router.Version(api, "v1", router.VersionByPath())
+	v1.Get("/users", func(c router.Context) error { return c.SendString("users v1") })
+	v1.Get("/teams", func(c router.Context) error { return c.SendString("teams v1") })
+	v2 := router.Version(api, "v2", router.VersionByPath())
+	v2.Get("/users", func(c router.Context) error { return c.SendString("users v2") })
+	router.Deprecate(v2.Get("/legacy", func(c router.Context) error { return c.SendString("legacy") }),
+		router.Deprecation{Link: "https://example.com/legacy"})
+
+	header := router.VersionByHeader("")
+	router.Version(r, "1", header).Get("/reports", func(c router.Context) error { return c.SendString("reports 1") })
+	router.Version(r, "3", header).Get("/reports", func(c router.Context) error { return c.SendString("reports 3") })
+
+	accept := router.VersionByAccept("app")
+	router.Version(r, "v1", accept).Get("/orders", func(c router.Context) error { return c.SendString("orders v1") })
+	router.Version(r, "v2", accept).Get("/orders", func(c router.Context) error { return c.SendString("orders v2") })
+	r.Get("/orders", func(c router.Context) error { return c.SendString("orders unversioned") })
+
+	err := router.DeprecateVersion(r, "v1", router.Deprecation{
+		At:     v1DeprecatedAt,
+		Sunset: v1Sunset,
+		Link:   "https://example.com/migrate",
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestVersion_DispatchesByStrategy(t *testing.T) {
+	setup := routertest.Setup{Fiber: registerVersions, HTTPRouter: registerVersions, ServeMux: registerVersions}
+	routertest.EachAdapter(t, setup, func(t *testing.T, client *routertest.Client) {
+		client.Get("/api/v1/users").Do(t).AssertStatus(http.StatusOK).AssertBody("users v1")
+		client.Get("/api/v2/users").Do(t).AssertStatus(http.StatusOK).AssertBody("users v2")
+		client.Get("/api/v2/teams").Do(t).AssertStatus(http.StatusOK).AssertBody("teams v1")
+		client.Get("/api/v1/legacy").Do(t).AssertStatus(http.StatusNotFound)
+		client.Get("/api/v3/users").Do(t).AssertStatus(http.StatusNotFound)
+
+		client.Get("/reports").Do(t).AssertStatus(http.StatusOK).AssertBody("reports 3").AssertHeader("Vary", "Api-Version")
+		client.Get("/reports").Header("API-Version", "1").Do(t).AssertBody("reports 1")
+		client.Get("/reports").Header("API-Version", "2").Do(t).AssertBody("reports 1")
+		client.Get("/reports").Header("API-Version", "0").Do(t).AssertBody("reports 1")
+		client.Get("/reports").Header("API-Version", "9").Do(t).AssertBody("reports 3")
+
+		client.Get("/orders").Header("Accept", "application/vnd.app.v1+json").Do(t).
+			AssertStatus(http.StatusOK).
+			AssertBody("orders v1").
+			AssertHeader("Vary", "Accept")
+		client.Get("/orders").Header("Accept", "text/html, application/vnd.app+json; version=v2").Do(t).AssertBody("orders v2")
+		client.Get("/orders").Header("Accept", "application/json").Do(t).AssertBody("orders v2")
+	})
+}
+
+func TestVersion_DeprecationHeaders(t *testing.T) {
+	app := router.NewHTTPServer()
+	registerVersions(app.Router())
+
+	err := router.DeprecateVersion(app.Router(), "v9", router.Deprecation{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "API_VERSION_NOT_FOUND")
+
+	client := routertest.NewClient(app)
+	client.Get("/api/v1/users").Do(t).
+		AssertHeader("Deprecation", "@1767225600").
+		AssertHeader("Sunset", "Thu, 31 Dec 2026 00:00:00 GMT").
+		AssertHeader("Link", `<https://example.com/migrate>; rel="deprecation"`)
+	client.Get("/orders").Header("Accept", "application/vnd.app.v1+json").Do(t).AssertHeader("Deprecation", "@1767225600")
+
+	res := client.Get("/api/v2/users").Do(t)
+	assert.Empty(t, res.Header.Get("Deprecation"))
+
+	res = client.Get("/api/v2/legacy").Do(t).AssertHeader("Link", `<https://example.com/legacy>; rel="deprecation"`)
+	assert.Empty(t, res.Header.Get("Deprecation"), "a deprecation without a date marks the route only")
+
+	require.Error(t, router.DeprecateVersion(app.Router(), "v1", router.Deprecation{}), "versions are sealed once serving")
+}
+
+func TestVersion_RouteHeadersAddToEarlierValues(t *testing.T) {
+	preset := func(h http.Header) {
+		h.Set("Vary", "Accept-Encoding")
+		h.Set("Link", `</app.css>; rel="preload"`)
+	}
+	wrap := func(handler http.Handler) func(*http.Request) *http.Response {
+		return func(req *http.Request) *http.Response {
+			rec := httptest.NewRecorder()
+			preset(rec.Header())
+			handler.ServeHTTP(rec, req)
+			return rec.Result()
+		}
+	}
+	httpApp := router.NewHTTPServer()
+	registerVersions(httpApp.Router())
+	muxApp := router.NewServeMuxServer()
+	registerVersions(muxApp.Router())
+	fiberApp := router.NewFiberAdapter(func(app *fiber.App) *fiber.App {
+		app.Use(func(c *fiber.Ctx) error {
+			c.Set("Vary", "Accept-Encoding")
+			c.Set("Link", `</app.css>; rel="preload"`)
+			return c.Next()
+		})
+		return app
+	})
+	registerVersions(fiberApp.Router())
+
+	servers := map[string]func(*http.Request) *http.Response{
+		"fiber": func(req *http.Request) *http.Response {
+			res, err := fiberApp.WrappedRouter().Test(req)
+			require.NoError(t, err)
+			return res
+		},
+		"httprouter": wrap(httpApp.WrappedRouter()),
+		"servemux":   wrap(muxApp.WrappedRouter()),
+	}
+	for name, serve := range servers {
+		t.Run(name, func(t *testing.T) {
+			res := serve(httptest.NewRequest(http.MethodGet, "/reports", nil))
+			assert.Equal(t, "Accept-Encoding, Api-Version", strings.Join(res.Header.Values("Vary"), ", "))
+
+			res = serve(httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+			assert.Equal(t, `</app.css>; rel="preload", <https://example.com/migrate>; rel="deprecation"`,
+				strings.Join(res.Header.Values("Link"), ", "))
+		})
+	}
+}
+
+func TestVersion_OpenAPIPerVersion(t *testing.T) {
+	app := router.NewHTTPServer()
+	registerVersions(app.Router())
+	router.ServeOpenAPI(app.Router(), router.NewOpenAPIRenderer(), router.WithOpenAPIVersion("v2"))
+	router.ServeOpenAPI(app.Router(), router.NewOpenAPIRenderer(), router.WithOpenAPIVersion("v1"))
+
+	client := routertest.NewClient(app)
+	var doc map[string]any
+	require.NoError(t, client.Get("/openapi/v2.json").Do(t).AssertStatus(http.StatusOK).DecodeJSON(&doc))
+	assert.Equal(t, "v2", doc["info"].(map[string]any)["version"])
+
+	paths := doc["paths"].(map[string]any)
+	assert.Contains(t, paths, "/api/v2/users")
+	assert.Contains(t, paths, "/api/v2/teams", "inherited routes are documented under the version")
+	assert.Contains(t, paths, "/orders")
+	assert.NotContains(t, paths, "/api/v1/users")
+	assert.Equal(t, true, paths["/api/v2/legacy"].(map[string]any)["get"].(map[string]any)["deprecated"])
+	assert.Nil(t, paths["/api/v2/users"].(map[string]any)["get"].(map[string]any)["deprecated"])
+
+	require.NoError(t, client.Get("/openapi/v1.json").Do(t).AssertStatus(http.StatusOK).DecodeJSON(&doc))
+	paths = doc["paths"].(map[string]any)
+	assert.NotContains(t, paths, "/api/v2/users")
+	assert.Equal(t, true, paths["/api/v1/users"].(map[string]any)["get"].(map[string]any)["deprecated"])
+
+	client.Get("/meta/docs/v1/").Do(t).AssertStatus(http.StatusOK).AssertBodyContains("/openapi/v1.yaml")
+}
+
+func TestVersion_RoutesAndManifest(t *testing.T) {
+	app := router.NewHTTPServer()
+	registerVersions(app.Router())
+
+	var reports []string
+	for _, entry := range router.BuildRouterManifest(app.Router()) {
+		if entry.Path == "/reports" {
+			reports = append(reports, entry.Version)
+		}
+	}
+	assert.Equal(t, []string{"1", "3"}, reports)
+
+	for _, route := range app.Router().Routes() {
+		assert.NotEqual(t, "/api/v2/teams", route.Path, "inherited routes are served, not declared")
+	}
+
+	r := app.Router()
+	assert.Panics(t, func() { router.Version(r, "v4", router.VersionByAccept("")) })
+	assert.Panics(t, func() { router.Version(r, "a/b", router.VersionByPath()) })
+}
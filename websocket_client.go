@@ -2,7 +2,6 @@ package router
 
 import (
 	"context"
-	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -362,20 +361,11 @@ func (c *wsClient) CloseWithContext(ctx context.Context, code int, reason string
 			return
 		}
 
-		// Send close message
-		closeMsg := make([]byte, 2+len(reason))
-		binary.BigEndian.PutUint16(closeMsg[:2], closeCode)
-		copy(closeMsg[2:], reason)
-
-		if writeErr := c.conn.WriteMessage(CloseMessage, closeMsg); writeErr != nil {
-			c.logger.Warn("failed to write websocket close frame", "client_id", c.ID(), "error", writeErr)
-			err = writeErr
-		}
-		if closeErr := c.conn.Close(); closeErr != nil {
+		// Send the close frame and close the connection. Close would send a
+		// second, normal-closure frame and fail with ErrCloseSent.
+		if closeErr := c.conn.CloseWithStatus(int(closeCode), reason); closeErr != nil {
 			c.logger.Warn("failed to close websocket connection", "client_id", c.ID(), "error", closeErr)
-			if err == nil {
-				err = closeErr
-			}
+			err = closeErr
 		}
 
 		// Clean up resources
@@ -1,10 +1,8 @@
 package router
 
 import (
-	"crypto/sha256"
 	_ "embed"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -36,20 +34,14 @@ const (
 
 // Generate ETags for cache validation
 var (
-	websocketClientETag       = generateETag(websocketClientJS, "js")
-	websocketClientMinETag    = generateETag(websocketClientMinJS, "min.js")
-	websocketClientMinMapEtag = generateETag(websocketClientMinMap, "js.map")
-	websocketClientDTSETag    = generateETag(websocketClientDTS, "dts")
-	websocketExamplesETag     = generateETag(websocketExamplesJS, "examples.js")
-	websocketTestETag         = generateETag(websocketTestHTML, "test.html")
+	websocketClientETag       = contentETag("ws-"+WebSocketClientVersion+"-js-", websocketClientJS, false)
+	websocketClientMinETag    = contentETag("ws-"+WebSocketClientVersion+"-min.js-", websocketClientMinJS, false)
+	websocketClientMinMapEtag = contentETag("ws-"+WebSocketClientVersion+"-js.map-", websocketClientMinMap, false)
+	websocketClientDTSETag    = contentETag("ws-"+WebSocketClientVersion+"-dts-", websocketClientDTS, false)
+	websocketExamplesETag     = contentETag("ws-"+WebSocketClientVersion+"-examples.js-", websocketExamplesJS, false)
+	websocketTestETag         = contentETag("ws-"+WebSocketClientVersion+"-test.html-", websocketTestHTML, false)
 )
 
-// generateETag creates a simple ETag from content hash and type
-func generateETag(content []byte, fileType string) string {
-	hash := sha256.Sum256(content)
-	return fmt.Sprintf(`"ws-%s-%s-%x"`, WebSocketClientVersion, fileType, hash[:8])
-}
-
 // setCommonHeaders sets standard headers for all WebSocket client assets
 func setCommonHeaders(c Context, contentType, etag string, maxAge time.Duration) {
 	c.SetHeader("Content-Type", contentType)
@@ -60,12 +52,6 @@ func setCommonHeaders(c Context, contentType, etag string, maxAge time.Duration)
 	c.SetHeader("X-WebSocket-Client-Build", WebSocketClientBuild)
 }
 
-// checkETag checks if the client's ETag matches and returns 304 if unchanged
-func checkETag(c Context, etag string) bool {
-	clientETag := strings.TrimSpace(c.Header("If-None-Match"))
-	return clientETag == etag
-}
-
 // WebSocketClientHandler serves the main WebSocket client library
 // @param minified bool - serve minified version if true
 func WebSocketClientHandler(minified bool) HandlerFunc {
@@ -85,7 +71,7 @@ func WebSocketClientHandler(minified bool) HandlerFunc {
 		}
 
 		// Check ETag for caching
-		if checkETag(c, etag) {
+		if matchesIfNoneMatch(c, etag) {
 			return c.Status(304).Send(nil)
 		}
 
@@ -108,7 +94,7 @@ func WebSocketClientHandler(minified bool) HandlerFunc {
 func WebSocketClientTypesHandler() HandlerFunc {
 	return func(c Context) error {
 		// Check ETag for caching
-		if checkETag(c, websocketClientDTSETag) {
+		if matchesIfNoneMatch(c, websocketClientDTSETag) {
 			return c.Status(304).Send(nil)
 		}
 
@@ -130,7 +116,7 @@ func WebSocketClientTypesHandler() HandlerFunc {
 func WebsocketClientMinMapHandler() HandlerFunc {
 	return func(c Context) error {
 		// Check ETag for caching
-		if checkETag(c, websocketClientMinMapEtag) {
+		if matchesIfNoneMatch(c, websocketClientMinMapEtag) {
 			return c.Status(304).Send(nil)
 		}
 
@@ -146,7 +132,7 @@ func WebsocketClientMinMapHandler() HandlerFunc {
 func WebSocketExamplesHandler() HandlerFunc {
 	return func(c Context) error {
 		// Check ETag for caching
-		if checkETag(c, websocketExamplesETag) {
+		if matchesIfNoneMatch(c, websocketExamplesETag) {
 			return c.Status(304).Send(nil)
 		}
 
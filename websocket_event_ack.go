@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
@@ -202,6 +204,11 @@ func (m *AckManager) HandleAck(ack *EventAck) error {
 }
 
 func (m *AckManager) timeoutAck(ackID string) {
+	m.failAck(ackID, "acknowledgment timeout")
+}
+
+// failAck resolves a pending acknowledgment as failed with reason.
+func (m *AckManager) failAck(ackID, reason string) {
 	m.pendingMu.Lock()
 	pending, exists := m.pending[ackID]
 	if !exists {
@@ -223,7 +230,7 @@ func (m *AckManager) timeoutAck(ackID string) {
 	timeoutAck := &EventAck{
 		ID:        ackID,
 		Success:   false,
-		Error:     "acknowledgment timeout",
+		Error:     reason,
 		Timestamp: time.Now(),
 	}
 
@@ -280,6 +287,28 @@ func (m *AckManager) CancelAck(ackID string) {
 	m.cleanupAck(ackID)
 }
 
+// Flush waits until every pending acknowledgment is received or times out.
+// When ctx ends first, the remaining acknowledgments fail with a shutdown
+// error so their senders return.
+func (m *AckManager) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for m.PendingCount() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			m.pendingMu.RLock()
+			ids := slices.Collect(maps.Keys(m.pending))
+			m.pendingMu.RUnlock()
+			for _, id := range ids {
+				m.failAck(id, "server shutting down")
+			}
+			return fmt.Errorf("%d acknowledgments still pending: %w", len(ids), ctx.Err())
+		}
+	}
+	return nil
+}
+
 // PendingCount returns the number of pending acknowledgments
 func (m *AckManager) PendingCount() int {
 	m.pendingMu.RLock()
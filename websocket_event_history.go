@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -296,6 +297,7 @@ type EventBatcher struct {
 	interval   time.Duration
 	flushTimer *time.Timer
 	processor  func([]*EventMessage)
+	processing sync.WaitGroup
 }
 
 // NewEventBatcher creates a new event batcher
@@ -346,7 +348,7 @@ func (b *EventBatcher) flushNow() {
 	// Process current batch
 	if b.processor != nil {
 		batch := b.batch
-		go b.processor(batch)
+		b.processing.Go(func() { b.processor(batch) })
 	}
 
 	// Reset batch and timer
@@ -369,6 +371,26 @@ func (b *EventBatcher) Close() {
 	b.flushNow()
 }
 
+// Flush processes the pending batch and waits until every batch handed to
+// the processor is done, or ctx ends.
+func (b *EventBatcher) Flush(ctx context.Context) error {
+	b.batchMu.Lock()
+	b.flushNow()
+	b.batchMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.processing.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event batch still processing: %w", ctx.Err())
+	}
+}
+
 // EventThrottler throttles events by type
 type EventThrottler struct {
 	limits       map[string]*throttleLimit
@@ -426,6 +426,39 @@ func (h *WSHub) Close() error {
 	return nil
 }
 
+// CloseWithStatus stops the hub and sends every client a close frame with
+// code and reason. It returns the number of clients closed. Use
+// CloseServiceRestart with a reconnect hint to move clients to another
+// instance during a deploy. Rooms are left to the caller; ShutdownCoordinator
+// destroys them.
+func (h *WSHub) CloseWithStatus(code int, reason string) (int, error) {
+	// Close all client connections before stopping the hub, whose context
+	// ends the clients with a generic close frame.
+	h.clientsMu.Lock()
+	var errs []error
+	for _, client := range h.clients {
+		if err := client.Close(code, reason); err != nil {
+			h.logger.Error("Failed to close websocket client during hub shutdown",
+				"client_id", client.ID(),
+				"error", err)
+			errs = append(errs, err)
+		}
+	}
+	closed := len(h.clients)
+	h.clientsMu.Unlock()
+	h.cancel()
+
+	return closed, errors.Join(errs...)
+}
+
+// destroyRooms destroys the rooms of the hub once its clients are closed.
+func (h *WSHub) destroyRooms() error {
+	if h.roomManager == nil {
+		return nil
+	}
+	return h.roomManager.closeRooms()
+}
+
 // Internal methods
 
 func (h *WSHub) handleError(ctx context.Context, client WSClient, err error) {
@@ -144,6 +144,11 @@ func getWebSocketFactory(c Context) WebSocketContextFactory {
 		return GetFiberWebSocketFactory()
 	}
 
+	// ServeMux shares the net/http context implementation with httprouter
+	if hc, ok := c.(*httpRouterContext); ok && hc.adapter == serveMuxAdapterName && serveMuxFactory != nil {
+		return GetServeMuxWebSocketFactory()
+	}
+
 	// Check if it's an HTTP router context
 	contextType := fmt.Sprintf("%T", c)
 	if strings.Contains(contextType, "httpRouter") || strings.Contains(contextType, "HTTPRouter") {
@@ -183,6 +188,7 @@ func inheritWebSocketRouteState(src Context, dst WebSocketContext) {
 		target.store = source.store
 		target.passLocalsToViews = source.passLocalsToViews
 		target.router = source.router
+		target.adapter = source.adapter
 		if source.locals != nil {
 			locals := make(ViewContext, len(source.locals))
 			maps.Copy(locals, source.locals)
@@ -203,6 +209,7 @@ type WebSocketContextFactory interface {
 var (
 	fiberFactory      WebSocketContextFactory
 	httpRouterFactory WebSocketContextFactory
+	serveMuxFactory   WebSocketContextFactory
 )
 
 // RegisterWebSocketFactory registers a WebSocket factory for an adapter
@@ -212,6 +219,8 @@ func RegisterWebSocketFactory(adapterName string, factory WebSocketContextFactor
 		fiberFactory = factory
 	case "httprouter":
 		httpRouterFactory = factory
+	case serveMuxAdapterName:
+		serveMuxFactory = factory
 	}
 }
 
@@ -225,6 +234,11 @@ func GetHTTPRouterWebSocketFactory() WebSocketContextFactory {
 	return httpRouterFactory
 }
 
+// GetServeMuxWebSocketFactory returns the ServeMux WebSocket factory
+func GetServeMuxWebSocketFactory() WebSocketContextFactory {
+	return serveMuxFactory
+}
+
 // DefaultWebSocketMiddleware creates WebSocket middleware with default configuration
 func DefaultWebSocketMiddleware() MiddlewareFunc {
 	return WebSocketUpgrade(DefaultWebSocketConfig())
@@ -490,6 +490,11 @@ func (r *Room) GetInfo() RoomInfo {
 
 // Destroy destroys the room
 func (r *Room) Destroy() error {
+	return r.destroy(true)
+}
+
+// destroy destroys the room, telling its clients when notify is set.
+func (r *Room) destroy(notify bool) error {
 	r.destroyMu.Lock()
 	defer r.destroyMu.Unlock()
 
@@ -506,18 +511,8 @@ func (r *Room) Destroy() error {
 
 	// Remove all clients
 	r.clientsMu.Lock()
-	for _, client := range r.clients {
-		// Notify client they're being removed
-		if err := client.SendJSON(map[string]any{
-			"type":   "room:destroyed",
-			"room":   r.name,
-			"reason": "room destroyed",
-		}); err != nil {
-			r.logger.Error("Failed to notify client about room destruction",
-				"room_id", r.id,
-				"client_id", client.ID(),
-				"error", err)
-		}
+	if notify {
+		r.notifyDestroyedLocked()
 	}
 	r.clients = make(map[string]WSClient)
 	r.clientsMu.Unlock()
@@ -537,6 +532,23 @@ func (r *Room) Destroy() error {
 	return nil
 }
 
+// notifyDestroyedLocked tells every client the room is gone. Callers hold
+// clientsMu.
+func (r *Room) notifyDestroyedLocked() {
+	for _, client := range r.clients {
+		if err := client.SendJSON(map[string]any{
+			"type":   "room:destroyed",
+			"room":   r.name,
+			"reason": "room destroyed",
+		}); err != nil {
+			r.logger.Error("Failed to notify client about room destruction",
+				"room_id", r.id,
+				"client_id", client.ID(),
+				"error", err)
+		}
+	}
+}
+
 // IsDestroyed returns whether the room is destroyed
 func (r *Room) IsDestroyed() bool {
 	r.destroyMu.RLock()
@@ -144,22 +144,49 @@ func (rm *RoomManager) GetOrCreateRoom(ctx context.Context, id, name string, con
 // RemoveRoom removes a room from the manager
 func (rm *RoomManager) RemoveRoom(id string) error {
 	rm.roomsMu.Lock()
-	defer rm.roomsMu.Unlock()
-
 	room, exists := rm.rooms[id]
+	delete(rm.rooms, id)
+	rm.roomsMu.Unlock()
+
 	if !exists {
 		return fmt.Errorf("room %s not found", id)
 	}
 
-	// Destroy the room if not already destroyed
+	// Destroy the room if not already destroyed. The room is already
+	// forgotten, so its removal from the hub is a no-op.
 	if !room.IsDestroyed() {
 		if err := room.Destroy(); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
+// forgetRoom drops a destroyed room from the manager.
+func (rm *RoomManager) forgetRoom(id string) {
+	rm.roomsMu.Lock()
+	defer rm.roomsMu.Unlock()
 	delete(rm.rooms, id)
-	return nil
+}
+
+// closeRooms destroys every room without notifying its clients, which are
+// being disconnected, and joins the errors of the rooms that failed.
+func (rm *RoomManager) closeRooms() error {
+	rm.roomsMu.Lock()
+	rooms := rm.rooms
+	rm.rooms = make(map[string]*Room)
+	rm.roomsMu.Unlock()
+
+	var errs []error
+	for _, room := range rooms {
+		if room.IsDestroyed() {
+			continue
+		}
+		if err := room.destroy(false); err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", room.ID(), err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // ListRooms returns a list of all rooms
@@ -433,7 +460,7 @@ func EnhanceWSHub(hub *WSHub) {
 
 func (h *WSHub) removeRoom(roomID string) error {
 	if h.roomManager != nil {
-		return h.roomManager.RemoveRoom(roomID)
+		h.roomManager.forgetRoom(roomID)
 	}
 	return nil
 }